@@ -2,21 +2,33 @@ package main
 
 import (
 	"context"
+	"crypto/ed25519"
+	"crypto/tls"
 	"errors"
+	"flag"
 	"fmt"
-	"log"
+	"log/slog"
+	"net"
 	"net/http"
+	"os"
 	"os/signal"
 	"syscall"
 	"time"
 
 	"github.com/2509-hackz-ichthyo/main/api/internal/app"
 	"github.com/2509-hackz-ichthyo/main/api/internal/config"
+	"github.com/2509-hackz-ichthyo/main/api/internal/jobs"
+	"github.com/2509-hackz-ichthyo/main/api/internal/logging"
+	"github.com/2509-hackz-ichthyo/main/api/internal/server/grpcserver"
 	"github.com/2509-hackz-ichthyo/main/api/internal/server/httpserver"
+	"github.com/2509-hackz-ichthyo/main/api/internal/tracing"
 )
 
 var (
 	listenAndServe = func(srv *http.Server) error {
+		if srv.TLSConfig != nil {
+			return srv.ListenAndServeTLS("", "")
+		}
 		return srv.ListenAndServe()
 	}
 
@@ -24,21 +36,39 @@ var (
 		return srv.Shutdown(ctx)
 	}
 
-	loadConfig = config.Load
+	cliArgs = func() []string {
+		return os.Args[1:]
+	}
+
+	loadConfig = func() (config.Config, error) {
+		return config.Load(cliArgs())
+	}
+
+	setupTracing = tracing.Setup
 
 	newWhitespaceUsecase = app.NewWhitespaceUsecase
 
 	newRouter = httpserver.NewRouter
 
-	logFatalf = log.Fatalf
+	listenGRPC = net.Listen
+
+	logFatalf = func(format string, args ...any) {
+		slog.Error(fmt.Sprintf(format, args...))
+		os.Exit(1)
+	}
 )
 
-// main は HTTP サーバーを起動し、Whitespace デコーダ API を提供する。
+// main は HTTP / gRPC サーバーを起動し、Whitespace デコーダ API を提供する。
 func main() {
+	slog.SetDefault(logging.NewJSONLogger(os.Stdout, slog.LevelInfo))
+
 	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
 	defer stop()
 
 	if err := run(ctx); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return
+		}
 		logFatalf("%v", err)
 	}
 }
@@ -48,18 +78,86 @@ func run(ctx context.Context) error {
 	if err != nil {
 		return fmt.Errorf("設定の読み込みに失敗しました: %w", err)
 	}
+	slog.SetDefault(logging.NewJSONLogger(os.Stdout, logLevel(cfg.LogLevel)))
+
+	shutdownTracing, err := setupTracing(ctx, tracing.Exporter(cfg.TraceExporter))
+	if err != nil {
+		return fmt.Errorf("トレースの初期化に失敗しました: %w", err)
+	}
+	defer func() {
+		if err := shutdownTracing(context.Background()); err != nil {
+			slog.Warn("failed to flush traces", slog.Any("error", err))
+		}
+	}()
 
-	usecase := newWhitespaceUsecase()
-	router := newRouter(usecase)
+	usecase := newWhitespaceUsecase(app.WithEnvelopeKeys(envelopeKeys(cfg)))
+	readiness := httpserver.NewReadiness()
+	// HTTP と gRPC で同じキー・同じ接続元 IP のレート制限を共有する
+	auth := httpserver.NewAuthenticator(authConfig(cfg))
+	routerOpts := []httpserver.Option{
+		httpserver.WithDiffUsecase(usecase),
+		httpserver.WithReadiness(readiness),
+		httpserver.WithAuthenticator(auth),
+		httpserver.WithTrustedProxies(cfg.TrustedProxies),
+	}
+	if len(cfg.APIKeys) == 0 {
+		slog.Warn("api key authentication is disabled; set API_KEYS or API_KEY_FILE")
+	}
+	if len(cfg.CORSAllowedOrigins) > 0 {
+		routerOpts = append(routerOpts, httpserver.WithCORS(httpserver.CORSConfig{
+			AllowedOrigins: cfg.CORSAllowedOrigins,
+			AllowedMethods: cfg.CORSAllowedMethods,
+			AllowedHeaders: cfg.CORSAllowedHeaders,
+			MaxAge:         cfg.CORSMaxAge,
+		}))
+	}
+	if cfg.MetricsEnabled {
+		routerOpts = append(routerOpts, httpserver.WithMetrics(httpserver.NewMetrics()))
+	}
+	var jobManager *jobs.Manager
+	if cfg.JobWorkers > 0 {
+		jobManager = jobs.NewManager(usecase,
+			jobs.WithWorkers(cfg.JobWorkers),
+			jobs.WithQueueSize(cfg.JobQueueSize),
+			jobs.WithTTL(cfg.JobTTL),
+		)
+		defer func() {
+			// 異常終了した場合にもワーカーを止める。正常終了時は下で停止済み。
+			_ = jobManager.Shutdown(context.Background())
+		}()
+		routerOpts = append(routerOpts, httpserver.WithJobs(jobManager))
+	}
+	router := newRouter(usecase, routerOpts...)
 
 	srv := &http.Server{
-		Addr:    ":" + cfg.ServerPort,
-		Handler: router,
+		Addr:              ":" + cfg.ServerPort,
+		Handler:           router,
+		ReadTimeout:       cfg.ReadTimeout,
+		ReadHeaderTimeout: cfg.ReadHeaderTimeout,
+		WriteTimeout:      cfg.WriteTimeout,
+		IdleTimeout:       cfg.IdleTimeout,
+	}
+	if cfg.TLSEnabled() {
+		// TLSConfig を設定すると listenAndServe が HTTPS で待ち受け、HTTP/2 も自動で有効になる。
+		cert, err := tls.LoadX509KeyPair(cfg.TLSCertFile, cfg.TLSKeyFile)
+		if err != nil {
+			return fmt.Errorf("TLS 証明書の読み込みに失敗しました: %w", err)
+		}
+		srv.TLSConfig = &tls.Config{Certificates: []tls.Certificate{cert}, MinVersion: tls.VersionTLS12}
+	}
+
+	grpcSrv, grpcErr, err := startGRPC(cfg, usecase, auth, srv.TLSConfig)
+	if err != nil {
+		return err
 	}
 
 	serverErr := make(chan error, 1)
 	go func() {
-		log.Printf("サーバーを起動しました: http://0.0.0.0:%s", cfg.ServerPort)
+		slog.Info("server started",
+			slog.String("addr", srv.Addr),
+			slog.Bool("tls", srv.TLSConfig != nil),
+			slog.String("trace_exporter", cfg.TraceExporter),
+		)
 		if err := listenAndServe(srv); err != nil && !errors.Is(err, http.ErrServerClosed) {
 			serverErr <- fmt.Errorf("サーバー起動に失敗しました: %w", err)
 			return
@@ -70,12 +168,30 @@ func run(ctx context.Context) error {
 	select {
 	case err := <-serverErr:
 		return err
+	case err := <-grpcErr:
+		return err
 	case <-ctx.Done():
 	}
 
-	log.Println("シャットダウンシグナルを受信しました。終了処理を開始します。")
+	slog.Info("shutdown signal received",
+		slog.Duration("drain_delay", cfg.ShutdownDrainDelay),
+		slog.Duration("grace_period", cfg.ShutdownGracePeriod),
+	)
+
+	// 先に /readyz を 503 にし、ロードバランサが振り分け対象から外すまで受け付けを続ける。
+	readiness.SetReady(false)
+	if grpcSrv != nil {
+		grpcSrv.SetServing(false)
+	}
+	select {
+	case err := <-serverErr:
+		return err
+	case err := <-grpcErr:
+		return err
+	case <-time.After(cfg.ShutdownDrainDelay):
+	}
 
-	shutdownCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), cfg.ShutdownGracePeriod)
 	defer cancel()
 
 	if err := shutdownServer(srv, shutdownCtx); err != nil {
@@ -86,6 +202,85 @@ func run(ctx context.Context) error {
 		return err
 	}
 
-	log.Println("サーバーを終了しました。")
+	if jobManager != nil {
+		// 実行中のジョブは中断する。結果はメモリにしか無いため、再起動後に取得することはできない。
+		if err := jobManager.Shutdown(shutdownCtx); err != nil {
+			return fmt.Errorf("ジョブの停止に失敗しました: %w", err)
+		}
+	}
+
+	if grpcSrv != nil {
+		if err := grpcSrv.Shutdown(shutdownCtx); err != nil {
+			return fmt.Errorf("gRPC サーバーの正常終了に失敗しました: %w", err)
+		}
+		if err := <-grpcErr; err != nil {
+			return err
+		}
+	}
+
+	slog.Info("server stopped")
 	return nil
 }
+
+// startGRPC は cfg.GRPCPort で gRPC サーバを起動する。GRPCPort が空の場合は何もせず nil を返す。
+// 返すチャネルには Serve の終了時に一度だけ結果が送られる。
+func startGRPC(cfg config.Config, uc httpserver.WhitespaceUsecase, auth *httpserver.Authenticator, tlsConfig *tls.Config) (*grpcserver.Server, <-chan error, error) {
+	if cfg.GRPCPort == "" {
+		return nil, nil, nil
+	}
+
+	lis, err := listenGRPC("tcp", ":"+cfg.GRPCPort)
+	if err != nil {
+		return nil, nil, fmt.Errorf("gRPC サーバーの待ち受けに失敗しました: %w", err)
+	}
+
+	opts := []grpcserver.Option{grpcserver.WithAuth(auth)}
+	if tlsConfig != nil {
+		opts = append(opts, grpcserver.WithTLS(tlsConfig))
+	}
+	srv := grpcserver.New(uc, opts...)
+
+	errCh := make(chan error, 1)
+	go func() {
+		slog.Info("grpc server started", slog.String("addr", lis.Addr().String()), slog.Bool("tls", tlsConfig != nil))
+		if err := srv.Serve(lis); err != nil {
+			errCh <- fmt.Errorf("gRPC サーバー起動に失敗しました: %w", err)
+			return
+		}
+		errCh <- nil
+	}()
+	return srv, errCh, nil
+}
+
+// logLevel は設定値のログレベル名を slog.Level に変換する。解釈できない場合は INFO とする。
+func logLevel(name string) slog.Level {
+	var level slog.Level
+	if err := level.UnmarshalText([]byte(name)); err != nil {
+		return slog.LevelInfo
+	}
+	return level
+}
+
+// envelopeKeys は設定値から暗号化エンベロープ用の鍵を組み立てる。
+func envelopeKeys(cfg config.Config) app.EnvelopeKeys {
+	keys := app.EnvelopeKeys{AESKey: cfg.EnvelopeKey}
+	if len(cfg.EnvelopeSigningSeed) != 0 {
+		keys.SigningKey = ed25519.NewKeyFromSeed(cfg.EnvelopeSigningSeed)
+	}
+	return keys
+}
+
+// authConfig は設定値から API キー認証とレート制限の設定を組み立てる。
+func authConfig(cfg config.Config) httpserver.AuthConfig {
+	keys := make([]httpserver.APIKey, len(cfg.APIKeys))
+	for i, key := range cfg.APIKeys {
+		keys[i] = httpserver.APIKey{Client: key.Client, Key: key.Key, Rate: key.Rate, Burst: key.Burst}
+	}
+	return httpserver.AuthConfig{
+		Keys:     keys,
+		KeyRate:  cfg.KeyRateLimit,
+		KeyBurst: cfg.KeyRateBurst,
+		IPRate:   cfg.IPRateLimit,
+		IPBurst:  cfg.IPRateBurst,
+	}
+}
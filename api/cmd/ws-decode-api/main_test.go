@@ -3,25 +3,37 @@ package main
 import (
 	"context"
 	"fmt"
+	"log/slog"
+	"net"
 	"net/http"
+	"net/http/httptest"
 	"os"
+	"path/filepath"
+	"strings"
 	"syscall"
 	"testing"
 	"time"
 
 	"github.com/2509-hackz-ichthyo/main/api/internal/config"
+	"github.com/2509-hackz-ichthyo/main/api/internal/tracing"
 )
 
 func TestMainFunc(t *testing.T) {
 	t.Setenv("SERVER_PORT", "0")
+	t.Setenv("SHUTDOWN_DRAIN_DELAY", "0s")
+	t.Setenv("GRPC_PORT", "0")
 
 	origListen := listenAndServe
 	origShutdown := shutdownServer
+	origArgs := cliArgs
 	defer func() {
 		listenAndServe = origListen
 		shutdownServer = origShutdown
+		cliArgs = origArgs
 	}()
 
+	cliArgs = func() []string { return nil }
+
 	listenCalled := make(chan struct{})
 	listenDone := make(chan struct{})
 	shutdownCalled := make(chan struct{})
@@ -69,14 +81,19 @@ func TestMainFunc(t *testing.T) {
 
 func TestMainFuncServerError(t *testing.T) {
 	t.Setenv("SERVER_PORT", "0")
+	t.Setenv("GRPC_PORT", "0")
 
 	origListen := listenAndServe
 	origLogFatalf := logFatalf
+	origArgs := cliArgs
 	defer func() {
 		listenAndServe = origListen
 		logFatalf = origLogFatalf
+		cliArgs = origArgs
 	}()
 
+	cliArgs = func() []string { return nil }
+
 	listenAndServe = func(*http.Server) error {
 		return fmt.Errorf("boom")
 	}
@@ -195,3 +212,185 @@ func TestRunServerErrorAfterShutdown(t *testing.T) {
 		t.Fatal("listenAndServe was not invoked")
 	}
 }
+
+func TestRunTracingError(t *testing.T) {
+	origLoad := loadConfig
+	origSetup := setupTracing
+	defer func() {
+		loadConfig = origLoad
+		setupTracing = origSetup
+	}()
+
+	loadConfig = func() (config.Config, error) {
+		return config.Config{ServerPort: "0", TraceExporter: "otlp"}, nil
+	}
+	setupTracing = func(context.Context, tracing.Exporter) (func(context.Context) error, error) {
+		return nil, fmt.Errorf("exporter fail")
+	}
+
+	if err := run(context.Background()); err == nil || err.Error() != "トレースの初期化に失敗しました: exporter fail" {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func TestRunTLSCertError(t *testing.T) {
+	origLoad := loadConfig
+	defer func() { loadConfig = origLoad }()
+
+	missing := filepath.Join(t.TempDir(), "missing.pem")
+	loadConfig = func() (config.Config, error) {
+		return config.Config{ServerPort: "0", TLSCertFile: missing, TLSKeyFile: missing}, nil
+	}
+
+	err := run(context.Background())
+	if err == nil || !strings.HasPrefix(err.Error(), "TLS 証明書の読み込みに失敗しました: ") {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func TestLogLevel(t *testing.T) {
+	cases := map[string]slog.Level{
+		"debug": slog.LevelDebug,
+		"warn":  slog.LevelWarn,
+		"error": slog.LevelError,
+		"":      slog.LevelInfo,
+	}
+	for name, want := range cases {
+		if got := logLevel(name); got != want {
+			t.Fatalf("logLevel(%q) = %v, want %v", name, got, want)
+		}
+	}
+}
+
+func TestRunReadinessDuringShutdown(t *testing.T) {
+	origLoad := loadConfig
+	origListen := listenAndServe
+	origShutdown := shutdownServer
+	defer func() {
+		loadConfig = origLoad
+		listenAndServe = origListen
+		shutdownServer = origShutdown
+	}()
+
+	loadConfig = func() (config.Config, error) {
+		return config.Config{ServerPort: "0", ShutdownDrainDelay: 10 * time.Millisecond}, nil
+	}
+
+	listenCalled := make(chan struct{})
+	listenDone := make(chan struct{})
+	readyz := func(srv *http.Server) int {
+		rec := httptest.NewRecorder()
+		srv.Handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+		return rec.Code
+	}
+
+	listenAndServe = func(srv *http.Server) error {
+		if code := readyz(srv); code != http.StatusOK {
+			t.Errorf("/readyz before shutdown = %d, want %d", code, http.StatusOK)
+		}
+		close(listenCalled)
+		<-listenDone
+		return http.ErrServerClosed
+	}
+
+	shutdownServer = func(srv *http.Server, _ context.Context) error {
+		if code := readyz(srv); code != http.StatusServiceUnavailable {
+			t.Errorf("/readyz during shutdown = %d, want %d", code, http.StatusServiceUnavailable)
+		}
+		close(listenDone)
+		return nil
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	go func() {
+		<-listenCalled
+		cancel()
+	}()
+
+	if err := run(ctx); err != nil {
+		t.Fatalf("run() error = %v", err)
+	}
+}
+
+func TestRunGRPCListenError(t *testing.T) {
+	origLoad := loadConfig
+	origListenGRPC := listenGRPC
+	defer func() {
+		loadConfig = origLoad
+		listenGRPC = origListenGRPC
+	}()
+
+	loadConfig = func() (config.Config, error) {
+		return config.Config{ServerPort: "0", GRPCPort: "50051"}, nil
+	}
+	listenGRPC = func(string, string) (net.Listener, error) {
+		return nil, fmt.Errorf("address in use")
+	}
+
+	if err := run(context.Background()); err == nil || err.Error() != "gRPC サーバーの待ち受けに失敗しました: address in use" {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func TestRunStopsGRPC(t *testing.T) {
+	origLoad := loadConfig
+	origListen := listenAndServe
+	origShutdown := shutdownServer
+	origListenGRPC := listenGRPC
+	defer func() {
+		loadConfig = origLoad
+		listenAndServe = origListen
+		shutdownServer = origShutdown
+		listenGRPC = origListenGRPC
+	}()
+
+	loadConfig = func() (config.Config, error) {
+		return config.Config{ServerPort: "0", GRPCPort: "0", ShutdownGracePeriod: time.Second}, nil
+	}
+
+	grpcAddr := make(chan net.Addr, 1)
+	listenGRPC = func(network, address string) (net.Listener, error) {
+		lis, err := net.Listen(network, "127.0.0.1:0")
+		if err == nil {
+			grpcAddr <- lis.Addr()
+		}
+		return lis, err
+	}
+
+	listenDone := make(chan struct{})
+	listenAndServe = func(*http.Server) error {
+		<-listenDone
+		return http.ErrServerClosed
+	}
+	shutdownServer = func(*http.Server, context.Context) error {
+		close(listenDone)
+		return nil
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- run(ctx) }()
+
+	var addr net.Addr
+	select {
+	case addr = <-grpcAddr:
+	case <-time.After(time.Second):
+		t.Fatal("gRPC listener was not created")
+	}
+	conn, err := net.DialTimeout("tcp", addr.String(), time.Second)
+	if err != nil {
+		t.Fatalf("failed to connect gRPC port: %v", err)
+	}
+	_ = conn.Close()
+
+	cancel()
+
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatalf("run() error = %v", err)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("run() did not stop gRPC server in time")
+	}
+}
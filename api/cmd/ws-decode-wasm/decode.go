@@ -0,0 +1,61 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+
+	"github.com/2509-hackz-ichthyo/main/api/internal/app"
+	"github.com/2509-hackz-ichthyo/main/api/pkg/client"
+)
+
+// errorResponse は POST /v1/decode のエラーレスポンスと同じ形のボディ。
+type errorResponse struct {
+	Error   string `json:"error"`
+	Details string `json:"details"`
+}
+
+// decode は POST /v1/decode と同じ手順でペイロードを変換し、レスポンスボディと同じ形の値を返す。
+// 失敗時は errorResponse を返すため、呼び出し側は error フィールドの有無で成否を判定できる。
+func decode(ctx context.Context, uc *app.WhitespaceUsecase, commandType string, payload []string) any {
+	normalized, err := app.NormalizePayload(commandType, payload)
+	if err != nil {
+		return errorResponse{Error: "ペイロードが不正です", Details: err.Error()}
+	}
+
+	result, err := uc.Execute(ctx, app.WhitespaceCommand{CommandType: commandType, Payload: normalized})
+	if err != nil {
+		return errorResponse{Error: app.ErrorMessage(err), Details: err.Error()}
+	}
+	return newDecodeResponse(result)
+}
+
+// decodeJSON は decode の結果を JSON 文字列にする。
+func decodeJSON(ctx context.Context, uc *app.WhitespaceUsecase, commandType string, payload []string) string {
+	body, err := json.Marshal(decode(ctx, uc, commandType, payload))
+	if err != nil {
+		body, _ = json.Marshal(errorResponse{Error: "内部エラーが発生しました", Details: err.Error()})
+	}
+	return string(body)
+}
+
+func newDecodeResponse(result app.WhitespaceResult) client.DecodeResponse {
+	resp := client.DecodeResponse{
+		CommandType:             client.CommandType(result.CommandType),
+		ResultKind:              string(result.ResultKind),
+		ResultDecimals:          result.ResultDecimals,
+		ResultBinaries:          result.ResultBinaries,
+		ResultWhitespace:        result.ResultWhitespace,
+		ResultWhitespaceEncoded: result.ResultWhitespaceEncoded,
+	}
+
+	if len(result.ResultDecimals) > 0 {
+		joined := strings.Join(result.ResultDecimals, " ")
+		resp.DecimalString = &joined
+	}
+	if len(result.ResultBinaries) > 0 {
+		joined := strings.Join(result.ResultBinaries, " ")
+		resp.BinaryString = &joined
+	}
+	return resp
+}
@@ -0,0 +1,60 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/2509-hackz-ichthyo/main/api/internal/app"
+)
+
+func TestDecodeJSONMatchesAPIShape(t *testing.T) {
+	uc := app.NewWhitespaceUsecase()
+
+	body := decodeJSON(context.Background(), uc, "WhitespaceToDecimal", []string{"%20%20%20%20%20%20%09%0A%20%20%20%20%20%09%20%0A%20%20%20%20%20%20%20%20%20%09%09%0A"})
+	var got map[string]any
+	if err := json.Unmarshal([]byte(body), &got); err != nil {
+		t.Fatalf("failed to unmarshal %q: %v", body, err)
+	}
+	if got["command_type"] != "WhitespaceToDecimal" || got["result_kind"] != "DecimalSequence" || got["decimal_string"] != "1 2 3" {
+		t.Fatalf("unexpected response: %s", body)
+	}
+
+	body = decodeJSON(context.Background(), uc, "DecimalToWhitespace", []string{" 1 2 3 "})
+	var ws struct {
+		ResultWhitespace []string `json:"result_whitespace"`
+		Encoded          []string `json:"result_whitespace_percent_encoded"`
+	}
+	if err := json.Unmarshal([]byte(body), &ws); err != nil {
+		t.Fatalf("failed to unmarshal %q: %v", body, err)
+	}
+	if len(ws.ResultWhitespace) != 1 || ws.ResultWhitespace[0] != "      \t\n     \t \n         \t\t\n" || len(ws.Encoded) != 1 {
+		t.Fatalf("unexpected response: %s", body)
+	}
+}
+
+func TestDecodeJSONErrors(t *testing.T) {
+	uc := app.NewWhitespaceUsecase()
+	cases := map[string]struct {
+		commandType string
+		payload     []string
+		want        string
+	}{
+		"unknown command":  {commandType: "Unknown", payload: []string{"1 2 3"}, want: "ペイロードが不正です"},
+		"empty payload":    {commandType: "DecimalToWhitespace", want: "ペイロードが不正です"},
+		"invalid decimal":  {commandType: "DecimalToWhitespace", payload: []string{"1 2"}, want: "ペイロードが不正です"},
+		"missing envelope": {commandType: "EncryptedDecimalToWhitespace", payload: []string{"1 2 3"}, want: "暗号化エンベロープの鍵が設定されていません"},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			var got errorResponse
+			if err := json.Unmarshal([]byte(decodeJSON(context.Background(), uc, tc.commandType, tc.payload)), &got); err != nil {
+				t.Fatalf("failed to unmarshal: %v", err)
+			}
+			if got.Error != tc.want || got.Details == "" {
+				t.Fatalf("got %+v, want error %q", got, tc.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,58 @@
+//go:build js && wasm
+
+// Command ws-decode-wasm は Whitespace の変換を WebAssembly として公開する。
+//
+// 読み込むとグローバル関数 wsDecode(commandType, payload) が定義される。payload は文字列または文字列の配列で、
+// 戻り値は POST /v1/decode のレスポンスボディと同じ形のオブジェクト（失敗時は {error, details}）。
+// バックエンドを介さずにブラウザ内で変換できる。
+//
+//	env GOOS=js GOARCH=wasm go build -o ../app/wsdecode.wasm ./cmd/ws-decode-wasm
+package main
+
+import (
+	"context"
+	"syscall/js"
+
+	"github.com/2509-hackz-ichthyo/main/api/internal/app"
+)
+
+func main() {
+	uc := app.NewWhitespaceUsecase()
+	js.Global().Set("wsDecode", js.FuncOf(func(_ js.Value, args []js.Value) any {
+		if len(args) != 2 || args[0].Type() != js.TypeString {
+			return parse(`{"error":"引数が不正です","details":"usage: wsDecode(commandType, payload)"}`)
+		}
+		payload, ok := payloadStrings(args[1])
+		if !ok {
+			return parse(`{"error":"リクエストボディの形式が不正です","details":"payload must be a string or array of strings"}`)
+		}
+		return parse(decodeJSON(context.Background(), uc, args[0].String(), payload))
+	}))
+
+	// 関数を呼び出せるよう、プログラムを終了させない。
+	select {}
+}
+
+// payloadStrings は HTTP API と同様に、文字列または文字列の配列を受け付ける。
+func payloadStrings(v js.Value) ([]string, bool) {
+	if v.Type() == js.TypeString {
+		return []string{v.String()}, true
+	}
+	if !js.Global().Get("Array").Call("isArray", v).Bool() {
+		return nil, false
+	}
+
+	values := make([]string, v.Length())
+	for i := range values {
+		item := v.Index(i)
+		if item.Type() != js.TypeString {
+			return nil, false
+		}
+		values[i] = item.String()
+	}
+	return values, true
+}
+
+func parse(body string) js.Value {
+	return js.Global().Get("JSON").Call("parse", body)
+}
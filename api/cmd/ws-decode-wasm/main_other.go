@@ -0,0 +1,13 @@
+//go:build !(js && wasm)
+
+package main
+
+import (
+	"fmt"
+	"os"
+)
+
+func main() {
+	fmt.Fprintln(os.Stderr, "ws-decode-wasm: build with GOOS=js GOARCH=wasm")
+	os.Exit(2)
+}
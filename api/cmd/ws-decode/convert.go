@@ -0,0 +1,83 @@
+package main
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/2509-hackz-ichthyo/main/api/internal/app"
+	"github.com/2509-hackz-ichthyo/main/api/internal/domain"
+)
+
+// converter は ws-decode が依存するユースケースの最小限のインタフェース。
+type converter interface {
+	Execute(ctx context.Context, command app.WhitespaceCommand) (app.WhitespaceResult, error)
+}
+
+// lineError は入力中の行番号付きのエラーを表す。
+type lineError struct {
+	line int
+	err  error
+}
+
+func (e *lineError) Error() string {
+	return fmt.Sprintf("%d: %v", e.line, e.err)
+}
+
+func (e *lineError) Unwrap() error {
+	return e.err
+}
+
+// converted は 1 つの source を変換した結果を表す。
+type converted struct {
+	source      string
+	commandType domain.CommandType
+	resultKind  domain.ResultKind
+	lines       []int
+	values      []string
+}
+
+// convert は source を文ごとに変換する。
+// どの文が不正かを行番号で示せるよう、文を 1 つずつユースケースに渡し、すべてのエラーを集める。
+func convert(ctx context.Context, uc converter, commandType domain.CommandType, src source) (converted, []error) {
+	if src.err != nil {
+		return converted{}, []error{src.err}
+	}
+
+	format, _ := commandType.SourceFormat()
+	sentences, splitErr := splitSentences(format, src.text)
+
+	out := converted{source: src.name, commandType: commandType}
+	var errs []error
+	for _, s := range sentences {
+		result, err := uc.Execute(ctx, app.WhitespaceCommand{
+			CommandType: string(commandType),
+			Payload:     []string{s.text},
+		})
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s:%w", src.name, &lineError{line: s.line, err: err}))
+			continue
+		}
+		out.resultKind = result.ResultKind
+		out.lines = append(out.lines, s.line)
+		out.values = append(out.values, resultValues(result)...)
+	}
+
+	if splitErr != nil {
+		errs = append(errs, fmt.Errorf("%s:%w", src.name, splitErr))
+	}
+	if len(sentences) == 0 && len(errs) == 0 {
+		errs = append(errs, fmt.Errorf("%s: no sentences found", src.name))
+	}
+	return out, errs
+}
+
+func resultValues(result app.WhitespaceResult) []string {
+	switch result.ResultKind {
+	case domain.ResultKindDecimalSequence:
+		return result.ResultDecimals
+	case domain.ResultKindBinarySequence:
+		return result.ResultBinaries
+	default:
+		return result.ResultWhitespace
+	}
+}
@@ -0,0 +1,158 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/2509-hackz-ichthyo/main/api/internal/domain"
+	"github.com/2509-hackz-ichthyo/main/api/pkg/wscodec"
+)
+
+// newlineReplacer は数列の入力の改行を wscodec と同じく CRLF / CR から LF に揃える。
+var newlineReplacer = strings.NewReplacer("\r\n", "\n", "\r", "\n")
+
+// maxRecordSize は DynamoDB エクスポートの 1 行として読み込む最大バイト数。
+const maxRecordSize = 16 << 20
+
+// source は変換対象の 1 単位（ファイル、または DynamoDB エクスポートの 1 項目）を表す。
+// name はエラーメッセージの位置表示と出力の source 列に用いる。
+type source struct {
+	name string
+	text string
+	err  error
+}
+
+// sentence は 1 文分のペイロードと、その文が始まる行番号（1 始まり）を表す。
+type sentence struct {
+	line int
+	text string
+}
+
+// readSources は引数のファイル（省略時・"-" の場合は標準入力）を読み込む。
+// dynamoDBAttr を指定した場合は各ファイルを DynamoDB の JSON エクスポートとして項目ごとに分割する。
+func readSources(opts options, stdin io.Reader) ([]source, error) {
+	files := opts.files
+	if len(files) == 0 {
+		files = []string{"-"}
+	}
+
+	var sources []source
+	for _, name := range files {
+		r, closeFn, err := open(name, stdin)
+		if err != nil {
+			return nil, err
+		}
+
+		if opts.dynamoDBAttr != "" {
+			records, err := readDynamoDBExport(displayName(name), r, opts.dynamoDBAttr)
+			closeFn()
+			if err != nil {
+				return nil, err
+			}
+			sources = append(sources, records...)
+			continue
+		}
+
+		data, err := io.ReadAll(r)
+		closeFn()
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", displayName(name), err)
+		}
+		sources = append(sources, source{name: displayName(name), text: string(data)})
+	}
+	return sources, nil
+}
+
+func open(name string, stdin io.Reader) (io.Reader, func(), error) {
+	if name == "-" {
+		return stdin, func() {}, nil
+	}
+	f, err := os.Open(name)
+	if err != nil {
+		return nil, nil, err
+	}
+	return f, func() { _ = f.Close() }, nil
+}
+
+func displayName(name string) string {
+	if name == "-" {
+		return "<stdin>"
+	}
+	return name
+}
+
+// readDynamoDBExport は DynamoDB の JSON エクスポート（1 行 1 項目、{"Item": {...}} 形式）から
+// 文字列属性 attr を取り出す。Item で包まれていない項目もそのまま受け付ける。
+// 属性が無い項目はエラーとして記録し、他の項目の変換は続ける。
+func readDynamoDBExport(name string, r io.Reader, attr string) ([]source, error) {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), maxRecordSize)
+
+	var sources []source
+	for lineNum := 1; scanner.Scan(); lineNum++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+
+		src := source{name: fmt.Sprintf("%s:%d:%s", name, lineNum, attr)}
+		var record struct {
+			Item map[string]map[string]json.RawMessage `json:"Item"`
+		}
+		var item map[string]map[string]json.RawMessage
+		if err := json.Unmarshal([]byte(line), &record); err == nil && record.Item != nil {
+			item = record.Item
+		} else if err := json.Unmarshal([]byte(line), &item); err != nil {
+			src.err = fmt.Errorf("%s:%d: invalid DynamoDB JSON: %w", name, lineNum, err)
+			sources = append(sources, src)
+			continue
+		}
+
+		value, ok := item[attr]["S"]
+		if !ok {
+			src.err = fmt.Errorf("%s:%d: string attribute %q not found", name, lineNum, attr)
+			sources = append(sources, src)
+			continue
+		}
+		if err := json.Unmarshal(value, &src.text); err != nil {
+			src.err = fmt.Errorf("%s:%d: attribute %q: %w", name, lineNum, attr, err)
+		}
+		sources = append(sources, src)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("%s: %w", name, err)
+	}
+	return sources, nil
+}
+
+// splitSentences は入力を文に分割する。
+// Whitespace は wscodec.SplitWhitespaceLines と同じく空行を除いた 3 行を 1 文とし、数列は空行を除いた 1 行を 1 文とする。
+func splitSentences(format domain.PayloadFormat, text string) ([]sentence, error) {
+	if format != domain.PayloadFormatWhitespace {
+		var sentences []sentence
+		for i, line := range strings.Split(newlineReplacer.Replace(text), "\n") {
+			if strings.TrimSpace(line) == "" {
+				continue
+			}
+			sentences = append(sentences, sentence{line: i + 1, text: line})
+		}
+		return sentences, nil
+	}
+
+	parts, err := wscodec.SplitWhitespaceLines(text)
+	sentences := make([]sentence, len(parts))
+	for i, part := range parts {
+		sentences[i] = sentence{line: part.Line, text: part.Text}
+	}
+
+	var incomplete *wscodec.IncompleteSentenceError
+	if errors.As(err, &incomplete) {
+		return sentences, &lineError{line: incomplete.Line, err: incomplete}
+	}
+	return sentences, err
+}
@@ -0,0 +1,152 @@
+// Command ws-decode は HTTP サーバーを介さずに Whitespace と数列の相互変換を行う。
+//
+// DynamoDB のエクスポートから取り出した gameData のような、アーカイブ済みの Whitespace を
+// ローカルで手早く確認するためのツール。
+//
+//	ws-decode --from whitespace --to decimal game.txt
+//	ws-decode --from decimal --to whitespace --output raw < moves.txt
+//	ws-decode --dynamodb-attr gameData --output csv export.json
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/2509-hackz-ichthyo/main/api/internal/app"
+	"github.com/2509-hackz-ichthyo/main/api/internal/domain"
+)
+
+// 終了コード。
+const (
+	exitOK         = 0
+	exitConvertErr = 1
+	exitUsageErr   = 2
+)
+
+// conversions は --from / --to の組み合わせと命令種別の対応。
+var conversions = map[[2]domain.PayloadFormat]domain.CommandType{
+	{domain.PayloadFormatWhitespace, domain.PayloadFormatDecimal}: domain.CommandTypeWhitespaceToDecimal,
+	{domain.PayloadFormatWhitespace, domain.PayloadFormatBinary}:  domain.CommandTypeWhitespaceToBinary,
+	{domain.PayloadFormatDecimal, domain.PayloadFormatWhitespace}: domain.CommandTypeDecimalToWhitespace,
+	{domain.PayloadFormatBinary, domain.PayloadFormatWhitespace}:  domain.CommandTypeBinariesToWhitespace,
+}
+
+var errUsage = errors.New("usage error")
+
+func main() {
+	os.Exit(run(context.Background(), os.Args[1:], os.Stdin, os.Stdout, os.Stderr))
+}
+
+// options はコマンドライン引数を解釈した結果を表す。
+type options struct {
+	commandType  domain.CommandType
+	output       string
+	dynamoDBAttr string
+	files        []string
+}
+
+func run(ctx context.Context, args []string, stdin io.Reader, stdout, stderr io.Writer) int {
+	opts, err := parseArgs(args, stderr)
+	if err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return exitOK
+		}
+		fmt.Fprintf(stderr, "ws-decode: %v\n", err)
+		return exitUsageErr
+	}
+
+	w, err := newWriter(opts.output, stdout)
+	if err != nil {
+		fmt.Fprintf(stderr, "ws-decode: %v\n", err)
+		return exitUsageErr
+	}
+
+	sources, err := readSources(opts, stdin)
+	if err != nil {
+		fmt.Fprintf(stderr, "ws-decode: %v\n", err)
+		return exitUsageErr
+	}
+
+	uc := app.NewWhitespaceUsecase()
+	code := exitOK
+	for _, src := range sources {
+		converted, errs := convert(ctx, uc, opts.commandType, src)
+		for _, err := range errs {
+			fmt.Fprintln(stderr, err)
+		}
+		if len(errs) > 0 {
+			code = exitConvertErr
+			continue
+		}
+		if err := w.write(converted); err != nil {
+			fmt.Fprintf(stderr, "ws-decode: %v\n", err)
+			return exitConvertErr
+		}
+	}
+
+	if err := w.flush(); err != nil {
+		fmt.Fprintf(stderr, "ws-decode: %v\n", err)
+		return exitConvertErr
+	}
+	return code
+}
+
+func parseArgs(args []string, stderr io.Writer) (options, error) {
+	fs := flag.NewFlagSet("ws-decode", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+
+	from := fs.String("from", "whitespace", "入力の表現（whitespace / decimal / binary）")
+	to := fs.String("to", "", "出力の表現（whitespace / decimal / binary）。省略時は whitespace なら decimal、それ以外は whitespace")
+	output := fs.String("output", "json", "出力形式（json / csv / raw）")
+	dynamoDBAttr := fs.String("dynamodb-attr", "", "入力を DynamoDB の JSON エクスポート（1 行 1 項目）として読み、指定した属性を変換する")
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "usage: ws-decode [flags] [file ...]")
+		fmt.Fprintln(fs.Output(), "ファイルを省略した場合、または - を指定した場合は標準入力を読む。")
+		fs.PrintDefaults()
+	}
+
+	if err := fs.Parse(args); err != nil {
+		return options{}, err
+	}
+
+	fromFormat, err := parseFormat(*from)
+	if err != nil {
+		return options{}, fmt.Errorf("--from: %w", err)
+	}
+	toFormat := domain.PayloadFormatWhitespace
+	if fromFormat == domain.PayloadFormatWhitespace {
+		toFormat = domain.PayloadFormatDecimal
+	}
+	if *to != "" {
+		if toFormat, err = parseFormat(*to); err != nil {
+			return options{}, fmt.Errorf("--to: %w", err)
+		}
+	}
+
+	commandType, ok := conversions[[2]domain.PayloadFormat{fromFormat, toFormat}]
+	if !ok {
+		return options{}, fmt.Errorf("%w: conversion from %s to %s is not supported", errUsage, *from, toFormat)
+	}
+
+	return options{
+		commandType:  commandType,
+		output:       *output,
+		dynamoDBAttr: *dynamoDBAttr,
+		files:        fs.Args(),
+	}, nil
+}
+
+// parseFormat は小文字でも指定できるよう、大文字小文字を区別せずに PayloadFormat を解釈する。
+func parseFormat(raw string) (domain.PayloadFormat, error) {
+	for _, format := range []domain.PayloadFormat{domain.PayloadFormatWhitespace, domain.PayloadFormatDecimal, domain.PayloadFormatBinary} {
+		if strings.EqualFold(raw, string(format)) {
+			return format, nil
+		}
+	}
+	return domain.ParsePayloadFormat(raw)
+}
@@ -0,0 +1,132 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// sentence123 / sentence15 はそれぞれ "1 2 3" / "15 15 255" を表す Whitespace の文。
+const (
+	sentence123 = "      \t\n     \t \n         \t\t\n"
+	sentence15  = "   \t\t\t\t\n   \t\t\t\t\n   \t\t\t\t\t\t\t\t\n"
+)
+
+func runCLI(t *testing.T, stdin string, args ...string) (string, string, int) {
+	t.Helper()
+	var stdout, stderr bytes.Buffer
+	code := run(context.Background(), args, strings.NewReader(stdin), &stdout, &stderr)
+	return stdout.String(), stderr.String(), code
+}
+
+func TestRunWhitespaceToDecimalJSON(t *testing.T) {
+	stdout, stderr, code := runCLI(t, sentence123+sentence15)
+	if code != exitOK {
+		t.Fatalf("exit code = %d, stderr = %q", code, stderr)
+	}
+
+	var got jsonRecord
+	if err := json.Unmarshal([]byte(stdout), &got); err != nil {
+		t.Fatalf("failed to unmarshal output %q: %v", stdout, err)
+	}
+	if got.Source != "<stdin>" || got.CommandType != "WhitespaceToDecimal" {
+		t.Fatalf("unexpected record: %+v", got)
+	}
+	if len(got.Results) != 2 || got.Results[0] != "1 2 3" || got.Results[1] != "15 15 255" {
+		t.Fatalf("Results = %q", got.Results)
+	}
+}
+
+func TestRunDecimalToWhitespaceRaw(t *testing.T) {
+	stdout, stderr, code := runCLI(t, "1 2 3\n\n15 15 255\n", "--from", "decimal", "--output", "raw")
+	if code != exitOK {
+		t.Fatalf("exit code = %d, stderr = %q", code, stderr)
+	}
+	if stdout != sentence123+sentence15 {
+		t.Fatalf("stdout = %q, want %q", stdout, sentence123+sentence15)
+	}
+}
+
+func TestRunCSVReportsSourceLines(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "game.txt")
+	if err := os.WriteFile(path, []byte("\n"+sentence123+sentence15), 0o600); err != nil {
+		t.Fatalf("failed to write input: %v", err)
+	}
+
+	stdout, stderr, code := runCLI(t, "", "--to", "binary", "--output", "csv", path)
+	if code != exitOK {
+		t.Fatalf("exit code = %d, stderr = %q", code, stderr)
+	}
+
+	want := "source,line,result\n" +
+		path + ",2,0001 0010 00000011\n" +
+		path + ",5,1111 1111 11111111\n"
+	if stdout != want {
+		t.Fatalf("stdout = %q, want %q", stdout, want)
+	}
+}
+
+func TestRunReportsLineErrors(t *testing.T) {
+	input := sentence123 + "   x   \n      \t\n         \t\t\n" + "      \t\n"
+	stdout, stderr, code := runCLI(t, input)
+	if code != exitConvertErr {
+		t.Fatalf("exit code = %d, want %d", code, exitConvertErr)
+	}
+	if stdout != "" {
+		t.Fatalf("stdout = %q, want empty output for failed source", stdout)
+	}
+
+	lines := strings.Split(strings.TrimSpace(stderr), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("stderr = %q, want 2 errors", stderr)
+	}
+	if !strings.HasPrefix(lines[0], "<stdin>:4: ") {
+		t.Fatalf("first error = %q, want line 4", lines[0])
+	}
+	if lines[1] != "<stdin>:7: incomplete sentence: expected 3 lines, got 1" {
+		t.Fatalf("second error = %q", lines[1])
+	}
+}
+
+func TestRunDynamoDBExport(t *testing.T) {
+	sentence, err := json.Marshal(sentence123)
+	if err != nil {
+		t.Fatalf("failed to marshal: %v", err)
+	}
+	export := `{"Item":{"gameId":{"S":"g1"},"gameData":{"S":` + string(sentence) + `}}}` + "\n" +
+		`{"Item":{"gameId":{"S":"g2"}}}` + "\n"
+
+	stdout, stderr, code := runCLI(t, export, "--dynamodb-attr", "gameData", "--output", "csv")
+	if code != exitConvertErr {
+		t.Fatalf("exit code = %d, want %d", code, exitConvertErr)
+	}
+	if stdout != "source,line,result\n<stdin>:1:gameData,1,1 2 3\n" {
+		t.Fatalf("stdout = %q", stdout)
+	}
+	if strings.TrimSpace(stderr) != `<stdin>:2: string attribute "gameData" not found` {
+		t.Fatalf("stderr = %q", stderr)
+	}
+}
+
+func TestRunUsageErrors(t *testing.T) {
+	cases := map[string][]string{
+		"unsupported conversion": {"--from", "decimal", "--to", "binary"},
+		"unknown format":         {"--from", "hex"},
+		"unknown output":         {"--output", "yaml"},
+		"unknown flag":           {"--verbose"},
+		"missing file":           {filepath.Join(t.TempDir(), "missing.txt")},
+	}
+
+	for name, args := range cases {
+		t.Run(name, func(t *testing.T) {
+			_, stderr, code := runCLI(t, "", args...)
+			if code != exitUsageErr {
+				t.Fatalf("exit code = %d, want %d (stderr = %q)", code, exitUsageErr, stderr)
+			}
+		})
+	}
+}
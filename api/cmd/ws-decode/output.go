@@ -0,0 +1,105 @@
+package main
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+
+	"github.com/2509-hackz-ichthyo/main/api/internal/domain"
+)
+
+// writer は変換結果を指定の形式で出力する。
+type writer interface {
+	write(c converted) error
+	flush() error
+}
+
+func newWriter(format string, w io.Writer) (writer, error) {
+	switch format {
+	case "json":
+		return &jsonWriter{enc: json.NewEncoder(w)}, nil
+	case "csv":
+		return &csvWriter{w: csv.NewWriter(w)}, nil
+	case "raw":
+		return &rawWriter{w: bufio.NewWriter(w)}, nil
+	default:
+		return nil, fmt.Errorf("%w: unsupported output %q (want json, csv or raw)", errUsage, format)
+	}
+}
+
+// jsonWriter は source ごとに 1 行の JSON（JSON Lines）を出力する。
+type jsonWriter struct {
+	enc *json.Encoder
+}
+
+type jsonRecord struct {
+	Source      string   `json:"source"`
+	CommandType string   `json:"command_type"`
+	ResultKind  string   `json:"result_kind"`
+	Results     []string `json:"results"`
+}
+
+func (w *jsonWriter) write(c converted) error {
+	return w.enc.Encode(jsonRecord{
+		Source:      c.source,
+		CommandType: string(c.commandType),
+		ResultKind:  string(c.resultKind),
+		Results:     c.values,
+	})
+}
+
+func (w *jsonWriter) flush() error {
+	return nil
+}
+
+// csvWriter は 1 文につき 1 行を、入力での行番号とともに出力する。
+type csvWriter struct {
+	w             *csv.Writer
+	headerWritten bool
+}
+
+func (w *csvWriter) write(c converted) error {
+	if !w.headerWritten {
+		if err := w.w.Write([]string{"source", "line", "result"}); err != nil {
+			return err
+		}
+		w.headerWritten = true
+	}
+	for i, value := range c.values {
+		if err := w.w.Write([]string{c.source, strconv.Itoa(c.lines[i]), value}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (w *csvWriter) flush() error {
+	w.w.Flush()
+	return w.w.Error()
+}
+
+// rawWriter は結果をそのまま出力する。Whitespace は各文が改行で終わるため連結し、数列は 1 行 1 文とする。
+type rawWriter struct {
+	w *bufio.Writer
+}
+
+func (w *rawWriter) write(c converted) error {
+	for _, value := range c.values {
+		if _, err := w.w.WriteString(value); err != nil {
+			return err
+		}
+		if c.resultKind != domain.ResultKindWhitespace {
+			if err := w.w.WriteByte('\n'); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (w *rawWriter) flush() error {
+	return w.w.Flush()
+}
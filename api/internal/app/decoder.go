@@ -4,10 +4,14 @@ import (
 	"context"
 	"fmt"
 	"net/url"
-	"strconv"
 	"strings"
 
 	"github.com/2509-hackz-ichthyo/main/api/internal/domain"
+	"github.com/2509-hackz-ichthyo/main/api/pkg/wscodec"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
 )
 
 // WhitespaceCommand はユースケースが受け取る命令を表す。
@@ -28,27 +32,59 @@ type WhitespaceResult struct {
 }
 
 // WhitespaceUsecase は入力を検証し、各種フォーマット間の変換を担う。
-type WhitespaceUsecase struct{}
+// 変換規則そのものは wscodec にあり、ここでは命令の解釈・検証とトレースのみを行う。
+type WhitespaceUsecase struct {
+	envelope EnvelopeKeys
+}
 
-var (
-	parseCommandTypeFunc        = domain.ParseCommandType
-	parseWhitespaceSentenceFunc = parseWhitespaceSentence
-	decimalStringToBinaryFunc   = decimalStringToBinary
-	normalizeBinaryStringFunc   = normalizeBinaryString
-	bitsToWhitespaceFunc        = bitsToWhitespace
-)
+// Option は WhitespaceUsecase の任意設定を表す。
+type Option func(*WhitespaceUsecase)
+
+// WithEnvelopeKeys は暗号化エンベロープ命令で用いる鍵を設定する。
+func WithEnvelopeKeys(keys EnvelopeKeys) Option {
+	return func(u *WhitespaceUsecase) {
+		u.envelope = keys
+	}
+}
 
 var (
-	extractSegmentsFunc = extractSegments
+	parseCommandTypeFunc = domain.ParseCommandType
+	parseWhitespaceFunc  = wscodec.ParseWhitespace
+	parseDecimalFunc     = wscodec.ParseDecimal
+	parseBinaryFunc      = wscodec.ParseBinary
 )
 
+var tracer = otel.Tracer("github.com/2509-hackz-ichthyo/main/api/internal/app")
+
 // NewWhitespaceUsecase は WhitespaceUsecase を生成する。
-func NewWhitespaceUsecase() *WhitespaceUsecase {
-	return &WhitespaceUsecase{}
+func NewWhitespaceUsecase(opts ...Option) *WhitespaceUsecase {
+	u := &WhitespaceUsecase{}
+	for _, opt := range opts {
+		opt(u)
+	}
+	return u
 }
 
 // Execute は入力を検証し、Whitespace の変換結果を返す。
-func (u *WhitespaceUsecase) Execute(_ context.Context, command WhitespaceCommand) (WhitespaceResult, error) {
+func (u *WhitespaceUsecase) Execute(ctx context.Context, command WhitespaceCommand) (WhitespaceResult, error) {
+	_, span := tracer.Start(ctx, "WhitespaceUsecase.Execute", trace.WithAttributes(
+		attribute.String("ws.command_type", command.CommandType),
+		attribute.Int("ws.sentences", len(command.Payload)),
+	))
+	defer span.End()
+
+	result, err := u.execute(ctx, command)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	return result, err
+}
+
+func (u *WhitespaceUsecase) execute(ctx context.Context, command WhitespaceCommand) (WhitespaceResult, error) {
+	if err := ctx.Err(); err != nil {
+		return WhitespaceResult{}, err
+	}
 	if strings.TrimSpace(command.CommandType) == "" {
 		return WhitespaceResult{}, fmt.Errorf("%w: commandType must not be blank", ErrValidationFailed)
 	}
@@ -63,26 +99,26 @@ func (u *WhitespaceUsecase) Execute(_ context.Context, command WhitespaceCommand
 
 	switch commandType {
 	case domain.CommandTypeWhitespaceToBinary:
-		return u.whitespaceToBinary(command.Payload)
+		return u.whitespaceToBinary(ctx, command.Payload)
 	case domain.CommandTypeWhitespaceToDecimal:
-		return u.whitespaceToDecimal(command.Payload)
+		return u.whitespaceToDecimal(ctx, command.Payload)
 	case domain.CommandTypeDecimalToWhitespace:
-		return u.decimalToWhitespace(command.Payload)
+		return u.decimalToWhitespace(ctx, command.Payload)
 	case domain.CommandTypeBinariesToWhitespace:
-		return u.binaryToWhitespace(command.Payload)
+		return u.binaryToWhitespace(ctx, command.Payload)
+	case domain.CommandTypeEncryptedDecimalToWhitespace:
+		return u.encryptedDecimalToWhitespace(command.Payload)
+	case domain.CommandTypeEncryptedWhitespaceToDecimal:
+		return u.encryptedWhitespaceToDecimal(command.Payload)
 	default:
 		return WhitespaceResult{}, domain.ErrTypeMismatch
 	}
 }
 
-func (WhitespaceUsecase) whitespaceToBinary(payload []string) (WhitespaceResult, error) {
-	binaries := make([]string, len(payload))
-	for i, sentence := range payload {
-		parsed, err := parseWhitespaceSentenceFunc(sentence)
-		if err != nil {
-			return WhitespaceResult{}, err
-		}
-		binaries[i] = parsed.formatted
+func (WhitespaceUsecase) whitespaceToBinary(ctx context.Context, payload []string) (WhitespaceResult, error) {
+	binaries, err := convertEach(ctx, payload, parseWhitespaceFunc, wscodec.Sentence.Binary)
+	if err != nil {
+		return WhitespaceResult{}, err
 	}
 
 	return WhitespaceResult{
@@ -92,14 +128,10 @@ func (WhitespaceUsecase) whitespaceToBinary(payload []string) (WhitespaceResult,
 	}, nil
 }
 
-func (WhitespaceUsecase) whitespaceToDecimal(payload []string) (WhitespaceResult, error) {
-	decimals := make([]string, len(payload))
-	for i, sentence := range payload {
-		parsed, err := parseWhitespaceSentenceFunc(sentence)
-		if err != nil {
-			return WhitespaceResult{}, err
-		}
-		decimals[i] = strings.Join(parsed.decimals, " ")
+func (WhitespaceUsecase) whitespaceToDecimal(ctx context.Context, payload []string) (WhitespaceResult, error) {
+	decimals, err := convertEach(ctx, payload, parseWhitespaceFunc, wscodec.Sentence.Decimal)
+	if err != nil {
+		return WhitespaceResult{}, err
 	}
 
 	return WhitespaceResult{
@@ -109,195 +141,52 @@ func (WhitespaceUsecase) whitespaceToDecimal(payload []string) (WhitespaceResult
 	}, nil
 }
 
-func (WhitespaceUsecase) decimalToWhitespace(payload []string) (WhitespaceResult, error) {
-	whitespaces := make([]string, len(payload))
-	encoded := make([]string, len(payload))
-	for i, decimal := range payload {
-		binary, err := decimalStringToBinaryFunc(decimal)
-		if err != nil {
-			return WhitespaceResult{}, err
-		}
-
-		whitespace, err := bitsToWhitespaceFunc(binary)
-		if err != nil {
-			return WhitespaceResult{}, err
-		}
-
-		whitespaces[i] = whitespace
-		encoded[i] = url.PathEscape(whitespace)
-	}
-
-	return WhitespaceResult{
-		CommandType:             domain.CommandTypeDecimalToWhitespace,
-		ResultKind:              domain.ResultKindWhitespace,
-		ResultWhitespace:        whitespaces,
-		ResultWhitespaceEncoded: encoded,
-	}, nil
-}
-
-func (WhitespaceUsecase) binaryToWhitespace(payload []string) (WhitespaceResult, error) {
-	whitespaces := make([]string, len(payload))
-	encoded := make([]string, len(payload))
-	for i, binary := range payload {
-		clean, err := normalizeBinaryStringFunc(binary)
-		if err != nil {
-			return WhitespaceResult{}, err
-		}
-
-		whitespace, err := bitsToWhitespaceFunc(clean)
-		if err != nil {
-			return WhitespaceResult{}, err
-		}
-		whitespaces[i] = whitespace
-		encoded[i] = url.PathEscape(whitespace)
-	}
-
-	return WhitespaceResult{
-		CommandType:             domain.CommandTypeBinariesToWhitespace,
-		ResultKind:              domain.ResultKindWhitespace,
-		ResultWhitespace:        whitespaces,
-		ResultWhitespaceEncoded: encoded,
-	}, nil
-}
-
-type binarySentence struct {
-	formatted string
-	decimals  []string
-}
-
-func parseWhitespaceSentence(sentence string) (binarySentence, error) {
-	segments, err := extractSegmentsFunc(sentence)
+func (WhitespaceUsecase) decimalToWhitespace(ctx context.Context, payload []string) (WhitespaceResult, error) {
+	whitespaces, err := convertEach(ctx, payload, parseDecimalFunc, wscodec.Sentence.Whitespace)
 	if err != nil {
-		return binarySentence{}, err
-	}
-
-	formattedSegments := make([]string, len(segments))
-	decimals := make([]string, len(segments))
-	for i, segment := range segments {
-		var segmentBuilder strings.Builder
-		for _, r := range segment {
-			switch r {
-			case ' ':
-				segmentBuilder.WriteByte('0')
-			case '\t':
-				segmentBuilder.WriteByte('1')
-			default:
-				return binarySentence{}, fmt.Errorf("%w: unsupported rune %#U", domain.ErrInvalidPayload, r)
-			}
-		}
-		bits := segmentBuilder.String()
-		formattedSegments[i] = bits
-
-		value, err := strconv.ParseUint(bits, 2, 8)
-		if err != nil {
-			return binarySentence{}, fmt.Errorf("%w: invalid binary segment", domain.ErrInvalidPayload)
-		}
-		decimals[i] = strconv.FormatUint(value, 10)
+		return WhitespaceResult{}, err
 	}
 
-	return binarySentence{
-		formatted: strings.Join(formattedSegments, " "),
-		decimals:  decimals,
-	}, nil
+	return whitespaceResult(domain.CommandTypeDecimalToWhitespace, whitespaces), nil
 }
 
-func decimalStringToBinary(decimal string) (string, error) {
-	tokens := strings.Fields(decimal)
-	if len(tokens) != 3 {
-		return "", fmt.Errorf("%w: decimal must contain three numbers", domain.ErrInvalidPayload)
-	}
-
-	segmentLengths := []int{4, 4, 8}
-	var builder strings.Builder
-	for i, token := range tokens {
-		value, err := strconv.Atoi(token)
-		if err != nil {
-			return "", fmt.Errorf("%w: token %q is not an integer", domain.ErrInvalidPayload, token)
-		}
-		if value < 0 || value >= 1<<segmentLengths[i] {
-			return "", fmt.Errorf("%w: decimal %q out of range", domain.ErrInvalidPayload, token)
-		}
-		builder.WriteString(fmt.Sprintf("%0*b", segmentLengths[i], value))
+func (WhitespaceUsecase) binaryToWhitespace(ctx context.Context, payload []string) (WhitespaceResult, error) {
+	whitespaces, err := convertEach(ctx, payload, parseBinaryFunc, wscodec.Sentence.Whitespace)
+	if err != nil {
+		return WhitespaceResult{}, err
 	}
 
-	return builder.String(), nil
+	return whitespaceResult(domain.CommandTypeBinariesToWhitespace, whitespaces), nil
 }
 
-func normalizeBinaryString(input string) (string, error) {
-	trimmed := strings.TrimSpace(input)
-	if trimmed == "" {
-		return "", fmt.Errorf("%w: binary must not be blank", domain.ErrInvalidPayload)
-	}
-
-	clean := strings.ReplaceAll(trimmed, " ", "")
-	if len(clean) != 16 {
-		return "", fmt.Errorf("%w: binary must be 16 bits", domain.ErrInvalidPayload)
-	}
-
-	for _, r := range clean {
-		if r != '0' && r != '1' {
-			return "", fmt.Errorf("%w: binary contains invalid rune %#U", domain.ErrInvalidPayload, r)
+// convertEach は各文を parse で解釈し、format で目的の表現に変換する。
+// 大きなペイロードでも打ち切れるよう、文ごとに ctx の終了を確認する。
+func convertEach(ctx context.Context, payload []string, parse func(string) (wscodec.Sentence, error), format func(wscodec.Sentence) string) ([]string, error) {
+	out := make([]string, len(payload))
+	for i, value := range payload {
+		if err := ctx.Err(); err != nil {
+			return nil, err
 		}
-	}
-
-	return clean, nil
-}
-
-func bitsToWhitespace(bits string) (string, error) {
-	if len(bits) != 16 {
-		return "", fmt.Errorf("%w: binary must be 16 bits", domain.ErrInvalidPayload)
-	}
-
-	segments := []string{bits[:4], bits[4:8], bits[8:]}
-	var builder strings.Builder
-	for _, segment := range segments {
-		builder.WriteString("   ")
-		for _, bit := range segment {
-			switch bit {
-			case '0':
-				builder.WriteByte(' ')
-			case '1':
-				builder.WriteByte('\t')
-			default:
-				return "", fmt.Errorf("%w: binary contains invalid rune %#U", domain.ErrInvalidPayload, bit)
-			}
+		sentence, err := parse(value)
+		if err != nil {
+			return nil, err
 		}
-		builder.WriteByte('\n')
+		out[i] = format(sentence)
 	}
-
-	return builder.String(), nil
+	return out, nil
 }
 
-func extractSegments(sentence string) ([]string, error) {
-	if sentence == "" {
-		return nil, fmt.Errorf("%w: sentence must not be blank", domain.ErrInvalidPayload)
-	}
-
-	normalized := strings.ReplaceAll(sentence, "\r\n", "\n")
-	normalized = strings.ReplaceAll(normalized, "\r", "\n")
-	lines := strings.Split(normalized, "\n")
-
-	segments := make([]string, 0, 3)
-	for _, line := range lines {
-		if line == "" {
-			continue
-		}
-		if len(line) < 3 || !strings.HasPrefix(line, "   ") {
-			return nil, fmt.Errorf("%w: line must start with three spaces", domain.ErrInvalidPayload)
-		}
-		segments = append(segments, line[3:])
-	}
-
-	if len(segments) != 3 {
-		return nil, fmt.Errorf("%w: sentence must contain three lines", domain.ErrInvalidPayload)
+// whitespaceResult は Whitespace を返す命令の結果を、パーセントエンコードした表現とともに組み立てる。
+func whitespaceResult(commandType domain.CommandType, whitespaces []string) WhitespaceResult {
+	encoded := make([]string, len(whitespaces))
+	for i, whitespace := range whitespaces {
+		encoded[i] = url.PathEscape(whitespace)
 	}
 
-	lengths := []int{4, 4, 8}
-	for i, segment := range segments {
-		if len([]rune(segment)) != lengths[i] {
-			return nil, fmt.Errorf("%w: line %d must contain %d characters", domain.ErrInvalidPayload, i+1, lengths[i])
-		}
+	return WhitespaceResult{
+		CommandType:             commandType,
+		ResultKind:              domain.ResultKindWhitespace,
+		ResultWhitespace:        whitespaces,
+		ResultWhitespaceEncoded: encoded,
 	}
-
-	return segments, nil
 }
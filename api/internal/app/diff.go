@@ -0,0 +1,241 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/2509-hackz-ichthyo/main/api/internal/domain"
+	"github.com/2509-hackz-ichthyo/main/api/pkg/wscodec"
+)
+
+// DiffOperand は比較対象となるペイロードとその表現を表す。
+type DiffOperand struct {
+	Format  string   // ペイロードの表現（Whitespace / Binary / Decimal）
+	Payload []string // 文単位の配列
+}
+
+// DiffCommand は 2 つのペイロードを文単位で比較する命令を表す。
+type DiffCommand struct {
+	Left  DiffOperand
+	Right DiffOperand
+}
+
+// SentenceDiffStatus は文単位の比較結果の種類を表す。
+type SentenceDiffStatus string
+
+const (
+	// SentenceDiffChanged は両側に存在するが内容が異なる文を示す。
+	SentenceDiffChanged SentenceDiffStatus = "changed"
+
+	// SentenceDiffRemoved は左側にのみ存在する文を示す。
+	SentenceDiffRemoved SentenceDiffStatus = "removed"
+
+	// SentenceDiffAdded は右側にのみ存在する文を示す。
+	SentenceDiffAdded SentenceDiffStatus = "added"
+)
+
+// SegmentDiff は 1 文の中で値が異なるセグメント（4bit/4bit/8bit）を表す。
+// BitPositions はセグメント先頭（最上位ビット）を 0 とした位置の一覧。
+type SegmentDiff struct {
+	Segment      int
+	BitPositions []int
+	Before       int
+	After        int
+}
+
+// SentenceDiff は差分のある 1 文を表す。LeftIndex / RightIndex は各ペイロードでの 0 始まりの文番号で、
+// 片側にしか存在しない文では存在しない側が -1、Before / After のどちらかが空文字列になる。
+type SentenceDiff struct {
+	LeftIndex  int
+	RightIndex int
+	Status     SentenceDiffStatus
+	Before     string
+	After      string
+	Segments   []SegmentDiff
+}
+
+// DiffResult は比較結果を API 層へ渡すための DTO。
+type DiffResult struct {
+	LeftCount  int
+	RightCount int
+	Identical  bool
+	Sentences  []SentenceDiff
+}
+
+var segmentBounds = [][2]int{{0, 4}, {4, 8}, {8, 16}}
+
+// Diff は 2 つのペイロードを 16bit の文に揃え、一致する文を LCS で対応付けて差分を返す。
+// 一致した文の間に挟まれた区間では、左右に残った文を先頭から順に changed として組にし、
+// 余った文を removed / added として報告する。
+func (u *WhitespaceUsecase) Diff(_ context.Context, command DiffCommand) (DiffResult, error) {
+	left, err := sentenceBits("left", command.Left)
+	if err != nil {
+		return DiffResult{}, err
+	}
+	right, err := sentenceBits("right", command.Right)
+	if err != nil {
+		return DiffResult{}, err
+	}
+
+	result := DiffResult{LeftCount: len(left), RightCount: len(right)}
+	var removed, added []int
+	flush := func() {
+		paired := min(len(removed), len(added))
+		for k := 0; k < paired; k++ {
+			i, j := removed[k], added[k]
+			result.Sentences = append(result.Sentences, SentenceDiff{
+				LeftIndex:  i,
+				RightIndex: j,
+				Status:     SentenceDiffChanged,
+				Before:     bitsToDecimalString(left[i]),
+				After:      bitsToDecimalString(right[j]),
+				Segments:   diffSegments(left[i], right[j]),
+			})
+		}
+		for _, i := range removed[paired:] {
+			result.Sentences = append(result.Sentences, SentenceDiff{
+				LeftIndex:  i,
+				RightIndex: -1,
+				Status:     SentenceDiffRemoved,
+				Before:     bitsToDecimalString(left[i]),
+			})
+		}
+		for _, j := range added[paired:] {
+			result.Sentences = append(result.Sentences, SentenceDiff{
+				LeftIndex:  -1,
+				RightIndex: j,
+				Status:     SentenceDiffAdded,
+				After:      bitsToDecimalString(right[j]),
+			})
+		}
+		removed, added = removed[:0], added[:0]
+	}
+
+	for _, op := range alignSentences(left, right) {
+		switch {
+		case op.left >= 0 && op.right >= 0:
+			flush()
+		case op.left >= 0:
+			removed = append(removed, op.left)
+		default:
+			added = append(added, op.right)
+		}
+	}
+	flush()
+	result.Identical = len(result.Sentences) == 0
+
+	return result, nil
+}
+
+// editOp は編集スクリプトの 1 手を表す。一致した文は両方の番号を持ち、
+// 片側にしか無い文は存在しない側が -1 になる。
+type editOp struct {
+	left  int
+	right int
+}
+
+// alignSentences は左右の文の最長共通部分列を求め、先頭から順の編集スクリプトを返す。
+func alignSentences(left, right []string) []editOp {
+	// lcs[i][j] は left[i:] と right[j:] の最長共通部分列の長さ。
+	lcs := make([][]int, len(left)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(right)+1)
+	}
+	for i := len(left) - 1; i >= 0; i-- {
+		for j := len(right) - 1; j >= 0; j-- {
+			if left[i] == right[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+
+	ops := make([]editOp, 0, max(len(left), len(right)))
+	i, j := 0, 0
+	for i < len(left) || j < len(right) {
+		switch {
+		case i < len(left) && j < len(right) && left[i] == right[j]:
+			ops = append(ops, editOp{left: i, right: j})
+			i++
+			j++
+		case j == len(right) || (i < len(left) && lcs[i+1][j] >= lcs[i][j+1]):
+			ops = append(ops, editOp{left: i, right: -1})
+			i++
+		default:
+			ops = append(ops, editOp{left: -1, right: j})
+			j++
+		}
+	}
+	return ops
+}
+
+// sentenceBits はペイロードを表現に応じて解釈し、各文を区切り無しの 16bit 文字列に揃える。
+func sentenceBits(side string, operand DiffOperand) ([]string, error) {
+	if len(operand.Payload) == 0 {
+		return nil, fmt.Errorf("%w: %s payload must not be blank", ErrValidationFailed, side)
+	}
+
+	format, err := domain.ParsePayloadFormat(operand.Format)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", side, err)
+	}
+
+	parse := map[domain.PayloadFormat]func(string) (wscodec.Sentence, error){
+		domain.PayloadFormatWhitespace: parseWhitespaceFunc,
+		domain.PayloadFormatBinary:     parseBinaryFunc,
+		domain.PayloadFormatDecimal:    parseDecimalFunc,
+	}[format]
+
+	bits := make([]string, len(operand.Payload))
+	for i, value := range operand.Payload {
+		sentence, err := parse(value)
+		if err != nil {
+			return nil, fmt.Errorf("%s sentence %d: %w", side, i, err)
+		}
+		bits[i] = sentence.Bits()
+	}
+
+	return bits, nil
+}
+
+func diffSegments(before, after string) []SegmentDiff {
+	var segments []SegmentDiff
+	for i, bounds := range segmentBounds {
+		b, a := before[bounds[0]:bounds[1]], after[bounds[0]:bounds[1]]
+		if b == a {
+			continue
+		}
+
+		positions := make([]int, 0, len(b))
+		for j := range b {
+			if b[j] != a[j] {
+				positions = append(positions, j)
+			}
+		}
+
+		segments = append(segments, SegmentDiff{
+			Segment:      i,
+			BitPositions: positions,
+			Before:       bitsToInt(b),
+			After:        bitsToInt(a),
+		})
+	}
+	return segments
+}
+
+func bitsToDecimalString(bits string) string {
+	decimals := make([]string, len(segmentBounds))
+	for i, bounds := range segmentBounds {
+		decimals[i] = strconv.Itoa(bitsToInt(bits[bounds[0]:bounds[1]]))
+	}
+	return strings.Join(decimals, " ")
+}
+
+func bitsToInt(bits string) int {
+	// bits は sentenceBits で検証済みのため、ここでは失敗しない。
+	value, _ := strconv.ParseUint(bits, 2, 8)
+	return int(value)
+}
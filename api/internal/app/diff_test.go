@@ -0,0 +1,156 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/2509-hackz-ichthyo/main/api/internal/domain"
+)
+
+func TestWhitespaceUsecaseDiffAcrossFormats(t *testing.T) {
+	usecase := NewWhitespaceUsecase()
+
+	result, err := usecase.Diff(context.Background(), DiffCommand{
+		Left: DiffOperand{
+			Format: "Whitespace",
+			Payload: []string{
+				"   \t \t\t\n    \t\t \n   \t\t \t  \t \n",
+				"       \n       \n           \n",
+			},
+		},
+		Right: DiffOperand{
+			Format:  "Decimal",
+			Payload: []string{"11 6 210", "0 2 1", "3 3 3"},
+		},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if result.Identical {
+		t.Fatal("expected payloads to differ")
+	}
+	if result.LeftCount != 2 || result.RightCount != 3 {
+		t.Fatalf("counts = (%d, %d), want (2, 3)", result.LeftCount, result.RightCount)
+	}
+	if len(result.Sentences) != 2 {
+		t.Fatalf("expected 2 differing sentences, got %d", len(result.Sentences))
+	}
+
+	changed := result.Sentences[0]
+	if changed.LeftIndex != 1 || changed.RightIndex != 1 || changed.Status != SentenceDiffChanged {
+		t.Fatalf("unexpected sentence diff: %+v", changed)
+	}
+	if changed.Before != "0 0 0" || changed.After != "0 2 1" {
+		t.Fatalf("before/after = (%q, %q), want (\"0 0 0\", \"0 2 1\")", changed.Before, changed.After)
+	}
+	if len(changed.Segments) != 2 {
+		t.Fatalf("expected 2 changed segments, got %d", len(changed.Segments))
+	}
+	if got := changed.Segments[0]; got.Segment != 1 || got.Before != 0 || got.After != 2 || len(got.BitPositions) != 1 || got.BitPositions[0] != 2 {
+		t.Fatalf("unexpected segment diff: %+v", got)
+	}
+	if got := changed.Segments[1]; got.Segment != 2 || got.Before != 0 || got.After != 1 || len(got.BitPositions) != 1 || got.BitPositions[0] != 7 {
+		t.Fatalf("unexpected segment diff: %+v", got)
+	}
+
+	added := result.Sentences[1]
+	if added.LeftIndex != -1 || added.RightIndex != 2 || added.Status != SentenceDiffAdded || added.After != "3 3 3" || added.Before != "" {
+		t.Fatalf("unexpected added sentence: %+v", added)
+	}
+}
+
+func TestWhitespaceUsecaseDiffIdentical(t *testing.T) {
+	usecase := NewWhitespaceUsecase()
+
+	result, err := usecase.Diff(context.Background(), DiffCommand{
+		Left:  DiffOperand{Format: "Binary", Payload: []string{"1011 0110 11010010"}},
+		Right: DiffOperand{Format: "Decimal", Payload: []string{"11 6 210"}},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if !result.Identical || len(result.Sentences) != 0 {
+		t.Fatalf("expected identical result, got %+v", result)
+	}
+}
+
+func TestWhitespaceUsecaseDiffRemoved(t *testing.T) {
+	usecase := NewWhitespaceUsecase()
+
+	result, err := usecase.Diff(context.Background(), DiffCommand{
+		Left:  DiffOperand{Format: "Decimal", Payload: []string{"1 2 3", "4 5 6"}},
+		Right: DiffOperand{Format: "Decimal", Payload: []string{"1 2 3"}},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(result.Sentences) != 1 || result.Sentences[0].Status != SentenceDiffRemoved || result.Sentences[0].Before != "4 5 6" {
+		t.Fatalf("unexpected diff: %+v", result.Sentences)
+	}
+}
+
+func TestWhitespaceUsecaseDiffInserted(t *testing.T) {
+	usecase := NewWhitespaceUsecase()
+
+	result, err := usecase.Diff(context.Background(), DiffCommand{
+		Left:  DiffOperand{Format: "Decimal", Payload: []string{"1 2 3", "4 5 6", "7 8 9"}},
+		Right: DiffOperand{Format: "Decimal", Payload: []string{"1 2 3", "0 0 0", "4 5 6", "7 8 9"}},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// 挿入位置以降の文はずれても一致として扱い、挿入された 1 文だけを報告する。
+	if len(result.Sentences) != 1 {
+		t.Fatalf("expected 1 differing sentence, got %+v", result.Sentences)
+	}
+	if got := result.Sentences[0]; got.Status != SentenceDiffAdded || got.LeftIndex != -1 || got.RightIndex != 1 || got.After != "0 0 0" {
+		t.Fatalf("unexpected inserted sentence: %+v", got)
+	}
+}
+
+func TestWhitespaceUsecaseDiffErrors(t *testing.T) {
+	usecase := NewWhitespaceUsecase()
+
+	cases := []struct {
+		name    string
+		command DiffCommand
+		want    error
+	}{
+		{
+			name: "blank left",
+			command: DiffCommand{
+				Right: DiffOperand{Format: "Decimal", Payload: []string{"0 0 0"}},
+			},
+			want: ErrValidationFailed,
+		},
+		{
+			name: "unknown format",
+			command: DiffCommand{
+				Left:  DiffOperand{Format: "Hex", Payload: []string{"ff"}},
+				Right: DiffOperand{Format: "Decimal", Payload: []string{"0 0 0"}},
+			},
+			want: domain.ErrInvalidPayloadFormat,
+		},
+		{
+			name: "invalid right sentence",
+			command: DiffCommand{
+				Left:  DiffOperand{Format: "Decimal", Payload: []string{"0 0 0"}},
+				Right: DiffOperand{Format: "Binary", Payload: []string{"1010"}},
+			},
+			want: domain.ErrInvalidPayload,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			if _, err := usecase.Diff(context.Background(), tc.command); err == nil || !errors.Is(err, tc.want) {
+				t.Fatalf("expected %v, got %v", tc.want, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,173 @@
+package app
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/ed25519"
+	"crypto/rand"
+	"fmt"
+	"io"
+
+	"github.com/2509-hackz-ichthyo/main/api/internal/domain"
+	"github.com/2509-hackz-ichthyo/main/api/pkg/wscodec"
+)
+
+// EnvelopeKeys は暗号化エンベロープで用いる鍵を保持する。
+// SigningKey を設定した場合、封入時に署名し、開封時には署名を必須とする。
+type EnvelopeKeys struct {
+	AESKey     []byte
+	SigningKey ed25519.PrivateKey
+}
+
+// エンベロープのバイト列は次の構成で、2 バイトずつ 1 文（16bit）の Whitespace に写像する。
+//
+//	version(1) | flags(1) | nonce(12) | ciphertext(2n) + tag(16) | signature(64, 署名時のみ)
+//
+// 各要素の長さはすべて偶数なので、全体も必ず 2 バイト単位に収まる。
+const (
+	envelopeVersion    byte = 1
+	envelopeFlagSigned byte = 1 << 0
+	envelopeHeaderSize      = 2
+)
+
+var randReader io.Reader = rand.Reader
+
+func (u *WhitespaceUsecase) encryptedDecimalToWhitespace(payload []string) (WhitespaceResult, error) {
+	if len(u.envelope.AESKey) == 0 {
+		return WhitespaceResult{}, ErrEnvelopeKeyNotConfigured
+	}
+
+	plaintext := make([]byte, 0, len(payload)*2)
+	for _, decimal := range payload {
+		sentence, err := parseDecimalFunc(decimal)
+		if err != nil {
+			return WhitespaceResult{}, err
+		}
+		b := sentence.Bytes()
+		plaintext = append(plaintext, b[:]...)
+	}
+
+	sealed, err := sealEnvelope(u.envelope, plaintext)
+	if err != nil {
+		return WhitespaceResult{}, err
+	}
+
+	whitespaces := make([]string, 0, len(sealed)/2)
+	for i := 0; i < len(sealed); i += 2 {
+		whitespaces = append(whitespaces, wscodec.SentenceFromBytes(sealed[i], sealed[i+1]).Whitespace())
+	}
+
+	return whitespaceResult(domain.CommandTypeEncryptedDecimalToWhitespace, whitespaces), nil
+}
+
+func (u *WhitespaceUsecase) encryptedWhitespaceToDecimal(payload []string) (WhitespaceResult, error) {
+	if len(u.envelope.AESKey) == 0 {
+		return WhitespaceResult{}, ErrEnvelopeKeyNotConfigured
+	}
+
+	sealed := make([]byte, 0, len(payload)*2)
+	for _, sentence := range payload {
+		parsed, err := parseWhitespaceFunc(sentence)
+		if err != nil {
+			return WhitespaceResult{}, err
+		}
+		b := parsed.Bytes()
+		sealed = append(sealed, b[:]...)
+	}
+
+	plaintext, err := openEnvelope(u.envelope, sealed)
+	if err != nil {
+		return WhitespaceResult{}, err
+	}
+
+	decimals := make([]string, 0, len(plaintext)/2)
+	for i := 0; i+1 < len(plaintext); i += 2 {
+		decimals = append(decimals, wscodec.SentenceFromBytes(plaintext[i], plaintext[i+1]).Decimal())
+	}
+
+	return WhitespaceResult{
+		CommandType:    domain.CommandTypeEncryptedWhitespaceToDecimal,
+		ResultKind:     domain.ResultKindDecimalSequence,
+		ResultDecimals: decimals,
+	}, nil
+}
+
+// sealEnvelope は平文を AES-GCM で暗号化し、必要に応じて Ed25519 で署名したエンベロープを返す。
+// ヘッダは追加認証データとして暗号文に結び付けるため、フラグの書き換えも検知できる。
+func sealEnvelope(keys EnvelopeKeys, plaintext []byte) ([]byte, error) {
+	aead, err := newEnvelopeAEAD(keys.AESKey)
+	if err != nil {
+		return nil, err
+	}
+
+	header := []byte{envelopeVersion, 0}
+	if keys.SigningKey != nil {
+		header[1] |= envelopeFlagSigned
+	}
+
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := io.ReadFull(randReader, nonce); err != nil {
+		return nil, fmt.Errorf("failed to generate nonce: %w", err)
+	}
+
+	envelope := append(header, nonce...)
+	envelope = aead.Seal(envelope, nonce, plaintext, header)
+
+	if keys.SigningKey != nil {
+		envelope = append(envelope, ed25519.Sign(keys.SigningKey, envelope)...)
+	}
+
+	return envelope, nil
+}
+
+// openEnvelope はエンベロープの署名を検証したうえで復号し、平文を返す。
+func openEnvelope(keys EnvelopeKeys, envelope []byte) ([]byte, error) {
+	aead, err := newEnvelopeAEAD(keys.AESKey)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(envelope) < envelopeHeaderSize+aead.NonceSize()+aead.Overhead() {
+		return nil, fmt.Errorf("%w: envelope is too short", ErrEnvelopeVerificationFailed)
+	}
+	if envelope[0] != envelopeVersion {
+		return nil, fmt.Errorf("%w: unsupported envelope version %d", ErrEnvelopeVerificationFailed, envelope[0])
+	}
+
+	signed := envelope[1]&envelopeFlagSigned != 0
+	switch {
+	case keys.SigningKey != nil && !signed:
+		return nil, fmt.Errorf("%w: envelope is not signed", ErrEnvelopeVerificationFailed)
+	case signed:
+		if keys.SigningKey == nil {
+			return nil, fmt.Errorf("%w: signing key is not configured", ErrEnvelopeKeyNotConfigured)
+		}
+		if len(envelope) < envelopeHeaderSize+aead.NonceSize()+aead.Overhead()+ed25519.SignatureSize {
+			return nil, fmt.Errorf("%w: envelope is too short", ErrEnvelopeVerificationFailed)
+		}
+		body := envelope[:len(envelope)-ed25519.SignatureSize]
+		signature := envelope[len(body):]
+		publicKey := keys.SigningKey.Public().(ed25519.PublicKey)
+		if !ed25519.Verify(publicKey, body, signature) {
+			return nil, fmt.Errorf("%w: invalid signature", ErrEnvelopeVerificationFailed)
+		}
+		envelope = body
+	}
+
+	header := envelope[:envelopeHeaderSize]
+	nonce := envelope[envelopeHeaderSize : envelopeHeaderSize+aead.NonceSize()]
+	plaintext, err := aead.Open(nil, nonce, envelope[envelopeHeaderSize+aead.NonceSize():], header)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrEnvelopeVerificationFailed, err)
+	}
+
+	return plaintext, nil
+}
+
+func newEnvelopeAEAD(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrEnvelopeKeyNotConfigured, err)
+	}
+	return cipher.NewGCM(block)
+}
@@ -0,0 +1,133 @@
+package app
+
+import (
+	"bytes"
+	"context"
+	"crypto/ed25519"
+	"errors"
+	"strings"
+	"testing"
+)
+
+var (
+	testAESKey     = bytes.Repeat([]byte{0x42}, 32)
+	testSigningKey = ed25519.NewKeyFromSeed(bytes.Repeat([]byte{0x07}, ed25519.SeedSize))
+)
+
+func encryptDecimals(t *testing.T, usecase *WhitespaceUsecase, decimals []string) []string {
+	t.Helper()
+
+	result, err := usecase.Execute(context.Background(), WhitespaceCommand{
+		CommandType: "EncryptedDecimalToWhitespace",
+		Payload:     decimals,
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	return result.ResultWhitespace
+}
+
+func TestEncryptedEnvelopeRoundTrip(t *testing.T) {
+	cases := map[string]EnvelopeKeys{
+		"unsigned": {AESKey: testAESKey},
+		"signed":   {AESKey: testAESKey, SigningKey: testSigningKey},
+	}
+
+	for name, keys := range cases {
+		t.Run(name, func(t *testing.T) {
+			usecase := NewWhitespaceUsecase(WithEnvelopeKeys(keys))
+			decimals := []string{"11 6 210", "0 0 0", "15 15 255"}
+
+			sealed := encryptDecimals(t, usecase, decimals)
+			overhead := (envelopeHeaderSize + 12 + 16) / 2
+			if keys.SigningKey != nil {
+				overhead += ed25519.SignatureSize / 2
+			}
+			if len(sealed) != len(decimals)+overhead {
+				t.Fatalf("sentence count = %d, want %d", len(sealed), len(decimals)+overhead)
+			}
+
+			result, err := usecase.Execute(context.Background(), WhitespaceCommand{
+				CommandType: "EncryptedWhitespaceToDecimal",
+				Payload:     sealed,
+			})
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if strings.Join(result.ResultDecimals, ",") != strings.Join(decimals, ",") {
+				t.Fatalf("decimals = %v, want %v", result.ResultDecimals, decimals)
+			}
+		})
+	}
+}
+
+func TestEncryptedEnvelopeDetectsTampering(t *testing.T) {
+	usecase := NewWhitespaceUsecase(WithEnvelopeKeys(EnvelopeKeys{AESKey: testAESKey, SigningKey: testSigningKey}))
+	sealed := encryptDecimals(t, usecase, []string{"1 2 3"})
+
+	// 暗号文部分の 1 文の最終ビットを反転させる。
+	tampered := make([]string, len(sealed))
+	copy(tampered, sealed)
+	target := []byte(tampered[8])
+	if target[len(target)-2] == ' ' {
+		target[len(target)-2] = '\t'
+	} else {
+		target[len(target)-2] = ' '
+	}
+	tampered[8] = string(target)
+
+	_, err := usecase.Execute(context.Background(), WhitespaceCommand{
+		CommandType: "EncryptedWhitespaceToDecimal",
+		Payload:     tampered,
+	})
+	if !errors.Is(err, ErrEnvelopeVerificationFailed) {
+		t.Fatalf("expected ErrEnvelopeVerificationFailed, got %v", err)
+	}
+}
+
+func TestEncryptedEnvelopeWrongKey(t *testing.T) {
+	sealed := encryptDecimals(t, NewWhitespaceUsecase(WithEnvelopeKeys(EnvelopeKeys{AESKey: testAESKey})), []string{"1 2 3"})
+
+	other := NewWhitespaceUsecase(WithEnvelopeKeys(EnvelopeKeys{AESKey: bytes.Repeat([]byte{0x01}, 32)}))
+	_, err := other.Execute(context.Background(), WhitespaceCommand{
+		CommandType: "EncryptedWhitespaceToDecimal",
+		Payload:     sealed,
+	})
+	if !errors.Is(err, ErrEnvelopeVerificationFailed) {
+		t.Fatalf("expected ErrEnvelopeVerificationFailed, got %v", err)
+	}
+}
+
+func TestEncryptedEnvelopeRequiresSignature(t *testing.T) {
+	sealed := encryptDecimals(t, NewWhitespaceUsecase(WithEnvelopeKeys(EnvelopeKeys{AESKey: testAESKey})), []string{"1 2 3"})
+
+	verifier := NewWhitespaceUsecase(WithEnvelopeKeys(EnvelopeKeys{AESKey: testAESKey, SigningKey: testSigningKey}))
+	_, err := verifier.Execute(context.Background(), WhitespaceCommand{
+		CommandType: "EncryptedWhitespaceToDecimal",
+		Payload:     sealed,
+	})
+	if !errors.Is(err, ErrEnvelopeVerificationFailed) {
+		t.Fatalf("expected ErrEnvelopeVerificationFailed, got %v", err)
+	}
+}
+
+func TestEncryptedEnvelopeKeyNotConfigured(t *testing.T) {
+	usecase := NewWhitespaceUsecase()
+
+	for _, commandType := range []string{"EncryptedDecimalToWhitespace", "EncryptedWhitespaceToDecimal"} {
+		_, err := usecase.Execute(context.Background(), WhitespaceCommand{
+			CommandType: commandType,
+			Payload:     []string{"0 0 0"},
+		})
+		if !errors.Is(err, ErrEnvelopeKeyNotConfigured) {
+			t.Fatalf("%s: expected ErrEnvelopeKeyNotConfigured, got %v", commandType, err)
+		}
+	}
+}
+
+func TestOpenEnvelopeTooShort(t *testing.T) {
+	if _, err := openEnvelope(EnvelopeKeys{AESKey: testAESKey}, []byte{envelopeVersion, 0}); !errors.Is(err, ErrEnvelopeVerificationFailed) {
+		t.Fatalf("expected ErrEnvelopeVerificationFailed, got %v", err)
+	}
+}
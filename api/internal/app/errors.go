@@ -5,4 +5,10 @@ import "errors"
 var (
 	// ErrValidationFailed は入力値の検証に失敗した場合に返される共通エラー。
 	ErrValidationFailed = errors.New("app: validation failed")
+
+	// ErrEnvelopeKeyNotConfigured は暗号化エンベロープ用の鍵が設定されていない場合に返される。
+	ErrEnvelopeKeyNotConfigured = errors.New("app: envelope key not configured")
+
+	// ErrEnvelopeVerificationFailed はエンベロープの改ざん・鍵の不一致・署名不正を検知した場合に返される。
+	ErrEnvelopeVerificationFailed = errors.New("app: envelope verification failed")
 )
@@ -0,0 +1,74 @@
+package app
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/2509-hackz-ichthyo/main/api/internal/domain"
+)
+
+// NormalizePayload はクライアントから受け取ったペイロードを命令の入力表現に応じて正規化する。
+// HTTP API と WASM の両方から呼び出し、同じ入力を同じ形でユースケースへ渡す。
+func NormalizePayload(commandType string, values []string) ([]string, error) {
+	ct, err := domain.ParseCommandType(commandType)
+	if err != nil {
+		return nil, err
+	}
+
+	format, _ := ct.SourceFormat()
+	normalized := make([]string, len(values))
+	for i, value := range values {
+		normalized[i], err = NormalizeValue(format, value)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if len(normalized) == 0 {
+		return nil, fmt.Errorf("%w: payload must not be empty", ErrValidationFailed)
+	}
+
+	return normalized, nil
+}
+
+// NormalizeValue はペイロードの表現に応じて 1 文分の値を正規化する。
+// Whitespace はパーセントエンコードを解除し、数列は前後の空白を取り除く。
+func NormalizeValue(format domain.PayloadFormat, value string) (string, error) {
+	switch format {
+	case domain.PayloadFormatWhitespace:
+		decoded, err := url.PathUnescape(value)
+		if err != nil {
+			return "", fmt.Errorf("%w: failed to decode percent-encoded payload", domain.ErrInvalidPayload)
+		}
+		return decoded, nil
+	case domain.PayloadFormatDecimal, domain.PayloadFormatBinary:
+		return strings.TrimSpace(value), nil
+	default:
+		return value, nil
+	}
+}
+
+// ErrorMessage はユースケース層のエラーに対応する利用者向けのメッセージを返す。
+// HTTP API のエラーレスポンスと WASM の戻り値で同じ文言を使う。
+func ErrorMessage(err error) string {
+	switch {
+	case errors.Is(err, ErrValidationFailed):
+		return "入力値が不正です"
+	case errors.Is(err, domain.ErrInvalidPayload):
+		return "ペイロードが不正です"
+	case errors.Is(err, domain.ErrInvalidCommandType):
+		return "サポートされていない命令種別です"
+	case errors.Is(err, ErrEnvelopeVerificationFailed):
+		return "暗号化エンベロープの検証に失敗しました"
+	case errors.Is(err, ErrEnvelopeKeyNotConfigured):
+		return "暗号化エンベロープの鍵が設定されていません"
+	case errors.Is(err, domain.ErrInvalidPayloadFormat):
+		return "サポートされていないペイロード形式です"
+	case errors.Is(err, domain.ErrTypeMismatch):
+		return "命令と処理が一致しません"
+	default:
+		return "内部エラーが発生しました"
+	}
+}
@@ -0,0 +1,68 @@
+package app
+
+import (
+	"testing"
+
+	"github.com/2509-hackz-ichthyo/main/api/internal/domain"
+)
+
+func TestNormalizePayload(t *testing.T) {
+	t.Run("whitespace unescape", func(t *testing.T) {
+		values, err := NormalizePayload("WhitespaceToDecimal", []string{"%20%09"})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if values[0] != " \t" {
+			t.Fatalf("value = %q, want \" \\t\"", values[0])
+		}
+	})
+
+	t.Run("decimal trim", func(t *testing.T) {
+		values, err := NormalizePayload("DecimalToWhitespace", []string{"  1 2 3  "})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if values[0] != "1 2 3" {
+			t.Fatalf("value = %q, want %q", values[0], "1 2 3")
+		}
+	})
+
+	t.Run("binary trim", func(t *testing.T) {
+		values, err := NormalizePayload("BinariesToWhitespace", []string{" 0101 "})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if values[0] != "0101" {
+			t.Fatalf("value = %q, want %q", values[0], "0101")
+		}
+	})
+
+	t.Run("empty payload", func(t *testing.T) {
+		if _, err := NormalizePayload("WhitespaceToBinary", nil); err == nil {
+			t.Fatalf("expected error but got nil")
+		}
+	})
+
+	t.Run("invalid command", func(t *testing.T) {
+		if _, err := NormalizePayload("Unknown", []string{""}); err == nil {
+			t.Fatalf("expected error but got nil")
+		}
+	})
+
+	t.Run("invalid unescape", func(t *testing.T) {
+		if _, err := NormalizePayload("WhitespaceToBinary", []string{"%ZZ"}); err == nil {
+			t.Fatalf("expected error but got nil")
+		}
+	})
+
+	t.Run("default branch", func(t *testing.T) {
+		value, err := NormalizeValue(domain.PayloadFormat("Custom"), " keep ")
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		if value != " keep " {
+			t.Fatalf("value = %q, want %q", value, " keep ")
+		}
+	})
+}
@@ -7,6 +7,7 @@ import (
 	"testing"
 
 	"github.com/2509-hackz-ichthyo/main/api/internal/domain"
+	"github.com/2509-hackz-ichthyo/main/api/pkg/wscodec"
 )
 
 func TestWhitespaceUsecaseInvalidCommandType(t *testing.T) {
@@ -99,124 +100,41 @@ func TestWhitespaceUsecaseBinariesToWhitespaceInvalidBinary(t *testing.T) {
 	}
 }
 
-func TestDecimalToWhitespaceBitsToWhitespaceError(t *testing.T) {
-	original := bitsToWhitespaceFunc
-	bitsToWhitespaceFunc = func(string) (string, error) {
-		return "", fmt.Errorf("forced error")
+func TestDecimalToWhitespaceParseError(t *testing.T) {
+	original := parseDecimalFunc
+	parseDecimalFunc = func(string) (wscodec.Sentence, error) {
+		return wscodec.Sentence{}, fmt.Errorf("forced error")
 	}
-	defer func() { bitsToWhitespaceFunc = original }()
+	defer func() { parseDecimalFunc = original }()
 
-	_, err := (WhitespaceUsecase{}).decimalToWhitespace([]string{"0 0 0"})
+	_, err := (WhitespaceUsecase{}).decimalToWhitespace(context.Background(), []string{"0 0 0"})
 	if err == nil || err.Error() != "forced error" {
 		t.Fatalf("expected forced error, got %v", err)
 	}
 }
 
-func TestBinaryToWhitespaceBitsToWhitespaceError(t *testing.T) {
-	originalBits := bitsToWhitespaceFunc
-	bitsToWhitespaceFunc = func(string) (string, error) {
-		return "", fmt.Errorf("bits error")
+func TestBinaryToWhitespaceParseError(t *testing.T) {
+	original := parseBinaryFunc
+	parseBinaryFunc = func(string) (wscodec.Sentence, error) {
+		return wscodec.Sentence{}, fmt.Errorf("bits error")
 	}
-	originalNormalize := normalizeBinaryStringFunc
-	normalizeBinaryStringFunc = func(input string) (string, error) {
-		return "0000000000000000", nil
-	}
-	defer func() {
-		bitsToWhitespaceFunc = originalBits
-		normalizeBinaryStringFunc = originalNormalize
-	}()
+	defer func() { parseBinaryFunc = original }()
 
-	_, err := (WhitespaceUsecase{}).binaryToWhitespace([]string{"0000000000000000"})
+	_, err := (WhitespaceUsecase{}).binaryToWhitespace(context.Background(), []string{"0000000000000000"})
 	if err == nil || err.Error() != "bits error" {
 		t.Fatalf("expected bits error, got %v", err)
 	}
 }
 
-func TestDecimalStringToBinaryErrors(t *testing.T) {
-	cases := []struct {
-		name  string
-		input string
-	}{
-		{name: "too few tokens", input: "1 2"},
-		{name: "non integer", input: "a b c"},
-		{name: "negative", input: "-1 0 0"},
-		{name: "out of range", input: "16 0 0"},
-	}
-
-	for _, tc := range cases {
-		tc := tc
-		t.Run(tc.name, func(t *testing.T) {
-			if _, err := decimalStringToBinary(tc.input); err == nil || !errors.Is(err, domain.ErrInvalidPayload) {
-				t.Fatalf("expected domain.ErrInvalidPayload, got %v", err)
-			}
-		})
-	}
-}
-
-func TestNormalizeBinaryStringErrors(t *testing.T) {
-	cases := map[string]string{
-		"blank":          "",
-		"invalid length": "1010",
-		"invalid rune":   "0000000000000002",
-	}
-
-	for name, input := range cases {
-		input := input
-		t.Run(name, func(t *testing.T) {
-			if _, err := normalizeBinaryString(input); err == nil || !errors.Is(err, domain.ErrInvalidPayload) {
-				t.Fatalf("expected domain.ErrInvalidPayload, got %v", err)
-			}
-		})
-	}
-}
-
-func TestBitsToWhitespaceErrors(t *testing.T) {
-	cases := map[string]string{
-		"invalid length": "1010",
-		"invalid rune":   "0000000000000002",
-	}
-	for name, input := range cases {
-		input := input
-		t.Run(name, func(t *testing.T) {
-			if _, err := bitsToWhitespace(input); err == nil || !errors.Is(err, domain.ErrInvalidPayload) {
-				t.Fatalf("expected domain.ErrInvalidPayload, got %v", err)
-			}
-		})
-	}
-}
-
-func TestExtractSegmentsErrors(t *testing.T) {
-	cases := map[string]string{
-		"blank":              "",
-		"missing prefix":     "abc",
-		"insufficient lines": "   abcd\n   abcd",
-		"invalid length":     "   abcd\n   abcd\n   abcdefg",
-	}
-
-	for name, sentence := range cases {
-		t.Run(name, func(t *testing.T) {
-			if _, err := extractSegments(sentence); err == nil || !errors.Is(err, domain.ErrInvalidPayload) {
-				t.Fatalf("expected domain.ErrInvalidPayload, got %v", err)
-			}
-		})
-	}
-}
-
-func TestParseWhitespaceSentenceUnsupportedRune(t *testing.T) {
-	sentence := "   abcd\n   abcd\n   abcdefgh"
-	if _, err := parseWhitespaceSentence(sentence); err == nil || !errors.Is(err, domain.ErrInvalidPayload) {
-		t.Fatalf("expected domain.ErrInvalidPayload, got %v", err)
-	}
-}
-
-func TestParseWhitespaceSentenceInvalidBinary(t *testing.T) {
-	original := extractSegmentsFunc
-	extractSegmentsFunc = func(string) ([]string, error) {
-		return []string{"", "", ""}, nil
-	}
-	defer func() { extractSegmentsFunc = original }()
+func TestWhitespaceUsecaseHonorsContext(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
 
-	if _, err := parseWhitespaceSentence("dummy"); err == nil || !errors.Is(err, domain.ErrInvalidPayload) {
-		t.Fatalf("expected domain.ErrInvalidPayload, got %v", err)
+	_, err := NewWhitespaceUsecase().Execute(ctx, WhitespaceCommand{
+		CommandType: string(domain.CommandTypeDecimalToWhitespace),
+		Payload:     []string{"1 2 3"},
+	})
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected context.Canceled, got %v", err)
 	}
 }
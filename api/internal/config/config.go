@@ -1,23 +1,322 @@
 package config
 
-import "os"
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"time"
+)
 
 // Config はアプリケーション全体で共有する設定値を保持する。
 type Config struct {
 	// ServerPort は HTTP サーバがバインドするポート番号。
 	ServerPort string
+
+	// GRPCPort は gRPC サーバがバインドするポート番号。空の場合は gRPC サーバを起動しない。
+	GRPCPort string
+
+	// LogLevel はログの出力レベル（debug / info / warn / error）。
+	LogLevel string
+
+	// TLSCertFile / TLSKeyFile は HTTPS で待ち受ける場合の証明書と秘密鍵のパス。
+	// 両方を指定した場合のみ HTTPS（HTTP/2 対応）で起動する。
+	TLSCertFile string
+	TLSKeyFile  string
+
+	// ReadTimeout / ReadHeaderTimeout / WriteTimeout / IdleTimeout は http.Server に設定するタイムアウト。
+	ReadTimeout       time.Duration
+	ReadHeaderTimeout time.Duration
+	WriteTimeout      time.Duration
+	IdleTimeout       time.Duration
+
+	// ShutdownDrainDelay はシャットダウンシグナル受信後、GET /readyz を 503 にしてから
+	// 新規接続の受け付けを止めるまでの待ち時間。ロードバランサが振り分け対象から外すのを待つ。
+	ShutdownDrainDelay time.Duration
+
+	// ShutdownGracePeriod は新規接続の受け付けを止めた後、処理中のリクエストの完了を待つ最大時間。
+	ShutdownGracePeriod time.Duration
+
+	// MetricsEnabled が true の場合、GET /metrics で Prometheus メトリクスを公開する。
+	MetricsEnabled bool
+
+	// TraceExporter は OpenTelemetry のスパンの出力先（none / stdout / otlp）。
+	TraceExporter string
+
+	// APIKeys は /v1 配下の呼び出しを許可する API キーの一覧。空の場合は認証を行わない。
+	APIKeys []APIKey
+
+	// KeyRateLimit / KeyRateBurst は API キー単位のレート制限（毎秒のリクエスト数・バースト）の既定値。
+	KeyRateLimit float64
+	KeyRateBurst int
+
+	// IPRateLimit / IPRateBurst は接続元 IP 単位のレート制限。IPRateLimit が 0 の場合は制限しない。
+	IPRateLimit float64
+	IPRateBurst int
+
+	// TrustedProxies は X-Forwarded-For から接続元 IP を取り出してよいプロキシの IP / CIDR の一覧。
+	// 空の場合はヘッダを無視し、TCP 接続の相手を接続元 IP とする（NLB はヘッダを付与しない）。
+	TrustedProxies []string
+
+	// CORSAllowedOrigins はクロスオリジン呼び出しを許可するオリジンの一覧。"*" は任意のオリジンを許可する。
+	// 空の場合は CORS ヘッダを付与しない。
+	CORSAllowedOrigins []string
+
+	// CORSAllowedMethods / CORSAllowedHeaders はプリフライトで許可するメソッドとリクエストヘッダ。
+	CORSAllowedMethods []string
+	CORSAllowedHeaders []string
+
+	// CORSMaxAge はプリフライト結果をブラウザがキャッシュする期間。
+	CORSMaxAge time.Duration
+
+	// EnvelopeKey は暗号化エンベロープの AES-GCM 鍵（16/24/32 バイト）。
+	// 未設定の場合、暗号化エンベロープ命令は利用できない。
+	EnvelopeKey []byte
+
+	// EnvelopeSigningSeed は暗号化エンベロープに署名する Ed25519 鍵のシード（32 バイト）。
+	// 未設定の場合、エンベロープは署名されない。
+	EnvelopeSigningSeed []byte
+
+	// JobWorkers は非同期変換ジョブを同時に実行する数。0 の場合は /v1/jobs を公開しない。
+	JobWorkers int
+
+	// JobQueueSize は実行を待つジョブの最大数。超えた場合は 503 を返す。
+	JobQueueSize int
+
+	// JobTTL は完了したジョブの結果を保持する期間。
+	JobTTL time.Duration
+
+	// 以下は読み込み途中でのみ使う値で、Load の最後にファイルの内容を解決してから破棄する。
+	apiKeyFile         string
+	apiKeysJSON        string
+	envelopeKeyFile    string
+	envelopeKeyRaw     string
+	envelopeSigningRaw string
 }
 
-const (
-	envServerPort = "SERVER_PORT"
-)
+// TLSEnabled は HTTPS で待ち受ける設定になっているかを返す。
+func (c Config) TLSEnabled() bool {
+	return c.TLSCertFile != "" && c.TLSKeyFile != ""
+}
 
-// Load は環境変数から設定値を読み込む。指定が無い場合はデフォルト値を用いる。
-func Load() (Config, error) {
-	port := os.Getenv(envServerPort)
-	if port == "" {
-		port = "3000"
+// APIKey は API キーとその所有クライアント、キー固有のレート制限を表す。
+// Rate / Burst が 0 の場合は Config の既定値を用いる。
+type APIKey struct {
+	Client string  `json:"client" yaml:"client" toml:"client"`
+	Key    string  `json:"key" yaml:"key" toml:"key"`
+	Rate   float64 `json:"rate" yaml:"rate" toml:"rate"`
+	Burst  int     `json:"burst" yaml:"burst" toml:"burst"`
+}
+
+// Error は設定値の検証に失敗したキーと、その値の出どころを表す。
+type Error struct {
+	Key    string // 設定ファイル上のキー名（例: read_timeout）
+	Source string // 値の出どころ（例: env READ_TIMEOUT / flag --read-timeout / file config.yaml）
+	Err    error
+}
+
+func (e *Error) Error() string {
+	if e.Source == "" {
+		return fmt.Sprintf("config %s: %v", e.Key, e.Err)
 	}
+	return fmt.Sprintf("config %s (%s): %v", e.Key, e.Source, e.Err)
+}
 
-	return Config{ServerPort: port}, nil
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+const envelopeSigningSeedSize = 32
+
+// envelopeKeyFile は envelope_key_file が指す JSON ファイルの形式。値はいずれも base64 で記述する。
+type envelopeKeyFile struct {
+	Key        string `json:"key"`
+	SigningKey string `json:"signing_key"`
+}
+
+func defaults() Config {
+	return Config{
+		ServerPort:          "3000",
+		GRPCPort:            "50051",
+		LogLevel:            "info",
+		ReadTimeout:         15 * time.Second,
+		ReadHeaderTimeout:   5 * time.Second,
+		WriteTimeout:        30 * time.Second,
+		IdleTimeout:         120 * time.Second,
+		ShutdownDrainDelay:  5 * time.Second,
+		ShutdownGracePeriod: 10 * time.Second,
+		MetricsEnabled:      true,
+		TraceExporter:       "none",
+		KeyRateLimit:        10,
+		KeyRateBurst:        20,
+		IPRateLimit:         20,
+		IPRateBurst:         40,
+		CORSAllowedMethods:  []string{"GET", "POST", "OPTIONS"},
+		CORSAllowedHeaders:  []string{"Content-Type", "Authorization", "X-API-Key", "X-Request-ID"},
+		CORSMaxAge:          10 * time.Minute,
+		JobWorkers:          4,
+		JobQueueSize:        100,
+		JobTTL:              time.Hour,
+	}
+}
+
+// Load は設定ファイル、環境変数、コマンドライン引数の順に設定値を重ね合わせて読み込む。
+// 後から読み込んだ値ほど優先され、いずれにも指定が無い場合はデフォルト値を用いる。
+// 設定ファイルは --config または CONFIG_FILE で指定し、拡張子（.yaml / .yml / .toml）で形式を判別する。
+func Load(args []string) (Config, error) {
+	flagValues, configPath, err := parseFlags(args)
+	if err != nil {
+		return Config{}, err
+	}
+	if configPath == "" {
+		configPath = os.Getenv(envConfigFile)
+	}
+
+	values := map[string]sourcedValue{}
+	var fileKeys []APIKey
+	if configPath != "" {
+		fileValues, keys, err := readConfigFile(configPath)
+		if err != nil {
+			return Config{}, err
+		}
+		merge(values, fileValues)
+		fileKeys = keys
+	}
+	merge(values, envValues())
+	merge(values, flagValues)
+
+	cfg := defaults()
+	cfg.APIKeys = fileKeys
+	for _, s := range settings {
+		v, ok := values[s.key]
+		if !ok {
+			continue
+		}
+		if err := s.apply(&cfg, v.raw); err != nil {
+			return Config{}, &Error{Key: s.key, Source: v.source, Err: err}
+		}
+	}
+
+	if err := resolveAPIKeys(&cfg); err != nil {
+		return Config{}, err
+	}
+	if err := resolveEnvelopeKeys(&cfg); err != nil {
+		return Config{}, err
+	}
+	if err := validate(cfg); err != nil {
+		return Config{}, err
+	}
+
+	cfg.apiKeyFile, cfg.apiKeysJSON, cfg.envelopeKeyFile, cfg.envelopeKeyRaw, cfg.envelopeSigningRaw = "", "", "", "", ""
+	return cfg, nil
+}
+
+// resolveAPIKeys は api_key_file と api_keys_json（いずれも JSON 配列）の内容を追加し、API キー全体を検証する。
+func resolveAPIKeys(cfg *Config) error {
+	if cfg.apiKeyFile != "" {
+		data, err := os.ReadFile(cfg.apiKeyFile)
+		if err != nil {
+			return &Error{Key: "api_key_file", Err: err}
+		}
+		var keys []APIKey
+		if err := json.Unmarshal(data, &keys); err != nil {
+			return &Error{Key: "api_key_file", Err: err}
+		}
+		cfg.APIKeys = append(keys, cfg.APIKeys...)
+	}
+	if cfg.apiKeysJSON != "" {
+		var keys []APIKey
+		if err := json.Unmarshal([]byte(cfg.apiKeysJSON), &keys); err != nil {
+			return &Error{Key: "api_keys_json", Err: err}
+		}
+		cfg.APIKeys = append(cfg.APIKeys, keys...)
+	}
+
+	seen := make(map[string]struct{}, len(cfg.APIKeys))
+	for i, key := range cfg.APIKeys {
+		if key.Client == "" || key.Key == "" {
+			return &Error{Key: "api_keys", Err: fmt.Errorf("entry %d: client and key must not be empty", i)}
+		}
+		if key.Rate < 0 || key.Burst < 0 {
+			return &Error{Key: "api_keys", Err: fmt.Errorf("client %q: rate and burst must not be negative", key.Client)}
+		}
+		if _, dup := seen[key.Key]; dup {
+			return &Error{Key: "api_keys", Err: fmt.Errorf("client %q: duplicated key", key.Client)}
+		}
+		seen[key.Key] = struct{}{}
+	}
+	return nil
+}
+
+// resolveEnvelopeKeys はエンベロープ鍵を復号して検証する。
+// 鍵は envelope_key_file の内容より envelope_key / envelope_signing_key の値を優先する。
+func resolveEnvelopeKeys(cfg *Config) error {
+	var file envelopeKeyFile
+	if cfg.envelopeKeyFile != "" {
+		data, err := os.ReadFile(cfg.envelopeKeyFile)
+		if err != nil {
+			return &Error{Key: "envelope_key_file", Err: err}
+		}
+		if err := json.Unmarshal(data, &file); err != nil {
+			return &Error{Key: "envelope_key_file", Err: err}
+		}
+	}
+
+	key, err := decodeKey(firstNonEmpty(cfg.envelopeKeyRaw, file.Key))
+	if err != nil {
+		return &Error{Key: "envelope_key", Err: err}
+	}
+	switch len(key) {
+	case 0, 16, 24, 32:
+	default:
+		return &Error{Key: "envelope_key", Err: fmt.Errorf("AES key must be 16, 24 or 32 bytes, got %d", len(key))}
+	}
+
+	seed, err := decodeKey(firstNonEmpty(cfg.envelopeSigningRaw, file.SigningKey))
+	if err != nil {
+		return &Error{Key: "envelope_signing_key", Err: err}
+	}
+	if len(seed) != 0 && len(seed) != envelopeSigningSeedSize {
+		return &Error{Key: "envelope_signing_key", Err: fmt.Errorf("Ed25519 seed must be %d bytes, got %d", envelopeSigningSeedSize, len(seed))}
+	}
+	if len(seed) != 0 && len(key) == 0 {
+		return &Error{Key: "envelope_signing_key", Err: errors.New("signing key requires envelope_key")}
+	}
+
+	cfg.EnvelopeKey = key
+	cfg.EnvelopeSigningSeed = seed
+	return nil
+}
+
+// validate は複数の設定値にまたがる整合性を検証する。
+func validate(cfg Config) error {
+	if (cfg.TLSCertFile == "") != (cfg.TLSKeyFile == "") {
+		key := "tls_key_file"
+		if cfg.TLSCertFile == "" {
+			key = "tls_cert_file"
+		}
+		return &Error{Key: key, Err: errors.New("tls_cert_file and tls_key_file must be set together")}
+	}
+	return nil
+}
+
+func decodeKey(value string) ([]byte, error) {
+	if value == "" {
+		return nil, nil
+	}
+	key, err := base64.StdEncoding.DecodeString(value)
+	if err != nil {
+		return nil, fmt.Errorf("invalid base64: %w", err)
+	}
+	return key, nil
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, v := range values {
+		if v != "" {
+			return v
+		}
+	}
+	return ""
 }
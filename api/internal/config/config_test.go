@@ -1,13 +1,20 @@
 package config
 
-import "testing"
+import (
+	"bytes"
+	"encoding/base64"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
 
 func TestLoadWithEnv(t *testing.T) {
 	t.Setenv(envServerPort, "8081")
 
-	cfg, err := Load()
+	cfg, err := Load(nil)
 	if err != nil {
-		t.Fatalf("Load() error = %v", err)
+		t.Fatalf("Load(nil) error = %v", err)
 	}
 
 	if cfg.ServerPort != "8081" {
@@ -17,13 +24,446 @@ func TestLoadWithEnv(t *testing.T) {
 
 func TestLoadDefault(t *testing.T) {
 	t.Setenv(envServerPort, "")
+	t.Setenv(envMetricsEnabled, "")
+	t.Setenv(envTraceExporter, "")
 
-	cfg, err := Load()
+	cfg, err := Load(nil)
 	if err != nil {
-		t.Fatalf("Load() error = %v", err)
+		t.Fatalf("Load(nil) error = %v", err)
 	}
 
 	if cfg.ServerPort != "3000" {
 		t.Fatalf("ServerPort = %q, want %q", cfg.ServerPort, "3000")
 	}
+
+	if !cfg.MetricsEnabled {
+		t.Fatalf("MetricsEnabled = false, want true")
+	}
+
+	if cfg.TraceExporter != "none" {
+		t.Fatalf("TraceExporter = %q, want %q", cfg.TraceExporter, "none")
+	}
+}
+
+func TestLoadGRPCPortEmptyDisables(t *testing.T) {
+	t.Setenv(envGRPCPort, "")
+
+	cfg, err := Load(nil)
+	if err != nil {
+		t.Fatalf("Load(nil) error = %v", err)
+	}
+	if cfg.GRPCPort != "" {
+		t.Fatalf("GRPCPort = %q, want empty", cfg.GRPCPort)
+	}
+
+	// 空でない値はこれまでどおり検証する
+	t.Setenv(envGRPCPort, "grpc")
+	if _, err := Load(nil); err == nil {
+		t.Fatal("expected error for non-numeric gRPC port")
+	}
+}
+
+func TestLoadTraceExporter(t *testing.T) {
+	t.Setenv(envTraceExporter, "otlp")
+
+	cfg, err := Load(nil)
+	if err != nil {
+		t.Fatalf("Load(nil) error = %v", err)
+	}
+
+	if cfg.TraceExporter != "otlp" {
+		t.Fatalf("TraceExporter = %q, want %q", cfg.TraceExporter, "otlp")
+	}
+
+	t.Setenv(envTraceExporter, "zipkin")
+
+	if _, err := Load(nil); err == nil {
+		t.Fatal("expected error but got nil")
+	}
+}
+
+func TestLoadMetricsEnabled(t *testing.T) {
+	t.Setenv(envMetricsEnabled, "false")
+
+	cfg, err := Load(nil)
+	if err != nil {
+		t.Fatalf("Load(nil) error = %v", err)
+	}
+
+	if cfg.MetricsEnabled {
+		t.Fatalf("MetricsEnabled = true, want false")
+	}
+
+	t.Setenv(envMetricsEnabled, "maybe")
+
+	if _, err := Load(nil); err == nil {
+		t.Fatal("expected error but got nil")
+	}
+}
+
+func TestLoadEnvelopeKeys(t *testing.T) {
+	t.Setenv(envEnvelopeKey, base64.StdEncoding.EncodeToString(bytes.Repeat([]byte{1}, 32)))
+	t.Setenv(envEnvelopeSigningKey, "")
+
+	path := filepath.Join(t.TempDir(), "keys.json")
+	seed := base64.StdEncoding.EncodeToString(bytes.Repeat([]byte{2}, 32))
+	if err := os.WriteFile(path, []byte(`{"key":"ignored","signing_key":"`+seed+`"}`), 0o600); err != nil {
+		t.Fatalf("failed to write key file: %v", err)
+	}
+	t.Setenv(envEnvelopeKeyFile, path)
+
+	cfg, err := Load(nil)
+	if err != nil {
+		t.Fatalf("Load(nil) error = %v", err)
+	}
+
+	if len(cfg.EnvelopeKey) != 32 || cfg.EnvelopeKey[0] != 1 {
+		t.Fatalf("EnvelopeKey = %v, want environment value", cfg.EnvelopeKey)
+	}
+	if len(cfg.EnvelopeSigningSeed) != 32 || cfg.EnvelopeSigningSeed[0] != 2 {
+		t.Fatalf("EnvelopeSigningSeed = %v, want key file value", cfg.EnvelopeSigningSeed)
+	}
+}
+
+func TestLoadEnvelopeKeysInvalid(t *testing.T) {
+	cases := map[string]map[string]string{
+		"bad base64":     {envEnvelopeKey: "***"},
+		"bad aes length": {envEnvelopeKey: base64.StdEncoding.EncodeToString([]byte("short"))},
+		"bad seed length": {
+			envEnvelopeKey:        base64.StdEncoding.EncodeToString(bytes.Repeat([]byte{1}, 16)),
+			envEnvelopeSigningKey: base64.StdEncoding.EncodeToString([]byte("short")),
+		},
+		"seed without key": {envEnvelopeSigningKey: base64.StdEncoding.EncodeToString(bytes.Repeat([]byte{2}, 32))},
+		"missing file":     {envEnvelopeKeyFile: filepath.Join(t.TempDir(), "missing.json")},
+	}
+
+	for name, env := range cases {
+		t.Run(name, func(t *testing.T) {
+			for _, key := range []string{envEnvelopeKey, envEnvelopeSigningKey, envEnvelopeKeyFile} {
+				t.Setenv(key, env[key])
+			}
+
+			if _, err := Load(nil); err == nil {
+				t.Fatal("expected error but got nil")
+			}
+		})
+	}
+}
+
+func TestLoadAPIKeys(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "api-keys.json")
+	if err := os.WriteFile(path, []byte(`[{"client":"game-lambda","key":"k1","rate":100,"burst":200}]`), 0o600); err != nil {
+		t.Fatalf("failed to write key file: %v", err)
+	}
+	t.Setenv(envAPIKeyFile, path)
+	t.Setenv(envAPIKeys, "tooling=k2")
+	t.Setenv(envAPIKeysJSON, `[{"client":"replay-lambda","key":"k3","rate":50}]`)
+	t.Setenv(envKeyRateLimit, "5")
+	t.Setenv(envIPRateLimit, "0")
+
+	cfg, err := Load(nil)
+	if err != nil {
+		t.Fatalf("Load(nil) error = %v", err)
+	}
+
+	want := []APIKey{
+		{Client: "game-lambda", Key: "k1", Rate: 100, Burst: 200},
+		{Client: "tooling", Key: "k2"},
+		{Client: "replay-lambda", Key: "k3", Rate: 50},
+	}
+	if len(cfg.APIKeys) != len(want) {
+		t.Fatalf("APIKeys = %+v, want %+v", cfg.APIKeys, want)
+	}
+	for i := range want {
+		if cfg.APIKeys[i] != want[i] {
+			t.Fatalf("APIKeys[%d] = %+v, want %+v", i, cfg.APIKeys[i], want[i])
+		}
+	}
+
+	if cfg.KeyRateLimit != 5 || cfg.KeyRateBurst != 20 || cfg.IPRateLimit != 0 || cfg.IPRateBurst != 40 {
+		t.Fatalf("unexpected rate limits: %+v", cfg)
+	}
+}
+
+func TestLoadAPIKeysInvalid(t *testing.T) {
+	cases := map[string]map[string]string{
+		"missing separator": {envAPIKeys: "tooling"},
+		"empty key":         {envAPIKeys: "tooling="},
+		"duplicated key":    {envAPIKeys: "a=k,b=k"},
+		"bad rate":          {envKeyRateLimit: "fast"},
+		"negative burst":    {envIPRateBurst: "-1"},
+		"missing file":      {envAPIKeyFile: filepath.Join(t.TempDir(), "missing.json")},
+		"malformed json":    {envAPIKeysJSON: `{"client":"a"`},
+	}
+
+	for name, env := range cases {
+		t.Run(name, func(t *testing.T) {
+			for _, key := range []string{envAPIKeys, envAPIKeyFile, envAPIKeysJSON, envKeyRateLimit, envKeyRateBurst, envIPRateLimit, envIPRateBurst} {
+				t.Setenv(key, env[key])
+			}
+
+			if _, err := Load(nil); err == nil {
+				t.Fatal("expected error but got nil")
+			}
+		})
+	}
+}
+
+func TestLoadCORS(t *testing.T) {
+	t.Setenv(envCORSAllowedOrigins, "https://example.com, http://localhost:8080")
+	t.Setenv(envCORSAllowedMethods, "")
+	t.Setenv(envCORSMaxAge, "1h")
+
+	cfg, err := Load(nil)
+	if err != nil {
+		t.Fatalf("Load(nil) error = %v", err)
+	}
+
+	if len(cfg.CORSAllowedOrigins) != 2 || cfg.CORSAllowedOrigins[1] != "http://localhost:8080" {
+		t.Fatalf("CORSAllowedOrigins = %v", cfg.CORSAllowedOrigins)
+	}
+	if len(cfg.CORSAllowedMethods) != 3 {
+		t.Fatalf("CORSAllowedMethods = %v, want defaults", cfg.CORSAllowedMethods)
+	}
+	if cfg.CORSMaxAge != time.Hour {
+		t.Fatalf("CORSMaxAge = %v, want 1h", cfg.CORSMaxAge)
+	}
+}
+
+func TestLoadTrustedProxies(t *testing.T) {
+	t.Setenv(envTrustedProxies, "10.0.0.0/8, 192.0.2.1")
+
+	cfg, err := Load(nil)
+	if err != nil {
+		t.Fatalf("Load(nil) error = %v", err)
+	}
+	if len(cfg.TrustedProxies) != 2 || cfg.TrustedProxies[1] != "192.0.2.1" {
+		t.Fatalf("TrustedProxies = %v", cfg.TrustedProxies)
+	}
+
+	t.Setenv(envTrustedProxies, "proxy.local")
+	if _, err := Load(nil); err == nil {
+		t.Fatal("expected error for proxy that is not an IP or CIDR")
+	}
+}
+
+func TestLoadCORSInvalid(t *testing.T) {
+	t.Setenv(envCORSAllowedOrigins, "example.com")
+
+	if _, err := Load(nil); err == nil {
+		t.Fatal("expected error for origin without scheme")
+	}
+
+	t.Setenv(envCORSAllowedOrigins, "")
+	t.Setenv(envCORSMaxAge, "forever")
+
+	if _, err := Load(nil); err == nil {
+		t.Fatal("expected error for invalid max age")
+	}
+}
+
+func TestLoadJobSettings(t *testing.T) {
+	t.Setenv(envJobWorkers, "0")
+	t.Setenv(envJobTTL, "10m")
+
+	cfg, err := Load(nil)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+
+	if cfg.JobWorkers != 0 || cfg.JobQueueSize != 100 || cfg.JobTTL != 10*time.Minute {
+		t.Fatalf("unexpected job settings: workers=%d queue=%d ttl=%v", cfg.JobWorkers, cfg.JobQueueSize, cfg.JobTTL)
+	}
+}
+
+func TestLoadServerSettings(t *testing.T) {
+	t.Setenv(envReadTimeout, "3s")
+	t.Setenv(envShutdownGracePeriod, "")
+	t.Setenv(envLogLevel, "DEBUG")
+
+	cfg, err := Load(nil)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+
+	if cfg.ReadTimeout != 3*time.Second || cfg.WriteTimeout != 30*time.Second {
+		t.Fatalf("unexpected timeouts: read=%v write=%v", cfg.ReadTimeout, cfg.WriteTimeout)
+	}
+	if cfg.ShutdownGracePeriod != 10*time.Second {
+		t.Fatalf("ShutdownGracePeriod = %v, want 10s", cfg.ShutdownGracePeriod)
+	}
+	if cfg.LogLevel != "debug" {
+		t.Fatalf("LogLevel = %q, want %q", cfg.LogLevel, "debug")
+	}
+	if cfg.TLSEnabled() {
+		t.Fatal("TLSEnabled() = true, want false")
+	}
+}
+
+func TestLoadLayers(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "config.yaml")
+	content := `server_port: 4000
+read_timeout: 5s
+idle_timeout: 1m
+metrics_enabled: false
+cors_allowed_origins:
+  - https://example.com
+  - http://localhost:8080
+api_keys:
+  - client: game-lambda
+    key: k1
+    rate: 100
+`
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatalf("failed to write config file: %v", err)
+	}
+	t.Setenv(envConfigFile, path)
+	t.Setenv(envReadTimeout, "7s")
+	t.Setenv(envServerPort, "")
+	t.Setenv(envAPIKeys, "")
+
+	cfg, err := Load([]string{"--read-timeout=9s", "--log-level", "warn"})
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+
+	if cfg.ServerPort != "4000" {
+		t.Fatalf("ServerPort = %q, want file value", cfg.ServerPort)
+	}
+	if cfg.ReadTimeout != 9*time.Second {
+		t.Fatalf("ReadTimeout = %v, want flag value", cfg.ReadTimeout)
+	}
+	if cfg.IdleTimeout != time.Minute || cfg.MetricsEnabled {
+		t.Fatalf("unexpected file values: idle=%v metrics=%v", cfg.IdleTimeout, cfg.MetricsEnabled)
+	}
+	if cfg.LogLevel != "warn" {
+		t.Fatalf("LogLevel = %q, want %q", cfg.LogLevel, "warn")
+	}
+	if len(cfg.CORSAllowedOrigins) != 2 || cfg.CORSAllowedOrigins[1] != "http://localhost:8080" {
+		t.Fatalf("CORSAllowedOrigins = %v", cfg.CORSAllowedOrigins)
+	}
+	if len(cfg.APIKeys) != 1 || cfg.APIKeys[0] != (APIKey{Client: "game-lambda", Key: "k1", Rate: 100}) {
+		t.Fatalf("APIKeys = %+v", cfg.APIKeys)
+	}
+
+	t.Setenv(envReadTimeout, "")
+	cfg, err = Load(nil)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if cfg.ReadTimeout != 5*time.Second {
+		t.Fatalf("ReadTimeout = %v, want file value", cfg.ReadTimeout)
+	}
+
+	t.Setenv(envAPIKeys, "tooling=k2")
+	cfg, err = Load(nil)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if len(cfg.APIKeys) != 1 || cfg.APIKeys[0].Client != "tooling" {
+		t.Fatalf("APIKeys = %+v, want environment value to override file", cfg.APIKeys)
+	}
+}
+
+func TestLoadTOML(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.toml")
+	content := `shutdown_grace_period = "30s"
+rate_limit_ip_rps = 2.5
+cors_allowed_methods = ["GET"]
+
+[[api_keys]]
+client = "tooling"
+key = "k2"
+burst = 3
+`
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatalf("failed to write config file: %v", err)
+	}
+	t.Setenv(envAPIKeys, "")
+	t.Setenv(envIPRateLimit, "")
+
+	cfg, err := Load([]string{"--config", path})
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+
+	if cfg.ShutdownGracePeriod != 30*time.Second || cfg.IPRateLimit != 2.5 {
+		t.Fatalf("unexpected values: grace=%v ip=%v", cfg.ShutdownGracePeriod, cfg.IPRateLimit)
+	}
+	if len(cfg.CORSAllowedMethods) != 1 || cfg.CORSAllowedMethods[0] != "GET" {
+		t.Fatalf("CORSAllowedMethods = %v", cfg.CORSAllowedMethods)
+	}
+	if len(cfg.APIKeys) != 1 || cfg.APIKeys[0] != (APIKey{Client: "tooling", Key: "k2", Burst: 3}) {
+		t.Fatalf("APIKeys = %+v", cfg.APIKeys)
+	}
+}
+
+func TestLoadErrorNamesKey(t *testing.T) {
+	dir := t.TempDir()
+	yamlPath := filepath.Join(dir, "config.yaml")
+	if err := os.WriteFile(yamlPath, []byte("read_timout: 5s\n"), 0o600); err != nil {
+		t.Fatalf("failed to write config file: %v", err)
+	}
+	certPath := filepath.Join(dir, "cert.pem")
+
+	cases := []struct {
+		name string
+		env  map[string]string
+		args []string
+		want string
+	}{
+		{
+			name: "env",
+			env:  map[string]string{envWriteTimeout: "soon"},
+			want: `config write_timeout (env WRITE_TIMEOUT): must be a non-negative duration such as 10s, got "soon"`,
+		},
+		{
+			name: "flag",
+			args: []string{"--server-port=http"},
+			want: `config server_port (flag --server-port): must be a port number between 0 and 65535, got "http"`,
+		},
+		{
+			name: "unknown file key",
+			args: []string{"--config=" + yamlPath},
+			want: "config read_timout (file " + yamlPath + "): unknown key",
+		},
+		{
+			name: "tls pair",
+			env:  map[string]string{envTLSCertFile: certPath},
+			want: "config tls_key_file: tls_cert_file and tls_key_file must be set together",
+		},
+		{
+			name: "log level",
+			args: []string{"--log-level=verbose"},
+			want: `config log_level (flag --log-level): must be one of debug, info, warn or error, got "verbose"`,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			for _, key := range []string{envWriteTimeout, envTLSCertFile, envTLSKeyFile, envConfigFile} {
+				t.Setenv(key, tc.env[key])
+			}
+
+			_, err := Load(tc.args)
+			if err == nil || err.Error() != tc.want {
+				t.Fatalf("Load() error = %v, want %q", err, tc.want)
+			}
+		})
+	}
+}
+
+func TestLoadUnsupportedConfigFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.json")
+	if err := os.WriteFile(path, []byte(`{}`), 0o600); err != nil {
+		t.Fatalf("failed to write config file: %v", err)
+	}
+
+	if _, err := Load([]string{"--config", path}); err == nil {
+		t.Fatal("expected error for unsupported extension")
+	}
+	if _, err := Load([]string{"--no-such-flag"}); err == nil {
+		t.Fatal("expected error for unknown flag")
+	}
 }
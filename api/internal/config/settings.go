@@ -0,0 +1,218 @@
+package config
+
+import (
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// setting は 1 つの設定キーについて、環境変数名・説明・値の反映方法をまとめたもの。
+// 設定ファイルのキーは key、コマンドライン引数は key の "_" を "-" に置き換えた名前で指定する。
+// allowEmpty が true の設定は、空の環境変数も「未設定」ではなく空の値として反映する。
+type setting struct {
+	key        string
+	env        string
+	usage      string
+	allowEmpty bool
+	apply      func(cfg *Config, raw string) error
+}
+
+const (
+	envConfigFile          = "CONFIG_FILE"
+	envServerPort          = "SERVER_PORT"
+	envGRPCPort            = "GRPC_PORT"
+	envLogLevel            = "LOG_LEVEL"
+	envTLSCertFile         = "TLS_CERT_FILE"
+	envTLSKeyFile          = "TLS_KEY_FILE"
+	envReadTimeout         = "READ_TIMEOUT"
+	envReadHeaderTimeout   = "READ_HEADER_TIMEOUT"
+	envWriteTimeout        = "WRITE_TIMEOUT"
+	envIdleTimeout         = "IDLE_TIMEOUT"
+	envShutdownDrainDelay  = "SHUTDOWN_DRAIN_DELAY"
+	envShutdownGracePeriod = "SHUTDOWN_GRACE_PERIOD"
+	envMetricsEnabled      = "METRICS_ENABLED"
+	envTraceExporter       = "TRACE_EXPORTER"
+	envAPIKeys             = "API_KEYS"
+	envAPIKeyFile          = "API_KEY_FILE"
+	envAPIKeysJSON         = "API_KEYS_JSON"
+	envKeyRateLimit        = "RATE_LIMIT_KEY_RPS"
+	envKeyRateBurst        = "RATE_LIMIT_KEY_BURST"
+	envIPRateLimit         = "RATE_LIMIT_IP_RPS"
+	envIPRateBurst         = "RATE_LIMIT_IP_BURST"
+	envTrustedProxies      = "TRUSTED_PROXIES"
+	envCORSAllowedOrigins  = "CORS_ALLOWED_ORIGINS"
+	envCORSAllowedMethods  = "CORS_ALLOWED_METHODS"
+	envCORSAllowedHeaders  = "CORS_ALLOWED_HEADERS"
+	envCORSMaxAge          = "CORS_MAX_AGE"
+	envEnvelopeKey         = "ENVELOPE_KEY"
+	envEnvelopeSigningKey  = "ENVELOPE_SIGNING_KEY"
+	envEnvelopeKeyFile     = "ENVELOPE_KEY_FILE"
+	envJobWorkers          = "JOB_WORKERS"
+	envJobQueueSize        = "JOB_QUEUE_SIZE"
+	envJobTTL              = "JOB_TTL"
+)
+
+var settings = []setting{
+	{key: "server_port", env: envServerPort, usage: "HTTP サーバがバインドするポート番号", apply: func(c *Config, v string) error {
+		if err := validatePort(v); err != nil {
+			return err
+		}
+		c.ServerPort = v
+		return nil
+	}},
+	{key: "grpc_port", env: envGRPCPort, usage: "gRPC サーバがバインドするポート番号（空で無効）", allowEmpty: true, apply: func(c *Config, v string) error {
+		if v != "" {
+			if err := validatePort(v); err != nil {
+				return err
+			}
+		}
+		c.GRPCPort = v
+		return nil
+	}},
+	{key: "log_level", env: envLogLevel, usage: "ログの出力レベル（debug / info / warn / error）", apply: func(c *Config, v string) error {
+		level := strings.ToLower(v)
+		switch level {
+		case "debug", "info", "warn", "error":
+			c.LogLevel = level
+			return nil
+		}
+		return fmt.Errorf("must be one of debug, info, warn or error, got %q", v)
+	}},
+	{key: "tls_cert_file", env: envTLSCertFile, usage: "TLS 証明書のパス", apply: stringSetter(func(c *Config) *string { return &c.TLSCertFile })},
+	{key: "tls_key_file", env: envTLSKeyFile, usage: "TLS 秘密鍵のパス", apply: stringSetter(func(c *Config) *string { return &c.TLSKeyFile })},
+	{key: "read_timeout", env: envReadTimeout, usage: "リクエスト全体の読み込みタイムアウト", apply: durationSetter(func(c *Config) *time.Duration { return &c.ReadTimeout })},
+	{key: "read_header_timeout", env: envReadHeaderTimeout, usage: "リクエストヘッダの読み込みタイムアウト", apply: durationSetter(func(c *Config) *time.Duration { return &c.ReadHeaderTimeout })},
+	{key: "write_timeout", env: envWriteTimeout, usage: "レスポンスの書き込みタイムアウト", apply: durationSetter(func(c *Config) *time.Duration { return &c.WriteTimeout })},
+	{key: "idle_timeout", env: envIdleTimeout, usage: "Keep-Alive 接続のアイドルタイムアウト", apply: durationSetter(func(c *Config) *time.Duration { return &c.IdleTimeout })},
+	{key: "shutdown_drain_delay", env: envShutdownDrainDelay, usage: "シャットダウン時に /readyz を 503 にしてから受け付けを止めるまでの時間", apply: durationSetter(func(c *Config) *time.Duration { return &c.ShutdownDrainDelay })},
+	{key: "shutdown_grace_period", env: envShutdownGracePeriod, usage: "シャットダウン時に処理中のリクエストを待つ最大時間", apply: durationSetter(func(c *Config) *time.Duration { return &c.ShutdownGracePeriod })},
+	{key: "metrics_enabled", env: envMetricsEnabled, usage: "GET /metrics を公開するか", apply: func(c *Config, v string) error {
+		enabled, err := strconv.ParseBool(v)
+		if err != nil {
+			return fmt.Errorf("must be a boolean, got %q", v)
+		}
+		c.MetricsEnabled = enabled
+		return nil
+	}},
+	{key: "trace_exporter", env: envTraceExporter, usage: "スパンの出力先（none / stdout / otlp）", apply: func(c *Config, v string) error {
+		switch v {
+		case "none", "stdout", "otlp":
+			c.TraceExporter = v
+			return nil
+		}
+		return fmt.Errorf("unsupported exporter %q (want none, stdout or otlp)", v)
+	}},
+	{key: "api_keys", env: envAPIKeys, usage: "API キー（client=key のカンマ区切り）", apply: func(c *Config, v string) error {
+		c.APIKeys = nil
+		for _, pair := range splitList(v) {
+			client, key, ok := strings.Cut(pair, "=")
+			if !ok {
+				return fmt.Errorf("entry %q must be client=key", pair)
+			}
+			c.APIKeys = append(c.APIKeys, APIKey{Client: client, Key: key})
+		}
+		return nil
+	}},
+	{key: "api_key_file", env: envAPIKeyFile, usage: "API キーを記述した JSON ファイルのパス", apply: stringSetter(func(c *Config) *string { return &c.apiKeyFile })},
+	{key: "api_keys_json", env: envAPIKeysJSON, usage: "API キー（api_key_file と同じ形式の JSON。Secrets Manager から注入する場合に使う）", apply: stringSetter(func(c *Config) *string { return &c.apiKeysJSON })},
+	{key: "rate_limit_key_rps", env: envKeyRateLimit, usage: "API キー単位の毎秒リクエスト数", apply: floatSetter(func(c *Config) *float64 { return &c.KeyRateLimit })},
+	{key: "rate_limit_key_burst", env: envKeyRateBurst, usage: "API キー単位のバースト", apply: intSetter(func(c *Config) *int { return &c.KeyRateBurst })},
+	{key: "rate_limit_ip_rps", env: envIPRateLimit, usage: "接続元 IP 単位の毎秒リクエスト数（0 で無効）", apply: floatSetter(func(c *Config) *float64 { return &c.IPRateLimit })},
+	{key: "rate_limit_ip_burst", env: envIPRateBurst, usage: "接続元 IP 単位のバースト", apply: intSetter(func(c *Config) *int { return &c.IPRateBurst })},
+	{key: "trusted_proxies", env: envTrustedProxies, usage: "X-Forwarded-For を信頼するプロキシの IP / CIDR（カンマ区切り、空で信頼しない）", allowEmpty: true, apply: func(c *Config, v string) error {
+		proxies := splitList(v)
+		for _, proxy := range proxies {
+			if _, _, err := net.ParseCIDR(proxy); err != nil && net.ParseIP(proxy) == nil {
+				return fmt.Errorf("proxy %q must be an IP address or CIDR", proxy)
+			}
+		}
+		c.TrustedProxies = proxies
+		return nil
+	}},
+	{key: "cors_allowed_origins", env: envCORSAllowedOrigins, usage: "CORS で許可するオリジン（カンマ区切り）", apply: func(c *Config, v string) error {
+		origins := splitList(v)
+		for _, origin := range origins {
+			if origin != "*" && !strings.HasPrefix(origin, "http://") && !strings.HasPrefix(origin, "https://") {
+				return fmt.Errorf("origin %q must start with http:// or https://", origin)
+			}
+		}
+		c.CORSAllowedOrigins = origins
+		return nil
+	}},
+	{key: "cors_allowed_methods", env: envCORSAllowedMethods, usage: "CORS プリフライトで許可するメソッド", apply: listSetter(func(c *Config) *[]string { return &c.CORSAllowedMethods })},
+	{key: "cors_allowed_headers", env: envCORSAllowedHeaders, usage: "CORS プリフライトで許可するリクエストヘッダ", apply: listSetter(func(c *Config) *[]string { return &c.CORSAllowedHeaders })},
+	{key: "cors_max_age", env: envCORSMaxAge, usage: "CORS プリフライト結果のキャッシュ期間", apply: durationSetter(func(c *Config) *time.Duration { return &c.CORSMaxAge })},
+	{key: "envelope_key", env: envEnvelopeKey, usage: "暗号化エンベロープの AES 鍵（base64）", apply: stringSetter(func(c *Config) *string { return &c.envelopeKeyRaw })},
+	{key: "envelope_signing_key", env: envEnvelopeSigningKey, usage: "暗号化エンベロープの Ed25519 シード（base64）", apply: stringSetter(func(c *Config) *string { return &c.envelopeSigningRaw })},
+	{key: "envelope_key_file", env: envEnvelopeKeyFile, usage: "エンベロープ鍵を記述した JSON ファイルのパス", apply: stringSetter(func(c *Config) *string { return &c.envelopeKeyFile })},
+	{key: "job_workers", env: envJobWorkers, usage: "非同期変換ジョブの同時実行数（0 で /v1/jobs を無効）", apply: intSetter(func(c *Config) *int { return &c.JobWorkers })},
+	{key: "job_queue_size", env: envJobQueueSize, usage: "実行を待つ非同期変換ジョブの最大数", apply: intSetter(func(c *Config) *int { return &c.JobQueueSize })},
+	{key: "job_ttl", env: envJobTTL, usage: "完了した非同期変換ジョブの結果を保持する期間", apply: durationSetter(func(c *Config) *time.Duration { return &c.JobTTL })},
+}
+
+func validatePort(v string) error {
+	port, err := strconv.Atoi(v)
+	if err != nil || port < 0 || port > 65535 {
+		return fmt.Errorf("must be a port number between 0 and 65535, got %q", v)
+	}
+	return nil
+}
+
+func stringSetter(field func(*Config) *string) func(*Config, string) error {
+	return func(c *Config, v string) error {
+		*field(c) = v
+		return nil
+	}
+}
+
+func listSetter(field func(*Config) *[]string) func(*Config, string) error {
+	return func(c *Config, v string) error {
+		*field(c) = splitList(v)
+		return nil
+	}
+}
+
+func durationSetter(field func(*Config) *time.Duration) func(*Config, string) error {
+	return func(c *Config, v string) error {
+		d, err := time.ParseDuration(v)
+		if err != nil || d < 0 {
+			return fmt.Errorf("must be a non-negative duration such as 10s, got %q", v)
+		}
+		*field(c) = d
+		return nil
+	}
+}
+
+func floatSetter(field func(*Config) *float64) func(*Config, string) error {
+	return func(c *Config, v string) error {
+		f, err := strconv.ParseFloat(v, 64)
+		if err != nil || f < 0 {
+			return fmt.Errorf("must be a non-negative number, got %q", v)
+		}
+		*field(c) = f
+		return nil
+	}
+}
+
+func intSetter(field func(*Config) *int) func(*Config, string) error {
+	return func(c *Config, v string) error {
+		i, err := strconv.Atoi(v)
+		if err != nil || i < 0 {
+			return fmt.Errorf("must be a non-negative integer, got %q", v)
+		}
+		*field(c) = i
+		return nil
+	}
+}
+
+func splitList(raw string) []string {
+	var values []string
+	for _, value := range strings.Split(raw, ",") {
+		if value = strings.TrimSpace(value); value != "" {
+			values = append(values, value)
+		}
+	}
+	return values
+}
@@ -0,0 +1,168 @@
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/pelletier/go-toml/v2"
+	"gopkg.in/yaml.v3"
+)
+
+var errUnknownKey = errors.New("unknown key")
+
+// sourcedValue は設定値の文字列表現と、その値を読み込んだ場所を保持する。
+type sourcedValue struct {
+	raw    string
+	source string
+}
+
+func merge(dst, src map[string]sourcedValue) {
+	for key, value := range src {
+		dst[key] = value
+	}
+}
+
+// envValues は環境変数を設定キーごとに集める。
+// 空の環境変数は未設定として扱うが、空に意味がある設定（allowEmpty）では設定されていれば空でも採用する。
+func envValues() map[string]sourcedValue {
+	values := map[string]sourcedValue{}
+	for _, s := range settings {
+		if raw, ok := os.LookupEnv(s.env); ok && (raw != "" || s.allowEmpty) {
+			values[s.key] = sourcedValue{raw: raw, source: "env " + s.env}
+		}
+	}
+	return values
+}
+
+func flagName(key string) string {
+	return strings.ReplaceAll(key, "_", "-")
+}
+
+// parseFlags はコマンドライン引数を解釈し、明示的に指定された設定値と --config の値を返す。
+// 型の検証は他の読み込み元と揃えるため、すべて文字列として受け取ってから settings で行う。
+func parseFlags(args []string) (map[string]sourcedValue, string, error) {
+	fs := flag.NewFlagSet("ws-decode-api", flag.ContinueOnError)
+	fs.SetOutput(&bytes.Buffer{})
+
+	configPath := fs.String("config", "", "設定ファイルのパス（.yaml / .yml / .toml）")
+	raws := make(map[string]*string, len(settings))
+	for _, s := range settings {
+		raws[s.key] = fs.String(flagName(s.key), "", s.usage+"（環境変数 "+s.env+"）")
+	}
+
+	if err := fs.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			fs.SetOutput(os.Stderr)
+			fs.PrintDefaults()
+		}
+		return nil, "", err
+	}
+	if fs.NArg() > 0 {
+		return nil, "", fmt.Errorf("unexpected argument %q", fs.Arg(0))
+	}
+
+	values := map[string]sourcedValue{}
+	for _, s := range settings {
+		name := flagName(s.key)
+		fs.Visit(func(f *flag.Flag) {
+			if f.Name == name {
+				values[s.key] = sourcedValue{raw: *raws[s.key], source: "flag --" + name}
+			}
+		})
+	}
+	return values, *configPath, nil
+}
+
+// readConfigFile は YAML または TOML の設定ファイルを読み込む。
+// api_keys はオブジェクトの配列として記述できるため、他のキーとは別に返す。
+func readConfigFile(path string) (map[string]sourcedValue, []APIKey, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, nil, fmt.Errorf("config file: %w", err)
+	}
+
+	doc := map[string]any{}
+	switch ext := strings.ToLower(filepath.Ext(path)); ext {
+	case ".yaml", ".yml":
+		err = yaml.Unmarshal(data, &doc)
+	case ".toml":
+		err = toml.Unmarshal(data, &doc)
+	default:
+		return nil, nil, fmt.Errorf("config file %s: unsupported extension %q (want .yaml, .yml or .toml)", path, ext)
+	}
+	if err != nil {
+		return nil, nil, fmt.Errorf("config file %s: %w", path, err)
+	}
+
+	known := make(map[string]struct{}, len(settings))
+	for _, s := range settings {
+		known[s.key] = struct{}{}
+	}
+
+	source := "file " + path
+	values := map[string]sourcedValue{}
+	var keys []APIKey
+	for key, value := range doc {
+		if _, ok := known[key]; !ok {
+			return nil, nil, &Error{Key: key, Source: source, Err: errUnknownKey}
+		}
+		if list, ok := value.([]any); ok && key == "api_keys" {
+			if keys, err = decodeAPIKeys(list); err != nil {
+				return nil, nil, &Error{Key: key, Source: source, Err: err}
+			}
+			continue
+		}
+		raw, err := scalarString(value)
+		if err != nil {
+			return nil, nil, &Error{Key: key, Source: source, Err: err}
+		}
+		values[key] = sourcedValue{raw: raw, source: source}
+	}
+	return values, keys, nil
+}
+
+// decodeAPIKeys はファイル上のオブジェクト配列を APIKey に変換する。未知のフィールドはエラーにする。
+func decodeAPIKeys(list []any) ([]APIKey, error) {
+	data, err := json.Marshal(list)
+	if err != nil {
+		return nil, err
+	}
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+	var keys []APIKey
+	if err := dec.Decode(&keys); err != nil {
+		return nil, err
+	}
+	return keys, nil
+}
+
+// scalarString はファイル上の値を環境変数と同じ文字列表現に変換する。配列はカンマ区切りにする。
+func scalarString(value any) (string, error) {
+	switch v := value.(type) {
+	case string:
+		return v, nil
+	case bool, int, int64, uint64, float64:
+		return fmt.Sprint(v), nil
+	case []any:
+		items := make([]string, 0, len(v))
+		for _, item := range v {
+			s, err := scalarString(item)
+			if err != nil {
+				return "", err
+			}
+			if strings.Contains(s, ",") {
+				return "", fmt.Errorf("list item %q must not contain a comma", s)
+			}
+			items = append(items, s)
+		}
+		return strings.Join(items, ","), nil
+	default:
+		return "", fmt.Errorf("unsupported value of type %T", value)
+	}
+}
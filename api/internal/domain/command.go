@@ -17,13 +17,21 @@ const (
 
 	// CommandTypeBinariesToWhitespace は 2 進数列を Whitespace 文字列に変換する種別を表す。
 	CommandTypeBinariesToWhitespace CommandType = "BinariesToWhitespace"
+
+	// CommandTypeEncryptedDecimalToWhitespace は 10 進数列を暗号化エンベロープに封入し、Whitespace 文字列に変換する種別を表す。
+	CommandTypeEncryptedDecimalToWhitespace CommandType = "EncryptedDecimalToWhitespace"
+
+	// CommandTypeEncryptedWhitespaceToDecimal は暗号化エンベロープを含む Whitespace 文字列を検証・復号し、10 進数列に変換する種別を表す。
+	CommandTypeEncryptedWhitespaceToDecimal CommandType = "EncryptedWhitespaceToDecimal"
 )
 
 var supportedCommandTypes = map[CommandType]struct{}{
-	CommandTypeWhitespaceToDecimal: {},
-	CommandTypeWhitespaceToBinary:  {},
-	CommandTypeDecimalToWhitespace: {},
-	CommandTypeBinariesToWhitespace: {},
+	CommandTypeWhitespaceToDecimal:          {},
+	CommandTypeWhitespaceToBinary:           {},
+	CommandTypeDecimalToWhitespace:          {},
+	CommandTypeBinariesToWhitespace:         {},
+	CommandTypeEncryptedDecimalToWhitespace: {},
+	CommandTypeEncryptedWhitespaceToDecimal: {},
 }
 
 // ParseCommandType は文字列を CommandType に変換し、未対応の値の場合はエラーを返す。
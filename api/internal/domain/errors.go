@@ -1,6 +1,10 @@
 package domain
 
-import "errors"
+import (
+	"errors"
+
+	"github.com/2509-hackz-ichthyo/main/api/pkg/wscodec"
+)
 
 var (
 	// ErrInvalidCommandType は未サポートの命令種別が与えられた場合に返される。
@@ -10,5 +14,9 @@ var (
 	ErrTypeMismatch = errors.New("domain: command type mismatch for component")
 
 	// ErrInvalidPayload はペイロードの構文または値が不正な場合に返される。
-	ErrInvalidPayload = errors.New("domain: invalid command payload")
+	// 変換規則は wscodec にあるため、そのエラーと同一の値とする。
+	ErrInvalidPayload = wscodec.ErrInvalidPayload
+
+	// ErrInvalidPayloadFormat は未サポートのペイロード表現が与えられた場合に返される。
+	ErrInvalidPayloadFormat = errors.New("domain: invalid payload format")
 )
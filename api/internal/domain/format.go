@@ -0,0 +1,57 @@
+package domain
+
+import "fmt"
+
+// PayloadFormat は入力ペイロードがどの表現で書かれているかを表す。
+type PayloadFormat string
+
+const (
+	// PayloadFormatWhitespace は空白・タブ・改行で表現された Whitespace 文字列を示す。
+	PayloadFormatWhitespace PayloadFormat = "Whitespace"
+
+	// PayloadFormatBinary は "1011 0110 11010010" 形式の 2 進数列を示す。
+	PayloadFormatBinary PayloadFormat = "Binary"
+
+	// PayloadFormatDecimal は "11 6 210" 形式の 10 進数列を示す。
+	PayloadFormatDecimal PayloadFormat = "Decimal"
+)
+
+var supportedPayloadFormats = map[PayloadFormat]struct{}{
+	PayloadFormatWhitespace: {},
+	PayloadFormatBinary:     {},
+	PayloadFormatDecimal:    {},
+}
+
+// ParsePayloadFormat は文字列を PayloadFormat に変換し、未対応の値の場合はエラーを返す。
+func ParsePayloadFormat(raw string) (PayloadFormat, error) {
+	format := PayloadFormat(raw)
+	if _, ok := supportedPayloadFormats[format]; !ok {
+		return "", fmt.Errorf("%w: %s", ErrInvalidPayloadFormat, raw)
+	}
+	return format, nil
+}
+
+// SourceFormat は命令種別が入力として受け取るペイロードの表現を返す。
+func (ct CommandType) SourceFormat() (PayloadFormat, bool) {
+	switch ct {
+	case CommandTypeWhitespaceToDecimal, CommandTypeWhitespaceToBinary, CommandTypeEncryptedWhitespaceToDecimal:
+		return PayloadFormatWhitespace, true
+	case CommandTypeDecimalToWhitespace, CommandTypeEncryptedDecimalToWhitespace:
+		return PayloadFormatDecimal, true
+	case CommandTypeBinariesToWhitespace:
+		return PayloadFormatBinary, true
+	default:
+		return "", false
+	}
+}
+
+// Sentencewise は命令種別が文ごとに独立して変換できるかを返す。
+// 暗号化エンベロープはペイロード全体で 1 つの暗号文になるため、分割して変換できない。
+func (ct CommandType) Sentencewise() bool {
+	switch ct {
+	case CommandTypeWhitespaceToDecimal, CommandTypeWhitespaceToBinary, CommandTypeDecimalToWhitespace, CommandTypeBinariesToWhitespace:
+		return true
+	default:
+		return false
+	}
+}
@@ -0,0 +1,64 @@
+package domain
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestParsePayloadFormat(t *testing.T) {
+	t.Parallel()
+
+	for _, format := range []PayloadFormat{PayloadFormatWhitespace, PayloadFormatBinary, PayloadFormatDecimal} {
+		got, err := ParsePayloadFormat(string(format))
+		if err != nil {
+			t.Fatalf("unexpected error for %s: %v", format, err)
+		}
+		if got != format {
+			t.Fatalf("ParsePayloadFormat(%q) = %v", format, got)
+		}
+	}
+
+	if _, err := ParsePayloadFormat("Hex"); !errors.Is(err, ErrInvalidPayloadFormat) {
+		t.Fatalf("expected ErrInvalidPayloadFormat, got %v", err)
+	}
+}
+
+func TestCommandTypeSourceFormat(t *testing.T) {
+	t.Parallel()
+
+	cases := map[CommandType]PayloadFormat{
+		CommandTypeWhitespaceToDecimal:  PayloadFormatWhitespace,
+		CommandTypeWhitespaceToBinary:   PayloadFormatWhitespace,
+		CommandTypeDecimalToWhitespace:  PayloadFormatDecimal,
+		CommandTypeBinariesToWhitespace: PayloadFormatBinary,
+	}
+
+	for ct, want := range cases {
+		got, ok := ct.SourceFormat()
+		if !ok || got != want {
+			t.Fatalf("SourceFormat(%s) = (%v, %v), want (%v, true)", ct, got, ok, want)
+		}
+	}
+
+	if _, ok := CommandType("Unknown").SourceFormat(); ok {
+		t.Fatal("expected unknown command type to have no source format")
+	}
+}
+
+func TestCommandTypeSentencewise(t *testing.T) {
+	t.Parallel()
+
+	cases := map[CommandType]bool{
+		CommandTypeWhitespaceToDecimal:          true,
+		CommandTypeBinariesToWhitespace:         true,
+		CommandTypeEncryptedDecimalToWhitespace: false,
+		CommandTypeEncryptedWhitespaceToDecimal: false,
+		CommandType("Unknown"):                  false,
+	}
+
+	for ct, want := range cases {
+		if got := ct.Sentencewise(); got != want {
+			t.Fatalf("Sentencewise(%s) = %v, want %v", ct, got, want)
+		}
+	}
+}
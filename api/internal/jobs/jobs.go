@@ -0,0 +1,359 @@
+// Package jobs は大きな変換を非同期に実行するジョブの管理を提供する。
+//
+// ジョブは API プロセス内の固定数のワーカーで実行し、状態と結果はメモリに保持する。
+// 完了したジョブは TTL を過ぎると破棄する。
+package jobs
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/2509-hackz-ichthyo/main/api/internal/app"
+	"github.com/2509-hackz-ichthyo/main/api/internal/domain"
+)
+
+// 既定値。
+const (
+	DefaultWorkers   = 4
+	DefaultQueueSize = 100
+	DefaultTTL       = time.Hour
+	DefaultChunkSize = 1000
+)
+
+var (
+	// ErrNotFound は指定した ID のジョブが存在しない（または期限切れで破棄された）ことを表す。
+	ErrNotFound = errors.New("jobs: job not found")
+
+	// ErrQueueFull は待ち行列が満杯で、ジョブを受け付けられないことを表す。
+	ErrQueueFull = errors.New("jobs: queue is full")
+
+	// ErrClosed は Manager が停止済みで、ジョブを受け付けられないことを表す。
+	ErrClosed = errors.New("jobs: manager is closed")
+
+	// ErrNotFinished はジョブがまだ完了しておらず、結果を取得できないことを表す。
+	ErrNotFinished = errors.New("jobs: job has not finished")
+)
+
+// Status はジョブの状態を表す。queued → running → succeeded / failed の順に遷移する。
+type Status string
+
+// ジョブの状態。
+const (
+	StatusQueued    Status = "queued"
+	StatusRunning   Status = "running"
+	StatusSucceeded Status = "succeeded"
+	StatusFailed    Status = "failed"
+)
+
+// Done はジョブが完了（成功または失敗）しているかを返す。
+func (s Status) Done() bool {
+	return s == StatusSucceeded || s == StatusFailed
+}
+
+// Executor はジョブが依存するユースケースの最小限のインタフェース。
+type Executor interface {
+	Execute(ctx context.Context, command app.WhitespaceCommand) (app.WhitespaceResult, error)
+}
+
+// Job はジョブの状態のスナップショット。
+type Job struct {
+	ID          string
+	CommandType string
+	Status      Status
+	Total       int // 変換対象の文の数
+	Processed   int // 変換済みの文の数
+	CreatedAt   time.Time
+	StartedAt   time.Time // 実行前はゼロ値
+	FinishedAt  time.Time // 完了前はゼロ値
+	ExpiresAt   time.Time // 完了前はゼロ値
+	Err         error     // 失敗した場合の理由
+}
+
+// Option は NewManager の任意設定を表す。
+type Option func(*Manager)
+
+// WithWorkers は同時に実行するジョブの数を設定する。
+func WithWorkers(n int) Option {
+	return func(m *Manager) {
+		m.workers = max(n, 1)
+	}
+}
+
+// WithQueueSize は実行を待つジョブの最大数を設定する。
+func WithQueueSize(n int) Option {
+	return func(m *Manager) {
+		m.queueSize = max(n, 0)
+	}
+}
+
+// WithTTL は完了したジョブを保持する期間を設定する。
+func WithTTL(d time.Duration) Option {
+	return func(m *Manager) {
+		m.ttl = d
+	}
+}
+
+// WithChunkSize は進捗を更新する単位（1 回の Execute に渡す文の数）を設定する。
+func WithChunkSize(n int) Option {
+	return func(m *Manager) {
+		m.chunkSize = max(n, 1)
+	}
+}
+
+// Manager はジョブの受け付け・実行・破棄を担う。
+type Manager struct {
+	uc        Executor
+	workers   int
+	queueSize int
+	ttl       time.Duration
+	chunkSize int
+	now       func() time.Time
+
+	queue  chan *job
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+
+	mu     sync.Mutex
+	jobs   map[string]*job
+	closed bool
+}
+
+// job は Manager が保持するジョブ。フィールドは Manager.mu で保護する。
+type job struct {
+	Job
+	command app.WhitespaceCommand
+	result  app.WhitespaceResult
+}
+
+// NewManager は Manager を生成し、ワーカーと期限切れジョブの掃除を開始する。
+// 停止するには Shutdown を呼ぶ。
+func NewManager(uc Executor, opts ...Option) *Manager {
+	ctx, cancel := context.WithCancel(context.Background())
+	m := &Manager{
+		uc:        uc,
+		workers:   DefaultWorkers,
+		queueSize: DefaultQueueSize,
+		ttl:       DefaultTTL,
+		chunkSize: DefaultChunkSize,
+		now:       time.Now,
+		ctx:       ctx,
+		cancel:    cancel,
+		jobs:      map[string]*job{},
+	}
+	for _, opt := range opts {
+		opt(m)
+	}
+
+	m.queue = make(chan *job, m.queueSize)
+	for range m.workers {
+		m.wg.Add(1)
+		go m.work()
+	}
+	m.wg.Add(1)
+	go m.janitor()
+	return m
+}
+
+// Submit はジョブを待ち行列に追加し、そのスナップショットを返す。
+// ペイロードの検証は呼び出し側で済ませておくこと。
+func (m *Manager) Submit(command app.WhitespaceCommand) (Job, error) {
+	id, err := newID()
+	if err != nil {
+		return Job{}, err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.closed {
+		return Job{}, ErrClosed
+	}
+
+	j := &job{
+		Job: Job{
+			ID:          id,
+			CommandType: command.CommandType,
+			Status:      StatusQueued,
+			Total:       len(command.Payload),
+			CreatedAt:   m.now(),
+		},
+		command: command,
+	}
+	select {
+	case m.queue <- j:
+	default:
+		return Job{}, ErrQueueFull
+	}
+	m.jobs[id] = j
+	return j.Job, nil
+}
+
+// Get は ID に対応するジョブのスナップショットを返す。
+func (m *Manager) Get(id string) (Job, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	j, ok := m.jobs[id]
+	if !ok {
+		return Job{}, ErrNotFound
+	}
+	return j.Job, nil
+}
+
+// Result は成功したジョブの変換結果を返す。
+// 完了前なら ErrNotFinished を、失敗したジョブならその理由を返す。
+func (m *Manager) Result(id string) (app.WhitespaceResult, Job, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	j, ok := m.jobs[id]
+	switch {
+	case !ok:
+		return app.WhitespaceResult{}, Job{}, ErrNotFound
+	case !j.Status.Done():
+		return app.WhitespaceResult{}, j.Job, ErrNotFinished
+	case j.Status == StatusFailed:
+		return app.WhitespaceResult{}, j.Job, j.Err
+	default:
+		return j.result, j.Job, nil
+	}
+}
+
+// Shutdown は新規の受け付けを止め、実行中・待機中のジョブを中断してワーカーの終了を待つ。
+// 中断したジョブは context.Canceled で失敗した扱いになる。
+func (m *Manager) Shutdown(ctx context.Context) error {
+	m.mu.Lock()
+	if !m.closed {
+		m.closed = true
+		close(m.queue)
+	}
+	m.mu.Unlock()
+	m.cancel()
+
+	done := make(chan struct{})
+	go func() {
+		m.wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (m *Manager) work() {
+	defer m.wg.Done()
+	for j := range m.queue {
+		m.run(j)
+	}
+}
+
+// run はジョブを chunkSize 文ずつ実行し、その都度進捗を更新する。
+// 暗号化エンベロープのように文ごとに分割できない命令は一度に実行する。
+func (m *Manager) run(j *job) {
+	if err := m.ctx.Err(); err != nil {
+		m.finish(j, app.WhitespaceResult{}, err)
+		return
+	}
+
+	m.mu.Lock()
+	j.Status = StatusRunning
+	j.StartedAt = m.now()
+	m.mu.Unlock()
+
+	payload := j.command.Payload
+	chunkSize := len(payload)
+	if domain.CommandType(j.command.CommandType).Sentencewise() {
+		chunkSize = m.chunkSize
+	}
+
+	var result app.WhitespaceResult
+	for start := 0; start < len(payload); start += chunkSize {
+		end := min(start+chunkSize, len(payload))
+		chunk, err := m.uc.Execute(m.ctx, app.WhitespaceCommand{
+			CommandType: j.command.CommandType,
+			Payload:     payload[start:end],
+		})
+		if err != nil {
+			m.finish(j, app.WhitespaceResult{}, fmt.Errorf("sentences %d-%d: %w", start, end-1, err))
+			return
+		}
+		result = merge(result, chunk)
+
+		m.mu.Lock()
+		j.Processed = end
+		m.mu.Unlock()
+	}
+	m.finish(j, result, nil)
+}
+
+func (m *Manager) finish(j *job, result app.WhitespaceResult, err error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	now := m.now()
+	j.FinishedAt = now
+	j.ExpiresAt = now.Add(m.ttl)
+	j.command = app.WhitespaceCommand{}
+	if err != nil {
+		j.Status = StatusFailed
+		j.Err = err
+		return
+	}
+	j.Status = StatusSucceeded
+	j.result = result
+}
+
+// janitor は期限切れのジョブを定期的に破棄する。
+func (m *Manager) janitor() {
+	defer m.wg.Done()
+
+	ticker := time.NewTicker(max(m.ttl/10, time.Second))
+	defer ticker.Stop()
+	for {
+		select {
+		case <-m.ctx.Done():
+			return
+		case <-ticker.C:
+			m.expire()
+		}
+	}
+}
+
+func (m *Manager) expire() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	now := m.now()
+	for id, j := range m.jobs {
+		if j.Status.Done() && !now.Before(j.ExpiresAt) {
+			delete(m.jobs, id)
+		}
+	}
+}
+
+// merge はチャンクごとの変換結果を連結する。
+func merge(acc, chunk app.WhitespaceResult) app.WhitespaceResult {
+	acc.CommandType = chunk.CommandType
+	acc.ResultKind = chunk.ResultKind
+	acc.ResultDecimals = append(acc.ResultDecimals, chunk.ResultDecimals...)
+	acc.ResultBinaries = append(acc.ResultBinaries, chunk.ResultBinaries...)
+	acc.ResultWhitespace = append(acc.ResultWhitespace, chunk.ResultWhitespace...)
+	acc.ResultWhitespaceEncoded = append(acc.ResultWhitespaceEncoded, chunk.ResultWhitespaceEncoded...)
+	return acc
+}
+
+func newID() (string, error) {
+	var b [16]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		return "", fmt.Errorf("jobs: failed to generate job id: %w", err)
+	}
+	return hex.EncodeToString(b[:]), nil
+}
@@ -0,0 +1,240 @@
+package jobs
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/2509-hackz-ichthyo/main/api/internal/app"
+	"github.com/2509-hackz-ichthyo/main/api/internal/domain"
+)
+
+// blockingExecutor は release が閉じられるまで Execute を止め、受け取ったチャンクを記録する。
+type blockingExecutor struct {
+	release chan struct{}
+
+	mu     sync.Mutex
+	chunks [][]string
+}
+
+func (e *blockingExecutor) Execute(ctx context.Context, command app.WhitespaceCommand) (app.WhitespaceResult, error) {
+	e.mu.Lock()
+	e.chunks = append(e.chunks, command.Payload)
+	e.mu.Unlock()
+
+	select {
+	case <-e.release:
+	case <-ctx.Done():
+		return app.WhitespaceResult{}, ctx.Err()
+	}
+	return app.NewWhitespaceUsecase().Execute(ctx, command)
+}
+
+func newTestManager(t *testing.T, uc Executor, opts ...Option) *Manager {
+	t.Helper()
+	m := NewManager(uc, opts...)
+	t.Cleanup(func() { _ = m.Shutdown(context.Background()) })
+	return m
+}
+
+func waitFor(t *testing.T, m *Manager, id string, cond func(Job) bool) Job {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		job, err := m.Get(id)
+		if err != nil {
+			t.Fatalf("Get returned error: %v", err)
+		}
+		if cond(job) {
+			return job
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("timed out waiting for job: %+v", job)
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestManagerRunsJobInChunks(t *testing.T) {
+	uc := &blockingExecutor{release: make(chan struct{})}
+	close(uc.release)
+	m := newTestManager(t, uc, WithChunkSize(2))
+
+	job, err := m.Submit(app.WhitespaceCommand{
+		CommandType: string(domain.CommandTypeDecimalToWhitespace),
+		Payload:     []string{"1 2 3", "4 5 6", "7 8 9", "15 15 255", "0 0 0"},
+	})
+	if err != nil {
+		t.Fatalf("Submit returned error: %v", err)
+	}
+	if job.Status != StatusQueued || job.Total != 5 || len(job.ID) != 32 {
+		t.Fatalf("unexpected job: %+v", job)
+	}
+
+	done := waitFor(t, m, job.ID, func(j Job) bool { return j.Status.Done() })
+	if done.Status != StatusSucceeded || done.Processed != 5 || done.ExpiresAt.IsZero() {
+		t.Fatalf("unexpected finished job: %+v", done)
+	}
+
+	result, _, err := m.Result(job.ID)
+	if err != nil {
+		t.Fatalf("Result returned error: %v", err)
+	}
+	if len(result.ResultWhitespace) != 5 || result.ResultKind != domain.ResultKindWhitespace {
+		t.Fatalf("unexpected result: %+v", result)
+	}
+	if len(uc.chunks) != 3 || len(uc.chunks[2]) != 1 {
+		t.Fatalf("chunks = %q, want 3 chunks of at most 2 sentences", uc.chunks)
+	}
+}
+
+func TestManagerReportsProgress(t *testing.T) {
+	uc := &blockingExecutor{release: make(chan struct{})}
+	m := newTestManager(t, uc, WithChunkSize(1))
+
+	job, err := m.Submit(app.WhitespaceCommand{
+		CommandType: string(domain.CommandTypeDecimalToWhitespace),
+		Payload:     []string{"1 2 3", "4 5 6"},
+	})
+	if err != nil {
+		t.Fatalf("Submit returned error: %v", err)
+	}
+
+	running := waitFor(t, m, job.ID, func(j Job) bool { return j.Status == StatusRunning })
+	if running.Processed != 0 || running.StartedAt.IsZero() {
+		t.Fatalf("unexpected running job: %+v", running)
+	}
+	if _, _, err := m.Result(job.ID); !errors.Is(err, ErrNotFinished) {
+		t.Fatalf("Result error = %v, want ErrNotFinished", err)
+	}
+
+	close(uc.release)
+	waitFor(t, m, job.ID, func(j Job) bool { return j.Status == StatusSucceeded && j.Processed == 2 })
+}
+
+func TestManagerDoesNotSplitEnvelopeCommands(t *testing.T) {
+	uc := &blockingExecutor{release: make(chan struct{})}
+	close(uc.release)
+	m := newTestManager(t, uc, WithChunkSize(1))
+
+	job, err := m.Submit(app.WhitespaceCommand{
+		CommandType: string(domain.CommandTypeEncryptedDecimalToWhitespace),
+		Payload:     []string{"1 2 3", "4 5 6"},
+	})
+	if err != nil {
+		t.Fatalf("Submit returned error: %v", err)
+	}
+
+	done := waitFor(t, m, job.ID, func(j Job) bool { return j.Status.Done() })
+	if done.Status != StatusFailed || !errors.Is(done.Err, app.ErrEnvelopeKeyNotConfigured) {
+		t.Fatalf("unexpected job: %+v", done)
+	}
+	if _, _, err := m.Result(job.ID); !errors.Is(err, app.ErrEnvelopeKeyNotConfigured) {
+		t.Fatalf("Result error = %v", err)
+	}
+	if len(uc.chunks) != 1 || len(uc.chunks[0]) != 2 {
+		t.Fatalf("chunks = %q, want a single chunk", uc.chunks)
+	}
+}
+
+func TestManagerFailedChunkReportsRange(t *testing.T) {
+	m := newTestManager(t, app.NewWhitespaceUsecase(), WithChunkSize(2))
+
+	job, err := m.Submit(app.WhitespaceCommand{
+		CommandType: string(domain.CommandTypeDecimalToWhitespace),
+		Payload:     []string{"1 2 3", "4 5 6", "99 0 0"},
+	})
+	if err != nil {
+		t.Fatalf("Submit returned error: %v", err)
+	}
+
+	done := waitFor(t, m, job.ID, func(j Job) bool { return j.Status.Done() })
+	if done.Status != StatusFailed || done.Processed != 2 || !errors.Is(done.Err, domain.ErrInvalidPayload) {
+		t.Fatalf("unexpected job: %+v", done)
+	}
+	if !strings.HasPrefix(done.Err.Error(), "sentences 2-2: ") {
+		t.Fatalf("error = %q", done.Err)
+	}
+}
+
+func TestManagerQueueFull(t *testing.T) {
+	uc := &blockingExecutor{release: make(chan struct{})}
+	m := newTestManager(t, uc, WithWorkers(1), WithQueueSize(1))
+	command := app.WhitespaceCommand{CommandType: string(domain.CommandTypeDecimalToWhitespace), Payload: []string{"1 2 3"}}
+
+	first, err := m.Submit(command)
+	if err != nil {
+		t.Fatalf("Submit returned error: %v", err)
+	}
+	waitFor(t, m, first.ID, func(j Job) bool { return j.Status == StatusRunning })
+
+	if _, err := m.Submit(command); err != nil {
+		t.Fatalf("second Submit returned error: %v", err)
+	}
+	if _, err := m.Submit(command); !errors.Is(err, ErrQueueFull) {
+		t.Fatalf("third Submit error = %v, want ErrQueueFull", err)
+	}
+	close(uc.release)
+}
+
+func TestManagerExpiresFinishedJobs(t *testing.T) {
+	m := newTestManager(t, app.NewWhitespaceUsecase(), WithTTL(time.Minute))
+	now := time.Now()
+	m.mu.Lock()
+	m.now = func() time.Time { return now }
+	m.mu.Unlock()
+
+	job, err := m.Submit(app.WhitespaceCommand{CommandType: string(domain.CommandTypeDecimalToWhitespace), Payload: []string{"1 2 3"}})
+	if err != nil {
+		t.Fatalf("Submit returned error: %v", err)
+	}
+	done := waitFor(t, m, job.ID, func(j Job) bool { return j.Status.Done() })
+	if !done.ExpiresAt.Equal(now.Add(time.Minute)) {
+		t.Fatalf("ExpiresAt = %v, want %v", done.ExpiresAt, now.Add(time.Minute))
+	}
+
+	m.expire()
+	if _, err := m.Get(job.ID); err != nil {
+		t.Fatalf("job expired too early: %v", err)
+	}
+
+	m.mu.Lock()
+	m.now = func() time.Time { return now.Add(time.Minute) }
+	m.mu.Unlock()
+	m.expire()
+	if _, err := m.Get(job.ID); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("Get error = %v, want ErrNotFound", err)
+	}
+}
+
+func TestManagerShutdownCancelsJobs(t *testing.T) {
+	uc := &blockingExecutor{release: make(chan struct{})}
+	m := NewManager(uc, WithWorkers(1))
+	command := app.WhitespaceCommand{CommandType: string(domain.CommandTypeDecimalToWhitespace), Payload: []string{"1 2 3"}}
+
+	running, err := m.Submit(command)
+	if err != nil {
+		t.Fatalf("Submit returned error: %v", err)
+	}
+	queued, err := m.Submit(command)
+	if err != nil {
+		t.Fatalf("Submit returned error: %v", err)
+	}
+	waitFor(t, m, running.ID, func(j Job) bool { return j.Status == StatusRunning })
+
+	if err := m.Shutdown(context.Background()); err != nil {
+		t.Fatalf("Shutdown returned error: %v", err)
+	}
+	for _, id := range []string{running.ID, queued.ID} {
+		job, err := m.Get(id)
+		if err != nil || job.Status != StatusFailed || !errors.Is(job.Err, context.Canceled) {
+			t.Fatalf("job %s = %+v, %v; want failed with context.Canceled", id, job, err)
+		}
+	}
+	if _, err := m.Submit(command); !errors.Is(err, ErrClosed) {
+		t.Fatalf("Submit after Shutdown error = %v, want ErrClosed", err)
+	}
+}
@@ -0,0 +1,68 @@
+// Package logging は log/slog による構造化ログの共通設定を提供する。
+package logging
+
+import (
+	"context"
+	"io"
+	"log/slog"
+
+	"go.opentelemetry.io/otel/trace"
+)
+
+type requestIDKey struct{}
+
+// WithRequestID はリクエスト ID を保持したコンテキストを返す。
+func WithRequestID(ctx context.Context, requestID string) context.Context {
+	return context.WithValue(ctx, requestIDKey{}, requestID)
+}
+
+// RequestID はコンテキストに保持されたリクエスト ID を返す。
+func RequestID(ctx context.Context) (string, bool) {
+	id, ok := ctx.Value(requestIDKey{}).(string)
+	return id, ok && id != ""
+}
+
+// NewJSONLogger は w へ JSON 形式で出力し、コンテキストのリクエスト ID とトレース ID を付与する Logger を生成する。
+func NewJSONLogger(w io.Writer, level slog.Leveler) *slog.Logger {
+	return slog.New(NewHandler(slog.NewJSONHandler(w, &slog.HandlerOptions{Level: level})))
+}
+
+// Handler は slog.Handler をラップし、*Context 系の呼び出しで渡されたコンテキストから
+// request_id / trace_id / span_id を取り出してすべてのログ行に付与する。
+type Handler struct {
+	inner slog.Handler
+}
+
+// NewHandler は inner をラップした Handler を生成する。
+func NewHandler(inner slog.Handler) *Handler {
+	return &Handler{inner: inner}
+}
+
+// Enabled は inner の設定に従う。
+func (h *Handler) Enabled(ctx context.Context, level slog.Level) bool {
+	return h.inner.Enabled(ctx, level)
+}
+
+// Handle はコンテキスト由来の属性を追加してから inner へ委譲する。
+func (h *Handler) Handle(ctx context.Context, record slog.Record) error {
+	if id, ok := RequestID(ctx); ok {
+		record.AddAttrs(slog.String("request_id", id))
+	}
+	if sc := trace.SpanContextFromContext(ctx); sc.IsValid() {
+		record.AddAttrs(
+			slog.String("trace_id", sc.TraceID().String()),
+			slog.String("span_id", sc.SpanID().String()),
+		)
+	}
+	return h.inner.Handle(ctx, record)
+}
+
+// WithAttrs は属性を追加した Handler を返す。
+func (h *Handler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &Handler{inner: h.inner.WithAttrs(attrs)}
+}
+
+// WithGroup はグループを追加した Handler を返す。
+func (h *Handler) WithGroup(name string) slog.Handler {
+	return &Handler{inner: h.inner.WithGroup(name)}
+}
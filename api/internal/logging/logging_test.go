@@ -0,0 +1,64 @@
+package logging
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"testing"
+
+	"go.opentelemetry.io/otel/trace"
+)
+
+func TestHandlerAddsRequestAndTraceIDs(t *testing.T) {
+	var buf bytes.Buffer
+	logger := NewJSONLogger(&buf, slog.LevelInfo)
+
+	traceID, _ := trace.TraceIDFromHex("0102030405060708090a0b0c0d0e0f10")
+	spanID, _ := trace.SpanIDFromHex("0102030405060708")
+	ctx := trace.ContextWithSpanContext(context.Background(), trace.NewSpanContext(trace.SpanContextConfig{
+		TraceID: traceID,
+		SpanID:  spanID,
+	}))
+	ctx = WithRequestID(ctx, "req-1")
+
+	logger.With("component", "test").InfoContext(ctx, "hello")
+
+	var line map[string]any
+	if err := json.Unmarshal(buf.Bytes(), &line); err != nil {
+		t.Fatalf("failed to unmarshal log line: %v", err)
+	}
+
+	want := map[string]string{
+		"msg":        "hello",
+		"component":  "test",
+		"request_id": "req-1",
+		"trace_id":   traceID.String(),
+		"span_id":    spanID.String(),
+	}
+	for key, value := range want {
+		if line[key] != value {
+			t.Fatalf("%s = %v, want %q", key, line[key], value)
+		}
+	}
+}
+
+func TestHandlerWithoutContextValues(t *testing.T) {
+	var buf bytes.Buffer
+	logger := NewJSONLogger(&buf, slog.LevelInfo)
+
+	logger.InfoContext(context.Background(), "plain")
+	logger.Debug("filtered")
+
+	var line map[string]any
+	if err := json.Unmarshal(buf.Bytes(), &line); err != nil {
+		t.Fatalf("failed to unmarshal log line: %v", err)
+	}
+
+	if _, ok := line["request_id"]; ok {
+		t.Fatalf("unexpected request_id in %v", line)
+	}
+	if _, ok := line["trace_id"]; ok {
+		t.Fatalf("unexpected trace_id in %v", line)
+	}
+}
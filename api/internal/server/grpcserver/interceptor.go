@@ -0,0 +1,166 @@
+package grpcserver
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"log/slog"
+	"net"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/2509-hackz-ichthyo/main/api/internal/logging"
+	"github.com/2509-hackz-ichthyo/main/api/internal/server/httpserver"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+// requestIDMetadataKey はリクエスト ID を受け渡すメタデータのキー。HTTP の X-Request-ID に対応する。
+var requestIDMetadataKey = strings.ToLower(httpserver.RequestIDHeader)
+
+// maxRequestIDLength を超えるクライアント指定のリクエスト ID は破棄して採番し直す。
+const maxRequestIDLength = 128
+
+// unaryLogger は HTTP の requestLogger と同じ形式で、1 RPC につき 1 行の構造化ログを出力する。
+func unaryLogger(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+	ctx = withRequestID(ctx)
+	start := time.Now()
+	resp, err := handler(ctx, req)
+	logRPC(ctx, info.FullMethod, start, err)
+	return resp, err
+}
+
+func streamLogger(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	ctx := withRequestID(ss.Context())
+	start := time.Now()
+	err := handler(srv, &contextStream{ServerStream: ss, ctx: ctx})
+	logRPC(ctx, info.FullMethod, start, err)
+	return err
+}
+
+// apiKeyMetadataKey は API キーを受け取るメタデータのキー。HTTP の X-API-Key に対応し、authorization: Bearer でも受け付ける。
+var apiKeyMetadataKey = strings.ToLower(httpserver.APIKeyHeader)
+
+// unaryAuth は HTTP の /v1 と同じ API キー認証とレート制限を WhitespaceService の RPC に適用する。
+// ヘルスチェックとリフレクションは HTTP の /healthz と同様に対象外とする。
+func unaryAuth(a *httpserver.Authenticator) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		if err := authorize(ctx, a, info.FullMethod); err != nil {
+			return nil, err
+		}
+		return handler(ctx, req)
+	}
+}
+
+func streamAuth(a *httpserver.Authenticator) grpc.StreamServerInterceptor {
+	return func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		if err := authorize(ss.Context(), a, info.FullMethod); err != nil {
+			return err
+		}
+		return handler(srv, ss)
+	}
+}
+
+// authorize は接続元 IP とメタデータの API キーを検証し、失敗した場合は gRPC のステータスに変換して返す。
+// レート制限を超えた場合は retry-after（秒）をトレーラで返す。
+func authorize(ctx context.Context, a *httpserver.Authenticator, method string) error {
+	if !strings.HasPrefix(method, "/"+ServiceName+"/") {
+		return nil
+	}
+
+	_, retryAfter, err := a.Authorize(peerIP(ctx), metadataAPIKey(ctx))
+	switch {
+	case errors.Is(err, httpserver.ErrMissingAPIKey):
+		return status.Error(codes.Unauthenticated, "API キーが指定されていません")
+	case errors.Is(err, httpserver.ErrInvalidAPIKey):
+		return status.Error(codes.Unauthenticated, "API キーが不正です")
+	case errors.Is(err, httpserver.ErrRateLimited):
+		_ = grpc.SetTrailer(ctx, metadata.Pairs("retry-after", strconv.Itoa(int(retryAfter.Seconds()))))
+		return status.Error(codes.ResourceExhausted, "リクエストが多すぎます")
+	}
+	return nil
+}
+
+func metadataAPIKey(ctx context.Context) string {
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok {
+		return ""
+	}
+	if values := md.Get(apiKeyMetadataKey); len(values) > 0 && strings.TrimSpace(values[0]) != "" {
+		return strings.TrimSpace(values[0])
+	}
+	if values := md.Get("authorization"); len(values) > 0 {
+		auth := values[0]
+		if len(auth) > len("Bearer ") && strings.EqualFold(auth[:len("Bearer ")], "Bearer ") {
+			return strings.TrimSpace(auth[len("Bearer "):])
+		}
+	}
+	return ""
+}
+
+// peerIP は TCP 接続の相手の IP を返す。HTTP と同様にクライアントが申告する値は使わない。
+func peerIP(ctx context.Context) string {
+	p, ok := peer.FromContext(ctx)
+	if !ok || p.Addr == nil {
+		return ""
+	}
+	host, _, err := net.SplitHostPort(p.Addr.String())
+	if err != nil {
+		return p.Addr.String()
+	}
+	return host
+}
+
+// withRequestID はメタデータのリクエスト ID を引き継ぎ（無ければ採番し）、ヘッダで返してコンテキストへ格納する。
+func withRequestID(ctx context.Context) context.Context {
+	var requestID string
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		if values := md.Get(requestIDMetadataKey); len(values) > 0 {
+			requestID = values[0]
+		}
+	}
+	if requestID == "" || len(requestID) > maxRequestIDLength {
+		requestID = newRequestID()
+	}
+	_ = grpc.SetHeader(ctx, metadata.Pairs(requestIDMetadataKey, requestID))
+	return logging.WithRequestID(ctx, requestID)
+}
+
+func logRPC(ctx context.Context, method string, start time.Time, err error) {
+	st := status.Convert(err)
+	attrs := []any{
+		slog.String("rpc_method", method),
+		slog.String("code", st.Code().String()),
+		slog.Duration("latency", time.Since(start)),
+	}
+	level := slog.LevelInfo
+	if err != nil {
+		attrs = append(attrs, slog.String("error", st.Message()))
+		level = slog.LevelWarn
+		if st.Code() == codes.Internal {
+			level = slog.LevelError
+		}
+	}
+	slog.Log(ctx, level, "rpc completed", attrs...)
+}
+
+// contextStream はリクエスト ID を格納したコンテキストをハンドラへ渡すための ServerStream。
+type contextStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *contextStream) Context() context.Context {
+	return s.ctx
+}
+
+func newRequestID() string {
+	var b [16]byte
+	_, _ = rand.Read(b[:])
+	return hex.EncodeToString(b[:])
+}
@@ -0,0 +1,120 @@
+package grpcserver
+
+import (
+	"context"
+	"crypto/tls"
+	"net"
+
+	"github.com/2509-hackz-ichthyo/main/api/internal/server/httpserver"
+	wsdecodev1 "github.com/2509-hackz-ichthyo/main/api/pkg/pb/wsdecode/v1"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/reflection"
+)
+
+// ServiceName は WhitespaceService の完全修飾名。ヘルスチェックのサービス名にも用いる。
+const ServiceName = "wsdecode.v1.WhitespaceService"
+
+// Option は New の任意設定を表す。
+type Option func(*serverOptions)
+
+type serverOptions struct {
+	tls  *tls.Config
+	auth *httpserver.Authenticator
+}
+
+// WithTLS は gRPC サーバを TLS で待ち受ける。
+func WithTLS(cfg *tls.Config) Option {
+	return func(o *serverOptions) {
+		o.tls = cfg
+	}
+}
+
+// WithAuth は WhitespaceService の RPC に a による API キー認証とレート制限を適用する。
+// HTTP サーバと同じ a を渡すと、同じキー・同じ接続元 IP のトークンバケットを共有する。
+func WithAuth(a *httpserver.Authenticator) Option {
+	return func(o *serverOptions) {
+		o.auth = a
+	}
+}
+
+// Server は WhitespaceService とヘルスチェック・リフレクションを提供する gRPC サーバ。
+type Server struct {
+	grpc   *grpc.Server
+	health *health.Server
+}
+
+// New は gRPC サーバを生成し、サービスを登録する。
+// grpcurl などから利用できるよう、サーバリフレクションも有効にする。
+func New(uc httpserver.WhitespaceUsecase, opts ...Option) *Server {
+	var options serverOptions
+	for _, opt := range opts {
+		opt(&options)
+	}
+
+	unary := []grpc.UnaryServerInterceptor{unaryLogger}
+	stream := []grpc.StreamServerInterceptor{streamLogger}
+	if options.auth != nil {
+		// 拒否した RPC もログに残るよう、ロガーの内側で認証する
+		unary = append(unary, unaryAuth(options.auth))
+		stream = append(stream, streamAuth(options.auth))
+	}
+	serverOpts := []grpc.ServerOption{
+		grpc.ChainUnaryInterceptor(unary...),
+		grpc.ChainStreamInterceptor(stream...),
+	}
+	if options.tls != nil {
+		serverOpts = append(serverOpts, grpc.Creds(credentials.NewTLS(options.tls)))
+	}
+
+	s := &Server{
+		grpc:   grpc.NewServer(serverOpts...),
+		health: health.NewServer(),
+	}
+
+	wsdecodev1.RegisterWhitespaceServiceServer(s.grpc, &whitespaceService{uc: uc})
+	healthpb.RegisterHealthServer(s.grpc, s.health)
+	reflection.Register(s.grpc)
+
+	s.SetServing(true)
+	return s
+}
+
+// Serve は lis で接続の受け付けを開始する。Shutdown が呼ばれるまで戻らない。
+func (s *Server) Serve(lis net.Listener) error {
+	return s.grpc.Serve(lis)
+}
+
+// SetServing はヘルスチェックが返す状態を切り替える。
+// シャットダウン開始時に false とし、HTTP の /readyz と同様にクライアントへ停止を知らせる。
+func (s *Server) SetServing(serving bool) {
+	st := healthpb.HealthCheckResponse_SERVING
+	if !serving {
+		st = healthpb.HealthCheckResponse_NOT_SERVING
+	}
+	s.health.SetServingStatus("", st)
+	s.health.SetServingStatus(ServiceName, st)
+}
+
+// Shutdown は処理中の RPC の完了を待ってから停止する。
+// ctx の期限までに完了しない場合は接続を強制的に切断し、ctx のエラーを返す。
+func (s *Server) Shutdown(ctx context.Context) error {
+	s.SetServing(false)
+
+	done := make(chan struct{})
+	go func() {
+		s.grpc.GracefulStop()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		s.grpc.Stop()
+		<-done
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,251 @@
+package grpcserver
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/2509-hackz-ichthyo/main/api/internal/app"
+	"github.com/2509-hackz-ichthyo/main/api/internal/domain"
+	"github.com/2509-hackz-ichthyo/main/api/internal/server/httpserver"
+	wsdecodev1 "github.com/2509-hackz-ichthyo/main/api/pkg/pb/wsdecode/v1"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/metadata"
+	reflectionpb "google.golang.org/grpc/reflection/grpc_reflection_v1"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+// stubUsecase は命令種別が "fail" の場合にエラーを返し、それ以外はペイロードをそのまま結果に詰めて返す。
+type stubUsecase struct{}
+
+func (stubUsecase) Execute(_ context.Context, cmd app.WhitespaceCommand) (app.WhitespaceResult, error) {
+	if cmd.CommandType == "fail" {
+		return app.WhitespaceResult{}, fmt.Errorf("%w: bad sentence", domain.ErrInvalidPayload)
+	}
+	return app.WhitespaceResult{
+		CommandType:    domain.CommandType(cmd.CommandType),
+		ResultKind:     domain.ResultKindDecimalSequence,
+		ResultDecimals: cmd.Payload,
+	}, nil
+}
+
+func newTestClient(t *testing.T, opts ...Option) (*Server, *grpc.ClientConn) {
+	t.Helper()
+
+	srv := New(stubUsecase{}, opts...)
+	lis := bufconn.Listen(1 << 20)
+	go func() { _ = srv.Serve(lis) }()
+	t.Cleanup(func() { _ = srv.Shutdown(context.Background()) })
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return lis.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	if err != nil {
+		t.Fatalf("failed to dial: %v", err)
+	}
+	t.Cleanup(func() { _ = conn.Close() })
+	return srv, conn
+}
+
+func TestDecode(t *testing.T) {
+	_, conn := newTestClient(t)
+	client := wsdecodev1.NewWhitespaceServiceClient(conn)
+
+	var header metadata.MD
+	ctx := metadata.AppendToOutgoingContext(context.Background(), "x-request-id", "tooling-1")
+	resp, err := client.Decode(ctx, &wsdecodev1.DecodeRequest{CommandType: "WhitespaceToDecimal", Payload: []string{"1 2 3"}}, grpc.Header(&header))
+	if err != nil {
+		t.Fatalf("Decode() error = %v", err)
+	}
+
+	if resp.GetCommandType() != "WhitespaceToDecimal" || len(resp.GetResultDecimals()) != 1 || resp.GetResultDecimals()[0] != "1 2 3" {
+		t.Fatalf("unexpected response: %+v", resp)
+	}
+	if got := header.Get("x-request-id"); len(got) != 1 || got[0] != "tooling-1" {
+		t.Fatalf("x-request-id header = %v, want tooling-1", got)
+	}
+
+	_, err = client.Decode(context.Background(), &wsdecodev1.DecodeRequest{CommandType: "fail", Payload: []string{"x"}})
+	if status.Code(err) != codes.InvalidArgument {
+		t.Fatalf("Decode() code = %v, want InvalidArgument", status.Code(err))
+	}
+}
+
+func TestBatchDecode(t *testing.T) {
+	_, conn := newTestClient(t)
+	client := wsdecodev1.NewWhitespaceServiceClient(conn)
+
+	resp, err := client.BatchDecode(context.Background(), &wsdecodev1.BatchDecodeRequest{Requests: []*wsdecodev1.DecodeRequest{
+		{CommandType: "WhitespaceToDecimal", Payload: []string{"1 2 3"}},
+		{CommandType: "fail", Payload: []string{"x"}},
+	}})
+	if err != nil {
+		t.Fatalf("BatchDecode() error = %v", err)
+	}
+
+	results := resp.GetResults()
+	if len(results) != 2 {
+		t.Fatalf("len(results) = %d, want 2", len(results))
+	}
+	if results[0].GetResponse() == nil || results[0].GetIndex() != 0 {
+		t.Fatalf("results[0] = %+v, want response", results[0])
+	}
+	if results[1].GetError().GetCode() != codes.InvalidArgument.String() || results[1].GetIndex() != 1 {
+		t.Fatalf("results[1] = %+v, want INVALID_ARGUMENT error", results[1])
+	}
+
+	_, err = client.BatchDecode(context.Background(), &wsdecodev1.BatchDecodeRequest{})
+	if status.Code(err) != codes.InvalidArgument {
+		t.Fatalf("BatchDecode(empty) code = %v, want InvalidArgument", status.Code(err))
+	}
+}
+
+func TestDecodeStream(t *testing.T) {
+	_, conn := newTestClient(t)
+	client := wsdecodev1.NewWhitespaceServiceClient(conn)
+
+	stream, err := client.DecodeStream(context.Background(), &wsdecodev1.BatchDecodeRequest{Requests: []*wsdecodev1.DecodeRequest{
+		{CommandType: "WhitespaceToDecimal", Payload: []string{"1 2 3"}},
+		{CommandType: "fail", Payload: []string{"x"}},
+		{CommandType: "WhitespaceToDecimal", Payload: []string{"4 5 6"}},
+	}})
+	if err != nil {
+		t.Fatalf("DecodeStream() error = %v", err)
+	}
+
+	var indexes []uint32
+	for {
+		result, err := stream.Recv()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			t.Fatalf("Recv() error = %v", err)
+		}
+		indexes = append(indexes, result.GetIndex())
+	}
+
+	if len(indexes) != 3 || indexes[0] != 0 || indexes[1] != 1 || indexes[2] != 2 {
+		t.Fatalf("received indexes = %v, want [0 1 2]", indexes)
+	}
+}
+
+func TestAuth(t *testing.T) {
+	auth := httpserver.NewAuthenticator(httpserver.AuthConfig{
+		Keys: []httpserver.APIKey{{Client: "game-lambda", Key: "secret", Rate: 0.5, Burst: 1}},
+	})
+	_, conn := newTestClient(t, WithAuth(auth))
+	client := wsdecodev1.NewWhitespaceServiceClient(conn)
+	req := &wsdecodev1.DecodeRequest{CommandType: "WhitespaceToDecimal", Payload: []string{"1 2 3"}}
+
+	if _, err := client.Decode(context.Background(), req); status.Code(err) != codes.Unauthenticated {
+		t.Fatalf("Decode() without key code = %v, want Unauthenticated", status.Code(err))
+	}
+
+	bad := metadata.AppendToOutgoingContext(context.Background(), "x-api-key", "wrong")
+	if _, err := client.Decode(bad, req); status.Code(err) != codes.Unauthenticated {
+		t.Fatalf("Decode() with wrong key code = %v, want Unauthenticated", status.Code(err))
+	}
+
+	ctx := metadata.AppendToOutgoingContext(context.Background(), "authorization", "Bearer secret")
+	if _, err := client.Decode(ctx, req); err != nil {
+		t.Fatalf("Decode() with key error = %v", err)
+	}
+
+	var trailer metadata.MD
+	_, err := client.Decode(ctx, req, grpc.Trailer(&trailer))
+	if status.Code(err) != codes.ResourceExhausted {
+		t.Fatalf("Decode() over limit code = %v, want ResourceExhausted", status.Code(err))
+	}
+	if got := trailer.Get("retry-after"); len(got) != 1 || got[0] != "2" {
+		t.Fatalf("retry-after trailer = %v, want 2", got)
+	}
+
+	// ストリームも同じバケットを消費するため、制限を超えたまま拒否される
+	stream, err := client.DecodeStream(ctx, &wsdecodev1.BatchDecodeRequest{Requests: []*wsdecodev1.DecodeRequest{req}})
+	if err == nil {
+		_, err = stream.Recv()
+	}
+	if status.Code(err) != codes.ResourceExhausted {
+		t.Fatalf("DecodeStream() over limit code = %v, want ResourceExhausted", status.Code(err))
+	}
+
+	// ヘルスチェックは認証の対象外
+	if _, err := healthpb.NewHealthClient(conn).Check(context.Background(), &healthpb.HealthCheckRequest{}); err != nil {
+		t.Fatalf("Check() without key error = %v", err)
+	}
+}
+
+func TestHealthAndReflection(t *testing.T) {
+	srv, conn := newTestClient(t)
+	health := healthpb.NewHealthClient(conn)
+
+	resp, err := health.Check(context.Background(), &healthpb.HealthCheckRequest{Service: ServiceName})
+	if err != nil {
+		t.Fatalf("Check() error = %v", err)
+	}
+	if resp.GetStatus() != healthpb.HealthCheckResponse_SERVING {
+		t.Fatalf("status = %v, want SERVING", resp.GetStatus())
+	}
+
+	srv.SetServing(false)
+	resp, err = health.Check(context.Background(), &healthpb.HealthCheckRequest{})
+	if err != nil {
+		t.Fatalf("Check() error = %v", err)
+	}
+	if resp.GetStatus() != healthpb.HealthCheckResponse_NOT_SERVING {
+		t.Fatalf("status = %v, want NOT_SERVING", resp.GetStatus())
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	stream, err := reflectionpb.NewServerReflectionClient(conn).ServerReflectionInfo(ctx)
+	if err != nil {
+		t.Fatalf("ServerReflectionInfo() error = %v", err)
+	}
+	if err := stream.Send(&reflectionpb.ServerReflectionRequest{
+		MessageRequest: &reflectionpb.ServerReflectionRequest_ListServices{},
+	}); err != nil {
+		t.Fatalf("Send() error = %v", err)
+	}
+	reply, err := stream.Recv()
+	if err != nil {
+		t.Fatalf("Recv() error = %v", err)
+	}
+
+	found := false
+	for _, svc := range reply.GetListServicesResponse().GetService() {
+		if svc.GetName() == ServiceName {
+			found = true
+		}
+	}
+	if !found {
+		t.Fatalf("reflection does not list %s: %v", ServiceName, reply.GetListServicesResponse().GetService())
+	}
+}
+
+func TestStatusFromError(t *testing.T) {
+	cases := map[error]codes.Code{
+		app.ErrValidationFailed:                        codes.InvalidArgument,
+		domain.ErrInvalidCommandType:                   codes.InvalidArgument,
+		app.ErrEnvelopeVerificationFailed:              codes.InvalidArgument,
+		app.ErrEnvelopeKeyNotConfigured:                codes.FailedPrecondition,
+		context.DeadlineExceeded:                       codes.DeadlineExceeded,
+		errors.New("unexpected"):                       codes.Internal,
+		fmt.Errorf("wrap: %w", domain.ErrTypeMismatch): codes.InvalidArgument,
+	}
+	for err, want := range cases {
+		if got := statusFromError(err).Code(); got != want {
+			t.Fatalf("statusFromError(%v) = %v, want %v", err, got, want)
+		}
+	}
+}
@@ -0,0 +1,134 @@
+package grpcserver
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/2509-hackz-ichthyo/main/api/internal/app"
+	"github.com/2509-hackz-ichthyo/main/api/internal/domain"
+	"github.com/2509-hackz-ichthyo/main/api/internal/server/httpserver"
+	wsdecodev1 "github.com/2509-hackz-ichthyo/main/api/pkg/pb/wsdecode/v1"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// maxBatchSize は BatchDecode / DecodeStream で 1 回に受け付ける命令数の上限。
+const maxBatchSize = 1000
+
+// whitespaceService は wsdecodev1.WhitespaceServiceServer を HTTP API と同じユースケースで実装する。
+type whitespaceService struct {
+	wsdecodev1.UnimplementedWhitespaceServiceServer
+
+	uc httpserver.WhitespaceUsecase
+}
+
+func (s *whitespaceService) Decode(ctx context.Context, req *wsdecodev1.DecodeRequest) (*wsdecodev1.DecodeResponse, error) {
+	resp, err := s.decode(ctx, req)
+	if err != nil {
+		return nil, statusFromError(err).Err()
+	}
+	return resp, nil
+}
+
+func (s *whitespaceService) BatchDecode(ctx context.Context, req *wsdecodev1.BatchDecodeRequest) (*wsdecodev1.BatchDecodeResponse, error) {
+	if err := validateBatch(req); err != nil {
+		return nil, err
+	}
+
+	results := make([]*wsdecodev1.BatchDecodeResult, len(req.GetRequests()))
+	for i, item := range req.GetRequests() {
+		if err := ctx.Err(); err != nil {
+			return nil, status.FromContextError(err).Err()
+		}
+		results[i] = s.decodeItem(ctx, i, item)
+	}
+	return &wsdecodev1.BatchDecodeResponse{Results: results}, nil
+}
+
+func (s *whitespaceService) DecodeStream(req *wsdecodev1.BatchDecodeRequest, stream wsdecodev1.WhitespaceService_DecodeStreamServer) error {
+	if err := validateBatch(req); err != nil {
+		return err
+	}
+
+	ctx := stream.Context()
+	for i, item := range req.GetRequests() {
+		if err := ctx.Err(); err != nil {
+			return status.FromContextError(err).Err()
+		}
+		if err := stream.Send(s.decodeItem(ctx, i, item)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *whitespaceService) decode(ctx context.Context, req *wsdecodev1.DecodeRequest) (*wsdecodev1.DecodeResponse, error) {
+	result, err := s.uc.Execute(ctx, app.WhitespaceCommand{
+		CommandType: req.GetCommandType(),
+		Payload:     req.GetPayload(),
+	})
+	if err != nil {
+		return nil, err
+	}
+	return newDecodeResponse(result), nil
+}
+
+// decodeItem はバッチ内の 1 件を変換し、失敗した場合は結果の error に格納する。
+func (s *whitespaceService) decodeItem(ctx context.Context, index int, req *wsdecodev1.DecodeRequest) *wsdecodev1.BatchDecodeResult {
+	result := &wsdecodev1.BatchDecodeResult{Index: uint32(index)}
+
+	resp, err := s.decode(ctx, req)
+	if err != nil {
+		st := statusFromError(err)
+		result.Outcome = &wsdecodev1.BatchDecodeResult_Error{Error: &wsdecodev1.DecodeError{
+			Code:    st.Code().String(),
+			Message: st.Message(),
+		}}
+		return result
+	}
+
+	result.Outcome = &wsdecodev1.BatchDecodeResult_Response{Response: resp}
+	return result
+}
+
+func validateBatch(req *wsdecodev1.BatchDecodeRequest) error {
+	switch n := len(req.GetRequests()); {
+	case n == 0:
+		return status.Error(codes.InvalidArgument, "requests must not be empty")
+	case n > maxBatchSize:
+		return status.Error(codes.InvalidArgument, fmt.Sprintf("requests must not exceed %d items, got %d", maxBatchSize, n))
+	}
+	return nil
+}
+
+func newDecodeResponse(result app.WhitespaceResult) *wsdecodev1.DecodeResponse {
+	return &wsdecodev1.DecodeResponse{
+		CommandType:                    string(result.CommandType),
+		ResultKind:                     string(result.ResultKind),
+		ResultDecimals:                 result.ResultDecimals,
+		ResultBinaries:                 result.ResultBinaries,
+		ResultWhitespace:               result.ResultWhitespace,
+		ResultWhitespacePercentEncoded: result.ResultWhitespaceEncoded,
+	}
+}
+
+// statusFromError はユースケース層から返却されたエラーを gRPC のステータスへ写像する。
+// 対応関係は HTTP API の handleUsecaseError に揃える。
+func statusFromError(err error) *status.Status {
+	switch {
+	case errors.Is(err, app.ErrValidationFailed),
+		errors.Is(err, domain.ErrInvalidPayload),
+		errors.Is(err, domain.ErrInvalidCommandType),
+		errors.Is(err, app.ErrEnvelopeVerificationFailed),
+		errors.Is(err, domain.ErrInvalidPayloadFormat),
+		errors.Is(err, domain.ErrTypeMismatch):
+		return status.New(codes.InvalidArgument, err.Error())
+	case errors.Is(err, app.ErrEnvelopeKeyNotConfigured):
+		return status.New(codes.FailedPrecondition, err.Error())
+	case errors.Is(err, context.Canceled), errors.Is(err, context.DeadlineExceeded):
+		return status.FromContextError(err)
+	default:
+		return status.New(codes.Internal, "内部エラーが発生しました")
+	}
+}
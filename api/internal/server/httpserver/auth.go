@@ -0,0 +1,241 @@
+package httpserver
+
+import (
+	"crypto/sha256"
+	"errors"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"golang.org/x/time/rate"
+)
+
+// APIKeyHeader は API キーを受け取る HTTP ヘッダ名。Authorization: Bearer でも受け付ける。
+const APIKeyHeader = "X-API-Key"
+
+// clientKey は認証済みクライアントの識別子を gin.Context に格納するキー。
+const clientKey = "httpserver.client"
+
+// limiterIdleTTL を超えて利用されていないレートリミッタは破棄する。
+const limiterIdleTTL = 10 * time.Minute
+
+var (
+	// ErrMissingAPIKey はキーが設定されているのにリクエストが API キーを含まないことを示す。
+	ErrMissingAPIKey = errors.New("httpserver: missing API key")
+
+	// ErrInvalidAPIKey はリクエストの API キーがどのクライアントにも一致しないことを示す。
+	ErrInvalidAPIKey = errors.New("httpserver: invalid API key")
+
+	// ErrRateLimited は API キー単位または接続元 IP 単位のトークンバケットが空であることを示す。
+	ErrRateLimited = errors.New("httpserver: rate limit exceeded")
+)
+
+// APIKey は 1 クライアント分の API キーと、そのキーに適用するレート制限を表す。
+// Rate / Burst が 0 の場合は AuthConfig の既定値を用いる。
+type APIKey struct {
+	Client string
+	Key    string
+	Rate   float64
+	Burst  int
+}
+
+// AuthConfig は API キー認証とレート制限の設定を表す。
+// Keys が空の場合は認証を行わず、IP 単位のレート制限のみを適用する。
+type AuthConfig struct {
+	Keys []APIKey
+
+	// KeyRate / KeyBurst は API キー単位のトークンバケットの既定値（毎秒の補充数・容量）。
+	KeyRate  float64
+	KeyBurst int
+
+	// IPRate / IPBurst は接続元 IP 単位のトークンバケット。有効なキーを持つリクエストには適用しない。
+	// IPRate が 0 の場合は制限しない。
+	IPRate  float64
+	IPBurst int
+}
+
+// Client はリクエストを送信した認証済みクライアントの識別子を返す。
+func Client(c *gin.Context) (string, bool) {
+	client := c.GetString(clientKey)
+	return client, client != ""
+}
+
+type apiKeyEntry struct {
+	client  string
+	limiter *rate.Limiter
+}
+
+// Authenticator は API キーの検証とトークンバケットによるレート制限を行う。
+type Authenticator struct {
+	keys map[[sha256.Size]byte]apiKeyEntry
+	ips  *limiterSet
+	now  func() time.Time
+}
+
+// NewAuthenticator は cfg のキーごとにトークンバケットを用意した Authenticator を生成する。
+func NewAuthenticator(cfg AuthConfig) *Authenticator {
+	a := &Authenticator{
+		keys: make(map[[sha256.Size]byte]apiKeyEntry, len(cfg.Keys)),
+		now:  time.Now,
+	}
+
+	for _, key := range cfg.Keys {
+		r, burst := key.Rate, key.Burst
+		if r == 0 {
+			r = cfg.KeyRate
+		}
+		if burst == 0 {
+			burst = cfg.KeyBurst
+		}
+		// キーはハッシュ値で引くことで、比較時間からキーを推測されないようにする。
+		a.keys[sha256.Sum256([]byte(key.Key))] = apiKeyEntry{
+			client:  key.Client,
+			limiter: newLimiter(r, burst),
+		}
+	}
+
+	if cfg.IPRate > 0 {
+		a.ips = &limiterSet{rate: cfg.IPRate, burst: cfg.IPBurst, entries: map[string]*limiterEntry{}}
+	}
+
+	return a
+}
+
+func newLimiter(r float64, burst int) *rate.Limiter {
+	if r <= 0 {
+		return rate.NewLimiter(rate.Inf, 0)
+	}
+	return rate.NewLimiter(rate.Limit(r), max(burst, 1))
+}
+
+// Authorize は API キーを検証し、有効なキーにはキー単位の制限だけを適用して認証済みクライアントの識別子を返す。
+// キーが無い・不正な場合や、キーが設定されていない場合は IP 単位の制限を適用する。
+// 有効なキーを IP 単位の制限から外すことで、高い rate を割り当てたキーが同じ IP からでも上限まで使える。
+// 失敗した場合は ErrMissingAPIKey・ErrInvalidAPIKey・ErrRateLimited のいずれかを返す。
+// ErrRateLimited の場合は次のトークンが補充されるまでの時間（1 秒以上）も返す。
+// HTTP のミドルウェアと gRPC のインターセプタで共有し、同じキーには同じトークンバケットを適用する。
+func (a *Authenticator) Authorize(ip, key string) (client string, retryAfter time.Duration, err error) {
+	now := a.now()
+
+	if len(a.keys) > 0 && key != "" {
+		if entry, ok := a.keys[sha256.Sum256([]byte(key))]; ok {
+			if wait, ok := take(entry.limiter, now); !ok {
+				return entry.client, wait, ErrRateLimited
+			}
+			return entry.client, 0, nil
+		}
+	}
+
+	// キーの総当たりや未認証のリクエストは接続元 IP 単位で抑える。
+	if a.ips != nil {
+		if wait, ok := take(a.ips.get(ip, now), now); !ok {
+			return "", wait, ErrRateLimited
+		}
+	}
+
+	switch {
+	case len(a.keys) == 0:
+		return "", 0, nil
+	case key == "":
+		return "", 0, ErrMissingAPIKey
+	default:
+		return "", 0, ErrInvalidAPIKey
+	}
+}
+
+// middleware は Authorize の結果を HTTP の応答に変換する。
+func (a *Authenticator) middleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		client, retryAfter, err := a.Authorize(c.ClientIP(), extractAPIKey(c.Request))
+		switch {
+		case errors.Is(err, ErrMissingAPIKey):
+			c.Header("WWW-Authenticate", `Bearer realm="ws-decode-api"`)
+			writeError(c, http.StatusUnauthorized, "API キーが指定されていません", err)
+			c.Abort()
+			return
+		case errors.Is(err, ErrInvalidAPIKey):
+			c.Header("WWW-Authenticate", `Bearer realm="ws-decode-api", error="invalid_token"`)
+			writeError(c, http.StatusUnauthorized, "API キーが不正です", err)
+			c.Abort()
+			return
+		case errors.Is(err, ErrRateLimited):
+			c.Header("Retry-After", strconv.Itoa(int(retryAfter.Seconds())))
+			writeError(c, http.StatusTooManyRequests, "リクエストが多すぎます", err)
+			c.Abort()
+			return
+		}
+
+		if client != "" {
+			c.Set(clientKey, client)
+		}
+		c.Next()
+	}
+}
+
+// take はトークンを 1 つ消費できれば true を返す。
+// 消費できない場合は次のトークンが補充されるまでの時間（秒単位に切り上げ、1 秒以上）を返す。
+func take(limiter *rate.Limiter, now time.Time) (time.Duration, bool) {
+	reservation := limiter.ReserveN(now, 1)
+	delay := reservation.DelayFrom(now)
+	if reservation.OK() && delay == 0 {
+		return 0, true
+	}
+	reservation.CancelAt(now)
+
+	retryAfter := int(math.Ceil(delay.Seconds()))
+	if !reservation.OK() || retryAfter < 1 {
+		retryAfter = 1
+	}
+	return time.Duration(retryAfter) * time.Second, false
+}
+
+func extractAPIKey(r *http.Request) string {
+	if key := strings.TrimSpace(r.Header.Get(APIKeyHeader)); key != "" {
+		return key
+	}
+	if auth := r.Header.Get("Authorization"); len(auth) > len("Bearer ") && strings.EqualFold(auth[:len("Bearer ")], "Bearer ") {
+		return strings.TrimSpace(auth[len("Bearer "):])
+	}
+	return ""
+}
+
+// limiterSet は IP アドレスなど動的に増えるキーごとのレートリミッタを保持する。
+type limiterSet struct {
+	rate  float64
+	burst int
+
+	mu        sync.Mutex
+	entries   map[string]*limiterEntry
+	lastSweep time.Time
+}
+
+type limiterEntry struct {
+	limiter  *rate.Limiter
+	lastSeen time.Time
+}
+
+func (s *limiterSet) get(key string, now time.Time) *rate.Limiter {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if now.Sub(s.lastSweep) > limiterIdleTTL {
+		for k, entry := range s.entries {
+			if now.Sub(entry.lastSeen) > limiterIdleTTL {
+				delete(s.entries, k)
+			}
+		}
+		s.lastSweep = now
+	}
+
+	entry, ok := s.entries[key]
+	if !ok {
+		entry = &limiterEntry{limiter: newLimiter(s.rate, s.burst)}
+		s.entries[key] = entry
+	}
+	entry.lastSeen = now
+	return entry.limiter
+}
@@ -0,0 +1,204 @@
+package httpserver
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+func newAuthTestRouter(cfg AuthConfig) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	return NewRouter(&stubUsecase{}, WithAuth(cfg))
+}
+
+func decodeRequestWithKey(header, value string) *http.Request {
+	req := httptest.NewRequest(http.MethodPost, "/v1/decode", bytes.NewBufferString(`{"command_type":"DecimalToWhitespace","payload":["0 0 0"]}`))
+	req.Header.Set("Content-Type", "application/json")
+	req.RemoteAddr = "192.0.2.1:1234"
+	if header != "" {
+		req.Header.Set(header, value)
+	}
+	return req
+}
+
+func TestAuth_APIKey(t *testing.T) {
+	r := newAuthTestRouter(AuthConfig{
+		Keys:     []APIKey{{Client: "game-lambda", Key: "secret"}},
+		KeyRate:  100,
+		KeyBurst: 100,
+	})
+
+	cases := []struct {
+		name   string
+		header string
+		value  string
+		want   int
+	}{
+		{name: "missing", want: http.StatusUnauthorized},
+		{name: "invalid", header: APIKeyHeader, value: "wrong", want: http.StatusUnauthorized},
+		{name: "header", header: APIKeyHeader, value: "secret", want: http.StatusOK},
+		{name: "bearer", header: "Authorization", value: "Bearer secret", want: http.StatusOK},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			r.ServeHTTP(rec, decodeRequestWithKey(tc.header, tc.value))
+
+			if rec.Code != tc.want {
+				t.Fatalf("status = %d, want %d", rec.Code, tc.want)
+			}
+		})
+	}
+}
+
+func TestAuth_HealthzIsNotProtected(t *testing.T) {
+	r := newAuthTestRouter(AuthConfig{Keys: []APIKey{{Client: "c", Key: "k"}}})
+
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/healthz", nil))
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want %d", rec.Code, http.StatusOK)
+	}
+}
+
+func TestAuth_KeyRateLimit(t *testing.T) {
+	r := newAuthTestRouter(AuthConfig{
+		Keys:     []APIKey{{Client: "web", Key: "low"}, {Client: "game-lambda", Key: "high", Rate: 100, Burst: 3}},
+		KeyRate:  1,
+		KeyBurst: 1,
+	})
+
+	for i, want := range []int{http.StatusOK, http.StatusTooManyRequests} {
+		rec := httptest.NewRecorder()
+		r.ServeHTTP(rec, decodeRequestWithKey(APIKeyHeader, "low"))
+		if rec.Code != want {
+			t.Fatalf("request %d status = %d, want %d", i, rec.Code, want)
+		}
+		if want == http.StatusTooManyRequests && rec.Header().Get("Retry-After") != "1" {
+			t.Fatalf("Retry-After = %q, want %q", rec.Header().Get("Retry-After"), "1")
+		}
+	}
+
+	for i := 0; i < 3; i++ {
+		rec := httptest.NewRecorder()
+		r.ServeHTTP(rec, decodeRequestWithKey(APIKeyHeader, "high"))
+		if rec.Code != http.StatusOK {
+			t.Fatalf("high quota request %d status = %d, want %d", i, rec.Code, http.StatusOK)
+		}
+	}
+}
+
+func TestAuth_HighQuotaKeyNotCappedByIPLimit(t *testing.T) {
+	r := newAuthTestRouter(AuthConfig{
+		Keys:    []APIKey{{Client: "game-lambda", Key: "high", Rate: 100, Burst: 5}},
+		IPRate:  0.5,
+		IPBurst: 1,
+	})
+
+	// 同じ IP からでも、有効なキーはキー単位のバーストまで通る
+	for i := 0; i < 5; i++ {
+		rec := httptest.NewRecorder()
+		r.ServeHTTP(rec, decodeRequestWithKey(APIKeyHeader, "high"))
+		if rec.Code != http.StatusOK {
+			t.Fatalf("keyed request %d status = %d, want %d", i, rec.Code, http.StatusOK)
+		}
+	}
+
+	// 不正なキーは IP 単位の制限を受ける
+	for i, want := range []int{http.StatusUnauthorized, http.StatusTooManyRequests} {
+		rec := httptest.NewRecorder()
+		r.ServeHTTP(rec, decodeRequestWithKey(APIKeyHeader, "wrong"))
+		if rec.Code != want {
+			t.Fatalf("invalid key request %d status = %d, want %d", i, rec.Code, want)
+		}
+	}
+}
+
+func TestAuth_IPRateLimitWithoutKeys(t *testing.T) {
+	r := newAuthTestRouter(AuthConfig{IPRate: 0.5, IPBurst: 1})
+
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, decodeRequestWithKey("", ""))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("first request status = %d, want %d", rec.Code, http.StatusOK)
+	}
+
+	rec = httptest.NewRecorder()
+	r.ServeHTTP(rec, decodeRequestWithKey("", ""))
+	if rec.Code != http.StatusTooManyRequests {
+		t.Fatalf("second request status = %d, want %d", rec.Code, http.StatusTooManyRequests)
+	}
+	if got := rec.Header().Get("Retry-After"); got != "2" {
+		t.Fatalf("Retry-After = %q, want %q", got, "2")
+	}
+
+	other := decodeRequestWithKey("", "")
+	other.RemoteAddr = "198.51.100.7:1234"
+	rec = httptest.NewRecorder()
+	r.ServeHTTP(rec, other)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("other IP status = %d, want %d", rec.Code, http.StatusOK)
+	}
+}
+
+func TestAuth_IPRateLimitIgnoresForwardedFor(t *testing.T) {
+	r := newAuthTestRouter(AuthConfig{IPRate: 0.5, IPBurst: 1})
+
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, decodeRequestWithKey("", ""))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("first request status = %d, want %d", rec.Code, http.StatusOK)
+	}
+
+	// 信頼するプロキシを指定していないため、クライアントが付けたヘッダで別の IP にはならない
+	spoofed := decodeRequestWithKey("X-Forwarded-For", "203.0.113.9")
+	rec = httptest.NewRecorder()
+	r.ServeHTTP(rec, spoofed)
+	if rec.Code != http.StatusTooManyRequests {
+		t.Fatalf("spoofed X-Forwarded-For status = %d, want %d", rec.Code, http.StatusTooManyRequests)
+	}
+}
+
+func TestAuth_IPRateLimitTrustedProxy(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	r := NewRouter(&stubUsecase{}, WithAuth(AuthConfig{IPRate: 0.5, IPBurst: 1}), WithTrustedProxies([]string{"192.0.2.0/24"}))
+
+	for _, client := range []string{"203.0.113.9", "203.0.113.10"} {
+		rec := httptest.NewRecorder()
+		r.ServeHTTP(rec, decodeRequestWithKey("X-Forwarded-For", client))
+		if rec.Code != http.StatusOK {
+			t.Fatalf("request from %s via trusted proxy status = %d, want %d", client, rec.Code, http.StatusOK)
+		}
+	}
+}
+
+func TestAuth_AttachesClientIdentity(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	a := NewAuthenticator(AuthConfig{Keys: []APIKey{{Client: "game-lambda", Key: "secret"}}})
+
+	ctx, _ := newTestContext()
+	ctx.Request = decodeRequestWithKey(APIKeyHeader, "secret")
+	a.middleware()(ctx)
+
+	if client, ok := Client(ctx); !ok || client != "game-lambda" {
+		t.Fatalf("Client() = (%q, %v), want (game-lambda, true)", client, ok)
+	}
+}
+
+func TestLimiterSetEvictsIdleEntries(t *testing.T) {
+	set := &limiterSet{rate: 1, burst: 1, entries: map[string]*limiterEntry{}}
+	start := time.Now()
+
+	set.get("192.0.2.1", start)
+	set.get("192.0.2.2", start.Add(limiterIdleTTL+time.Second))
+
+	if len(set.entries) != 1 {
+		t.Fatalf("entries = %d, want 1", len(set.entries))
+	}
+}
@@ -0,0 +1,125 @@
+package httpserver
+
+import (
+	"net/http"
+
+	"github.com/2509-hackz-ichthyo/main/api/internal/app"
+	"github.com/2509-hackz-ichthyo/main/api/internal/domain"
+	"github.com/gin-gonic/gin"
+)
+
+func diffHandler(uc DiffUsecase) gin.HandlerFunc {
+	// diffHandler は POST /v1/diff に届いた 2 つのペイロードを正規化し、ユースケースへ委譲する。
+	return func(c *gin.Context) {
+		var req diffRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			writeError(c, http.StatusBadRequest, "リクエストボディの形式が不正です", err)
+			return
+		}
+
+		left, err := normalizeOperand(req.Left)
+		if err != nil {
+			handleUsecaseError(c, err)
+			return
+		}
+		right, err := normalizeOperand(req.Right)
+		if err != nil {
+			handleUsecaseError(c, err)
+			return
+		}
+
+		result, err := uc.Diff(c.Request.Context(), app.DiffCommand{Left: left, Right: right})
+		if err != nil {
+			handleUsecaseError(c, err)
+			return
+		}
+
+		c.JSON(http.StatusOK, newDiffResponse(result))
+	}
+}
+
+// diffRequest は POST /v1/diff のリクエストボディ。
+type diffRequest struct {
+	Left  diffOperand `json:"left"`
+	Right diffOperand `json:"right"`
+}
+
+// diffOperand は比較対象の片側を表す。format は Whitespace / Binary / Decimal のいずれか。
+type diffOperand struct {
+	Format  string     `json:"format"`
+	Payload stringList `json:"payload"`
+}
+
+// diffResponse は差分結果のレスポンスボディ。
+type diffResponse struct {
+	Identical  bool                   `json:"identical"`
+	LeftCount  int                    `json:"left_count"`
+	RightCount int                    `json:"right_count"`
+	Sentences  []sentenceDiffResponse `json:"sentences"`
+}
+
+// sentenceDiffResponse は差分のある 1 文。文が存在しない側の番号と内容は省略する。
+type sentenceDiffResponse struct {
+	LeftIndex  *int                  `json:"left_index,omitempty"`
+	RightIndex *int                  `json:"right_index,omitempty"`
+	Status     string                `json:"status"`
+	Before     *string               `json:"before,omitempty"`
+	After      *string               `json:"after,omitempty"`
+	Segments   []segmentDiffResponse `json:"segments,omitempty"`
+}
+
+type segmentDiffResponse struct {
+	Segment      int   `json:"segment"`
+	BitPositions []int `json:"bit_positions"`
+	Before       int   `json:"before"`
+	After        int   `json:"after"`
+}
+
+func normalizeOperand(operand diffOperand) (app.DiffOperand, error) {
+	format, err := domain.ParsePayloadFormat(operand.Format)
+	if err != nil {
+		return app.DiffOperand{}, err
+	}
+
+	payload := make([]string, len(operand.Payload))
+	for i, value := range operand.Payload {
+		payload[i], err = app.NormalizeValue(format, value)
+		if err != nil {
+			return app.DiffOperand{}, err
+		}
+	}
+
+	return app.DiffOperand{Format: string(format), Payload: payload}, nil
+}
+
+func newDiffResponse(result app.DiffResult) diffResponse {
+	resp := diffResponse{
+		Identical:  result.Identical,
+		LeftCount:  result.LeftCount,
+		RightCount: result.RightCount,
+		Sentences:  make([]sentenceDiffResponse, len(result.Sentences)),
+	}
+
+	for i, sentence := range result.Sentences {
+		s := sentenceDiffResponse{Status: string(sentence.Status)}
+		if sentence.Status != app.SentenceDiffAdded {
+			leftIndex, before := sentence.LeftIndex, sentence.Before
+			s.LeftIndex, s.Before = &leftIndex, &before
+		}
+		if sentence.Status != app.SentenceDiffRemoved {
+			rightIndex, after := sentence.RightIndex, sentence.After
+			s.RightIndex, s.After = &rightIndex, &after
+		}
+		for _, segment := range sentence.Segments {
+			s.Segments = append(s.Segments, segmentDiffResponse{
+				Segment:      segment.Segment,
+				BitPositions: segment.BitPositions,
+				Before:       segment.Before,
+				After:        segment.After,
+			})
+		}
+		resp.Sentences[i] = s
+	}
+
+	return resp
+}
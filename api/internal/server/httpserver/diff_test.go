@@ -0,0 +1,122 @@
+package httpserver
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/2509-hackz-ichthyo/main/api/internal/app"
+	"github.com/gin-gonic/gin"
+)
+
+type stubDiffUsecase struct {
+	result          app.DiffResult
+	err             error
+	receivedCommand app.DiffCommand
+}
+
+func (s *stubDiffUsecase) Diff(_ context.Context, cmd app.DiffCommand) (app.DiffResult, error) {
+	s.receivedCommand = cmd
+	return s.result, s.err
+}
+
+func TestDiffHandler_Success(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	diff := &stubDiffUsecase{
+		result: app.DiffResult{
+			LeftCount:  1,
+			RightCount: 2,
+			Sentences: []app.SentenceDiff{
+				{
+					LeftIndex:  0,
+					RightIndex: 0,
+					Status:     app.SentenceDiffChanged,
+					Before:     "0 0 0",
+					After:      "0 0 1",
+					Segments: []app.SegmentDiff{
+						{Segment: 2, BitPositions: []int{7}, Before: 0, After: 1},
+					},
+				},
+				{LeftIndex: -1, RightIndex: 1, Status: app.SentenceDiffAdded, After: "1 1 1"},
+			},
+		},
+	}
+	r := NewRouter(&stubUsecase{}, WithDiffUsecase(diff))
+
+	payload := `{"left":{"format":"Whitespace","payload":["%20%20%20%20%0A"]},"right":{"format":"Decimal","payload":[" 0 0 1 ","1 1 1"]}}`
+	req := httptest.NewRequest(http.MethodPost, "/v1/diff", bytes.NewBufferString(payload))
+	req.Header.Set("Content-Type", "application/json")
+	rec := httptest.NewRecorder()
+
+	r.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want %d", rec.Code, http.StatusOK)
+	}
+
+	if got := diff.receivedCommand.Left.Payload[0]; got != "    \n" {
+		t.Fatalf("normalized left payload = %q, want %q", got, "    \n")
+	}
+	if got := diff.receivedCommand.Right.Payload[0]; got != "0 0 1" {
+		t.Fatalf("normalized right payload = %q, want %q", got, "0 0 1")
+	}
+
+	var resp diffResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("failed to unmarshal response: %v", err)
+	}
+
+	if resp.Identical || len(resp.Sentences) != 2 {
+		t.Fatalf("unexpected response: %+v", resp)
+	}
+	if s := resp.Sentences[0]; s.Before == nil || *s.Before != "0 0 0" || s.After == nil || *s.After != "0 0 1" || len(s.Segments) != 1 {
+		t.Fatalf("unexpected changed sentence: %+v", s)
+	}
+	if s := resp.Sentences[1]; s.LeftIndex != nil || s.RightIndex == nil || *s.RightIndex != 1 || s.Before != nil || s.After == nil || *s.After != "1 1 1" {
+		t.Fatalf("unexpected added sentence: %+v", s)
+	}
+}
+
+func TestDiffHandler_Errors(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	cases := map[string]string{
+		"invalid json":   `{`,
+		"unknown format": `{"left":{"format":"Hex","payload":["ff"]},"right":{"format":"Decimal","payload":["0 0 0"]}}`,
+		"bad escape":     `{"left":{"format":"Whitespace","payload":["%ZZ"]},"right":{"format":"Decimal","payload":["0 0 0"]}}`,
+	}
+
+	for name, payload := range cases {
+		t.Run(name, func(t *testing.T) {
+			r := NewRouter(&stubUsecase{}, WithDiffUsecase(&stubDiffUsecase{}))
+
+			req := httptest.NewRequest(http.MethodPost, "/v1/diff", bytes.NewBufferString(payload))
+			req.Header.Set("Content-Type", "application/json")
+			rec := httptest.NewRecorder()
+
+			r.ServeHTTP(rec, req)
+
+			if rec.Code != http.StatusBadRequest {
+				t.Fatalf("status = %d, want %d", rec.Code, http.StatusBadRequest)
+			}
+		})
+	}
+}
+
+func TestDiffHandler_NotRegisteredWithoutOption(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	r := NewRouter(&stubUsecase{})
+
+	req := httptest.NewRequest(http.MethodPost, "/v1/diff", bytes.NewBufferString(`{}`))
+	rec := httptest.NewRecorder()
+
+	r.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusNotFound {
+		t.Fatalf("status = %d, want %d", rec.Code, http.StatusNotFound)
+	}
+}
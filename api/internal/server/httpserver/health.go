@@ -0,0 +1,103 @@
+package httpserver
+
+import (
+	"net/http"
+	"runtime/debug"
+	"sync/atomic"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// buildRevision / buildTime はビルド時に -ldflags "-X ..." で埋め込む値。
+// Docker ビルドのように VCS 情報が無い環境で debug.ReadBuildInfo を補う。
+var (
+	buildRevision string
+	buildTime     string
+)
+
+var readBuildInfoFn = debug.ReadBuildInfo
+
+// Readiness はトラフィックを受け付けられる状態かどうかを保持する。
+// シャットダウン開始時に SetReady(false) とすることで、GET /readyz が 503 を返し、
+// ロードバランサがサーバを振り分け対象から外す。
+type Readiness struct {
+	notReady atomic.Bool
+}
+
+// NewReadiness は受け付け可能な状態の Readiness を生成する。
+func NewReadiness() *Readiness {
+	return &Readiness{}
+}
+
+// SetReady は受け付け可能かどうかを切り替える。
+func (r *Readiness) SetReady(ready bool) {
+	r.notReady.Store(!ready)
+}
+
+// Ready は受け付け可能であれば true を返す。
+func (r *Readiness) Ready() bool {
+	return !r.notReady.Load()
+}
+
+// BuildInfo は GET /version が返すビルド情報を表す。
+type BuildInfo struct {
+	Module    string `json:"module"`
+	Version   string `json:"version"`
+	GoVersion string `json:"go_version"`
+	Revision  string `json:"vcs_revision,omitempty"`
+	BuildTime string `json:"build_time,omitempty"`
+	Modified  bool   `json:"vcs_modified"`
+}
+
+// ReadBuildInfo は実行中のバイナリに埋め込まれたモジュールと VCS の情報を返す。
+// ビルド時刻にはコミット時刻（vcs.time）を用い、-ldflags で埋め込んだ値があればそちらを優先する。
+func ReadBuildInfo() BuildInfo {
+	var info BuildInfo
+	if bi, ok := readBuildInfoFn(); ok {
+		info.Module = bi.Main.Path
+		info.Version = bi.Main.Version
+		info.GoVersion = bi.GoVersion
+		for _, s := range bi.Settings {
+			switch s.Key {
+			case "vcs.revision":
+				info.Revision = s.Value
+			case "vcs.time":
+				info.BuildTime = s.Value
+			case "vcs.modified":
+				info.Modified = s.Value == "true"
+			}
+		}
+	}
+	if buildRevision != "" {
+		info.Revision = buildRevision
+	}
+	if buildTime != "" {
+		info.BuildTime = buildTime
+	}
+	return info
+}
+
+func healthzHandler(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{"status": "ok", "timestamp": time.Now().UTC()})
+}
+
+// livezHandler はプロセスが応答できることだけを示す。依存先やシャットダウン状態は見ない。
+func livezHandler(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{"status": "ok"})
+}
+
+// readyzHandler はトラフィックを受け付けられるかを返す。readiness が nil の場合は常に受け付け可能とする。
+func readyzHandler(readiness *Readiness) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if readiness != nil && !readiness.Ready() {
+			c.JSON(http.StatusServiceUnavailable, gin.H{"status": "shutting_down"})
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"status": "ok"})
+	}
+}
+
+func versionHandler(c *gin.Context) {
+	c.JSON(http.StatusOK, ReadBuildInfo())
+}
@@ -0,0 +1,142 @@
+package httpserver
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/2509-hackz-ichthyo/main/api/internal/app"
+	"github.com/2509-hackz-ichthyo/main/api/internal/jobs"
+	"github.com/gin-gonic/gin"
+)
+
+// JobManager は /v1/jobs が依存するインタフェースを表す。
+type JobManager interface {
+	Submit(command app.WhitespaceCommand) (jobs.Job, error)
+	Get(id string) (jobs.Job, error)
+	Result(id string) (app.WhitespaceResult, jobs.Job, error)
+}
+
+// queueFullRetryAfter は待ち行列が満杯のときに Retry-After で返す秒数。
+const queueFullRetryAfter = "5"
+
+// submitJobHandler は POST /v1/jobs に届いたリクエストを検証し、ジョブとして受け付ける。
+// 検証は同期的に行うため、不正なペイロードはジョブを作らずに 400 を返す。
+func submitJobHandler(m JobManager) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req decodeRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			writeError(c, http.StatusBadRequest, "リクエストボディの形式が不正です", err)
+			return
+		}
+
+		payload, err := app.NormalizePayload(req.CommandType, req.Payload)
+		if err != nil {
+			writeError(c, http.StatusBadRequest, "ペイロードが不正です", err)
+			return
+		}
+
+		job, err := m.Submit(app.WhitespaceCommand{CommandType: req.CommandType, Payload: payload})
+		switch {
+		case errors.Is(err, jobs.ErrQueueFull):
+			c.Header("Retry-After", queueFullRetryAfter)
+			writeError(c, http.StatusServiceUnavailable, "ジョブの受け付け数が上限に達しています", err)
+			return
+		case errors.Is(err, jobs.ErrClosed):
+			writeError(c, http.StatusServiceUnavailable, "サーバーが停止処理中です", err)
+			return
+		case err != nil:
+			writeError(c, http.StatusInternalServerError, "内部エラーが発生しました", err)
+			return
+		}
+
+		c.Header("Location", jobPath(job.ID))
+		c.JSON(http.StatusAccepted, newJobResponse(job))
+	}
+}
+
+// getJobHandler は GET /v1/jobs/:id でジョブの状態と進捗を返す。
+func getJobHandler(m JobManager) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		job, err := m.Get(c.Param("id"))
+		if err != nil {
+			writeError(c, http.StatusNotFound, "ジョブが見つかりません", err)
+			return
+		}
+		c.JSON(http.StatusOK, newJobResponse(job))
+	}
+}
+
+// jobResultHandler は GET /v1/jobs/:id/result で完了したジョブの結果を POST /v1/decode と同じ形で返す。
+// 失敗したジョブは、同じ入力を POST /v1/decode に送った場合と同じエラーレスポンスを返す。
+func jobResultHandler(m JobManager) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		result, job, err := m.Result(c.Param("id"))
+		switch {
+		case errors.Is(err, jobs.ErrNotFound):
+			writeError(c, http.StatusNotFound, "ジョブが見つかりません", err)
+			return
+		case errors.Is(err, jobs.ErrNotFinished):
+			writeError(c, http.StatusConflict, "ジョブが完了していません", err)
+			return
+		case err != nil:
+			handleUsecaseError(c, err)
+			return
+		}
+
+		c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="%s.json"`, job.ID))
+		c.JSON(http.StatusOK, newDecodeResponse(result))
+	}
+}
+
+func jobPath(id string) string {
+	return "/v1/jobs/" + id
+}
+
+// jobResponse は POST /v1/jobs と GET /v1/jobs/:id のレスポンスボディ。
+type jobResponse struct {
+	ID          string     `json:"id"`
+	Status      string     `json:"status"`
+	CommandType string     `json:"command_type"`
+	Total       int        `json:"total"`
+	Processed   int        `json:"processed"`
+	Progress    float64    `json:"progress"`
+	CreatedAt   time.Time  `json:"created_at"`
+	StartedAt   *time.Time `json:"started_at,omitempty"`
+	FinishedAt  *time.Time `json:"finished_at,omitempty"`
+	ExpiresAt   *time.Time `json:"expires_at,omitempty"`
+	Error       string     `json:"error,omitempty"`
+	ResultURL   string     `json:"result_url,omitempty"`
+}
+
+func newJobResponse(job jobs.Job) jobResponse {
+	resp := jobResponse{
+		ID:          job.ID,
+		Status:      string(job.Status),
+		CommandType: job.CommandType,
+		Total:       job.Total,
+		Processed:   job.Processed,
+		CreatedAt:   job.CreatedAt,
+		StartedAt:   optionalTime(job.StartedAt),
+		FinishedAt:  optionalTime(job.FinishedAt),
+		ExpiresAt:   optionalTime(job.ExpiresAt),
+	}
+	if job.Total > 0 {
+		resp.Progress = float64(job.Processed) / float64(job.Total)
+	}
+	if job.Err != nil {
+		resp.Error = job.Err.Error()
+	}
+	if job.Status == jobs.StatusSucceeded {
+		resp.ResultURL = jobPath(job.ID) + "/result"
+	}
+	return resp
+}
+
+func optionalTime(t time.Time) *time.Time {
+	if t.IsZero() {
+		return nil
+	}
+	return &t
+}
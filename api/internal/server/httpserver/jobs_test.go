@@ -0,0 +1,188 @@
+package httpserver
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/2509-hackz-ichthyo/main/api/internal/app"
+	"github.com/2509-hackz-ichthyo/main/api/internal/domain"
+	"github.com/2509-hackz-ichthyo/main/api/internal/jobs"
+	"github.com/gin-gonic/gin"
+)
+
+type stubJobManager struct {
+	job       jobs.Job
+	result    app.WhitespaceResult
+	submitErr error
+	getErr    error
+	resultErr error
+}
+
+func (s *stubJobManager) Submit(command app.WhitespaceCommand) (jobs.Job, error) {
+	return s.job, s.submitErr
+}
+
+func (s *stubJobManager) Get(string) (jobs.Job, error) {
+	return s.job, s.getErr
+}
+
+func (s *stubJobManager) Result(string) (app.WhitespaceResult, jobs.Job, error) {
+	return s.result, s.job, s.resultErr
+}
+
+func TestJobsHandler_RoundTrip(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	manager := jobs.NewManager(app.NewWhitespaceUsecase(), jobs.WithChunkSize(1))
+	t.Cleanup(func() { _ = manager.Shutdown(context.Background()) })
+	r := NewRouter(&stubUsecase{}, WithJobs(manager))
+
+	body := `{"command_type":"DecimalToWhitespace","payload":["1 2 3","0 0 0"]}`
+	req := httptest.NewRequest(http.MethodPost, "/v1/jobs", bytes.NewBufferString(body))
+	req.Header.Set("Content-Type", "application/json")
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusAccepted {
+		t.Fatalf("status = %d, want %d; body = %s", rec.Code, http.StatusAccepted, rec.Body.String())
+	}
+	var submitted jobResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &submitted); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if submitted.ID == "" || submitted.Total != 2 {
+		t.Fatalf("unexpected job: %+v", submitted)
+	}
+	if got, want := rec.Header().Get("Location"), "/v1/jobs/"+submitted.ID; got != want {
+		t.Fatalf("Location = %q, want %q", got, want)
+	}
+
+	var status jobResponse
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		rec = httptest.NewRecorder()
+		r.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/v1/jobs/"+submitted.ID, nil))
+		if rec.Code != http.StatusOK {
+			t.Fatalf("status = %d, want %d", rec.Code, http.StatusOK)
+		}
+		if err := json.Unmarshal(rec.Body.Bytes(), &status); err != nil {
+			t.Fatalf("failed to decode response: %v", err)
+		}
+		if jobs.Status(status.Status).Done() {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("job did not finish: %+v", status)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	if status.Status != string(jobs.StatusSucceeded) || status.Progress != 1 || status.ResultURL == "" {
+		t.Fatalf("unexpected job: %+v", status)
+	}
+
+	rec = httptest.NewRecorder()
+	r.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, status.ResultURL, nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want %d", rec.Code, http.StatusOK)
+	}
+	if got := rec.Header().Get("Content-Disposition"); got == "" {
+		t.Fatal("Content-Disposition header is missing")
+	}
+	var result decodeResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &result); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	want := []string{"      \t\n     \t \n         \t\t\n", "       \n       \n           \n"}
+	if len(result.ResultWhitespace) != len(want) || result.ResultWhitespace[0] != want[0] || result.ResultWhitespace[1] != want[1] {
+		t.Fatalf("ResultWhitespace = %q, want %q", result.ResultWhitespace, want)
+	}
+}
+
+func TestJobsHandler_SubmitErrors(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	cases := map[string]struct {
+		body       string
+		manager    *stubJobManager
+		wantStatus int
+	}{
+		"invalid json":   {body: `{`, manager: &stubJobManager{}, wantStatus: http.StatusBadRequest},
+		"invalid escape": {body: `{"command_type":"WhitespaceToDecimal","payload":["%ZZ"]}`, manager: &stubJobManager{}, wantStatus: http.StatusBadRequest},
+		"queue full":     {body: `{"command_type":"DecimalToWhitespace","payload":["1 2 3"]}`, manager: &stubJobManager{submitErr: jobs.ErrQueueFull}, wantStatus: http.StatusServiceUnavailable},
+		"closed":         {body: `{"command_type":"DecimalToWhitespace","payload":["1 2 3"]}`, manager: &stubJobManager{submitErr: jobs.ErrClosed}, wantStatus: http.StatusServiceUnavailable},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			r := NewRouter(&stubUsecase{}, WithJobs(tc.manager))
+
+			req := httptest.NewRequest(http.MethodPost, "/v1/jobs", bytes.NewBufferString(tc.body))
+			req.Header.Set("Content-Type", "application/json")
+			rec := httptest.NewRecorder()
+			r.ServeHTTP(rec, req)
+
+			if rec.Code != tc.wantStatus {
+				t.Fatalf("status = %d, want %d", rec.Code, tc.wantStatus)
+			}
+			if errors.Is(tc.manager.submitErr, jobs.ErrQueueFull) && rec.Header().Get("Retry-After") == "" {
+				t.Fatal("Retry-After header is missing")
+			}
+		})
+	}
+}
+
+func TestJobsHandler_ResultErrors(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	cases := map[string]struct {
+		manager    *stubJobManager
+		wantStatus int
+	}{
+		"not found":    {manager: &stubJobManager{resultErr: jobs.ErrNotFound}, wantStatus: http.StatusNotFound},
+		"not finished": {manager: &stubJobManager{resultErr: jobs.ErrNotFinished}, wantStatus: http.StatusConflict},
+		"failed":       {manager: &stubJobManager{resultErr: domain.ErrInvalidPayload}, wantStatus: http.StatusBadRequest},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			r := NewRouter(&stubUsecase{}, WithJobs(tc.manager))
+
+			rec := httptest.NewRecorder()
+			r.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/v1/jobs/abc/result", nil))
+
+			if rec.Code != tc.wantStatus {
+				t.Fatalf("status = %d, want %d", rec.Code, tc.wantStatus)
+			}
+		})
+	}
+}
+
+func TestJobsHandler_GetNotFound(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	r := NewRouter(&stubUsecase{}, WithJobs(&stubJobManager{getErr: jobs.ErrNotFound}))
+
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/v1/jobs/abc", nil))
+
+	if rec.Code != http.StatusNotFound {
+		t.Fatalf("status = %d, want %d", rec.Code, http.StatusNotFound)
+	}
+}
+
+func TestJobsHandler_NotRegisteredWithoutOption(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	r := NewRouter(&stubUsecase{})
+
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/v1/jobs", bytes.NewBufferString(`{}`)))
+
+	if rec.Code != http.StatusNotFound {
+		t.Fatalf("status = %d, want %d", rec.Code, http.StatusNotFound)
+	}
+}
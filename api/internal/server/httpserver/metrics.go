@@ -0,0 +1,176 @@
+package httpserver
+
+import (
+	"errors"
+	"strconv"
+	"time"
+
+	"github.com/2509-hackz-ichthyo/main/api/internal/app"
+	"github.com/2509-hackz-ichthyo/main/api/internal/domain"
+	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// decodeObservationKey は decodeHandler が gin.Context に計測対象の情報を格納するキー。
+const decodeObservationKey = "httpserver.decodeObservation"
+
+// unknownLabel はラベル値として扱えない入力（未対応の命令種別など）をまとめる値。
+const unknownLabel = "unknown"
+
+// errorLabels はエラー件数のラベルに用いる番兵エラーと名前の対応。先頭から順に判定する。
+var errorLabels = []struct {
+	err  error
+	name string
+}{
+	{err: app.ErrValidationFailed, name: "ErrValidationFailed"},
+	{err: app.ErrEnvelopeVerificationFailed, name: "ErrEnvelopeVerificationFailed"},
+	{err: app.ErrEnvelopeKeyNotConfigured, name: "ErrEnvelopeKeyNotConfigured"},
+	{err: domain.ErrInvalidPayload, name: "ErrInvalidPayload"},
+	{err: domain.ErrInvalidCommandType, name: "ErrInvalidCommandType"},
+	{err: domain.ErrTypeMismatch, name: "ErrTypeMismatch"},
+	{err: domain.ErrInvalidPayloadFormat, name: "ErrInvalidPayloadFormat"},
+	{err: ErrMissingAPIKey, name: "ErrMissingAPIKey"},
+	{err: ErrInvalidAPIKey, name: "ErrInvalidAPIKey"},
+	{err: ErrRateLimited, name: "ErrRateLimited"},
+}
+
+// Metrics は API が公開する Prometheus メトリクスを保持する。
+// 複数の Router を生成してもメトリクスが衝突しないよう、専用の Registry を持つ。
+type Metrics struct {
+	registry *prometheus.Registry
+
+	httpRequests   *prometheus.CounterVec
+	httpDuration   *prometheus.HistogramVec
+	decodeRequests *prometheus.CounterVec
+	decodeDuration *prometheus.HistogramVec
+	sentences      *prometheus.HistogramVec
+	payloadBytes   *prometheus.HistogramVec
+	errors         *prometheus.CounterVec
+}
+
+// decodeObservation は 1 回の POST /v1/decode で計測する値をまとめたもの。
+type decodeObservation struct {
+	commandType  string
+	sentences    int
+	payloadBytes int
+}
+
+// NewMetrics はメトリクスを生成し、Go ランタイム・プロセスのメトリクスとともに登録する。
+func NewMetrics() *Metrics {
+	m := &Metrics{
+		registry: prometheus.NewRegistry(),
+		httpRequests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "ws_http_requests_total",
+			Help: "HTTP リクエスト数（ルート・メソッド・ステータス別）",
+		}, []string{"route", "method", "status"}),
+		httpDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Name:    "ws_http_request_duration_seconds",
+			Help:    "HTTP リクエストの処理時間（秒）",
+			Buckets: prometheus.DefBuckets,
+		}, []string{"route", "method"}),
+		decodeRequests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "ws_decode_requests_total",
+			Help: "変換リクエスト数（命令種別・ステータス別）",
+		}, []string{"command_type", "status"}),
+		decodeDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Name:    "ws_decode_request_duration_seconds",
+			Help:    "変換リクエストの処理時間（秒）",
+			Buckets: prometheus.DefBuckets,
+		}, []string{"command_type"}),
+		sentences: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Name:    "ws_decode_sentences",
+			Help:    "1 リクエストあたりの文の数",
+			Buckets: []float64{1, 2, 4, 8, 16, 32, 64, 128},
+		}, []string{"command_type"}),
+		payloadBytes: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Name:    "ws_decode_payload_bytes",
+			Help:    "1 リクエストあたりのペイロードのバイト数",
+			Buckets: prometheus.ExponentialBuckets(16, 4, 8),
+		}, []string{"command_type"}),
+		errors: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "ws_errors_total",
+			Help: "エラーレスポンス数（ラップされた番兵エラー別）",
+		}, []string{"route", "error"}),
+	}
+
+	m.registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		m.httpRequests,
+		m.httpDuration,
+		m.decodeRequests,
+		m.decodeDuration,
+		m.sentences,
+		m.payloadBytes,
+		m.errors,
+	)
+
+	return m
+}
+
+func (m *Metrics) handler() gin.HandlerFunc {
+	return gin.WrapH(promhttp.HandlerFor(m.registry, promhttp.HandlerOpts{}))
+}
+
+// middleware は全リクエストの件数・処理時間と、ハンドラが記録したエラーを集計する。
+func (m *Metrics) middleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		start := time.Now()
+		c.Next()
+		elapsed := time.Since(start).Seconds()
+
+		route := c.FullPath()
+		if route == "" {
+			route = unknownLabel
+		}
+		status := strconv.Itoa(c.Writer.Status())
+
+		m.httpRequests.WithLabelValues(route, c.Request.Method, status).Inc()
+		m.httpDuration.WithLabelValues(route, c.Request.Method).Observe(elapsed)
+
+		for _, ginErr := range c.Errors {
+			m.errors.WithLabelValues(route, errorLabel(ginErr.Err)).Inc()
+		}
+
+		value, ok := c.Get(decodeObservationKey)
+		if !ok {
+			return
+		}
+		obs := value.(decodeObservation)
+		m.decodeRequests.WithLabelValues(obs.commandType, status).Inc()
+		m.decodeDuration.WithLabelValues(obs.commandType).Observe(elapsed)
+		m.sentences.WithLabelValues(obs.commandType).Observe(float64(obs.sentences))
+		m.payloadBytes.WithLabelValues(obs.commandType).Observe(float64(obs.payloadBytes))
+	}
+}
+
+// observeDecode は decodeHandler が受け付けたリクエストの情報を記録する。
+// 命令種別は未対応の値によってラベルが増え続けないよう、既知の値以外を unknown にまとめる。
+func observeDecode(c *gin.Context, commandType string, payload []string) {
+	label := unknownLabel
+	if ct, err := domain.ParseCommandType(commandType); err == nil {
+		label = string(ct)
+	}
+
+	size := 0
+	for _, sentence := range payload {
+		size += len(sentence)
+	}
+
+	c.Set(decodeObservationKey, decodeObservation{
+		commandType:  label,
+		sentences:    len(payload),
+		payloadBytes: size,
+	})
+}
+
+func errorLabel(err error) string {
+	for _, candidate := range errorLabels {
+		if errors.Is(err, candidate.err) {
+			return candidate.name
+		}
+	}
+	return "other"
+}
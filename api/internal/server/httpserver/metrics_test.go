@@ -0,0 +1,113 @@
+package httpserver
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/2509-hackz-ichthyo/main/api/internal/app"
+	"github.com/2509-hackz-ichthyo/main/api/internal/domain"
+	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+func TestMetrics_DecodeRequests(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	metrics := NewMetrics()
+	usecase := &stubUsecase{result: app.WhitespaceResult{ResultBinaries: []string{"0000 0000 00000000"}}}
+	r := NewRouter(usecase, WithMetrics(metrics))
+
+	post := func(body string) {
+		req := httptest.NewRequest(http.MethodPost, "/v1/decode", bytes.NewBufferString(body))
+		req.Header.Set("Content-Type", "application/json")
+		r.ServeHTTP(httptest.NewRecorder(), req)
+	}
+
+	post(`{"command_type":"WhitespaceToBinary","payload":["%20%20%20%20%0A","%20%0A"]}`)
+	usecase.err = fmt.Errorf("%w: broken", domain.ErrInvalidPayload)
+	post(`{"command_type":"WhitespaceToBinary","payload":["%20%0A"]}`)
+	post(`{"command_type":"NoSuchCommand","payload":["x"]}`)
+
+	if got := testutil.ToFloat64(metrics.decodeRequests.WithLabelValues("WhitespaceToBinary", "200")); got != 1 {
+		t.Fatalf("decode 200 count = %v, want 1", got)
+	}
+	if got := testutil.ToFloat64(metrics.decodeRequests.WithLabelValues("WhitespaceToBinary", "400")); got != 1 {
+		t.Fatalf("decode 400 count = %v, want 1", got)
+	}
+	if got := testutil.ToFloat64(metrics.decodeRequests.WithLabelValues(unknownLabel, "400")); got != 1 {
+		t.Fatalf("unknown command count = %v, want 1", got)
+	}
+	if got := testutil.ToFloat64(metrics.errors.WithLabelValues("/v1/decode", "ErrInvalidPayload")); got != 1 {
+		t.Fatalf("ErrInvalidPayload count = %v, want 1", got)
+	}
+	if got := testutil.ToFloat64(metrics.errors.WithLabelValues("/v1/decode", "ErrInvalidCommandType")); got != 1 {
+		t.Fatalf("ErrInvalidCommandType count = %v, want 1", got)
+	}
+	if got := testutil.CollectAndCount(metrics.sentences); got != 2 {
+		t.Fatalf("sentence histogram series = %d, want 2", got)
+	}
+}
+
+func TestMetrics_Endpoint(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	r := NewRouter(&stubUsecase{}, WithMetrics(NewMetrics()))
+
+	r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/healthz", nil))
+
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want %d", rec.Code, http.StatusOK)
+	}
+	if !strings.Contains(rec.Body.String(), `ws_http_requests_total{method="GET",route="/healthz",status="200"} 1`) {
+		t.Fatalf("metrics output does not contain healthz request count:\n%s", rec.Body.String())
+	}
+}
+
+func TestMetrics_EndpointRequiresAPIKey(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	r := NewRouter(&stubUsecase{},
+		WithMetrics(NewMetrics()),
+		WithAuth(AuthConfig{Keys: []APIKey{{Client: "prometheus", Key: "scrape"}}, KeyRate: 10, KeyBurst: 10}),
+	)
+
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	if rec.Code != http.StatusUnauthorized {
+		t.Fatalf("status without key = %d, want %d", rec.Code, http.StatusUnauthorized)
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/metrics", nil)
+	req.Header.Set("X-API-Key", "scrape")
+	rec = httptest.NewRecorder()
+	r.ServeHTTP(rec, req)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status with key = %d, want %d", rec.Code, http.StatusOK)
+	}
+}
+
+func TestMetrics_Disabled(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	r := NewRouter(&stubUsecase{})
+
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+
+	if rec.Code != http.StatusNotFound {
+		t.Fatalf("status = %d, want %d", rec.Code, http.StatusNotFound)
+	}
+}
+
+func TestErrorLabel(t *testing.T) {
+	if got := errorLabel(fmt.Errorf("wrap: %w", app.ErrValidationFailed)); got != "ErrValidationFailed" {
+		t.Fatalf("errorLabel() = %q, want ErrValidationFailed", got)
+	}
+	if got := errorLabel(fmt.Errorf("boom")); got != "other" {
+		t.Fatalf("errorLabel() = %q, want other", got)
+	}
+}
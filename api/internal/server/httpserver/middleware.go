@@ -0,0 +1,74 @@
+package httpserver
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"log/slog"
+	"time"
+
+	"github.com/2509-hackz-ichthyo/main/api/internal/logging"
+	"github.com/gin-gonic/gin"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+)
+
+// RequestIDHeader はリクエスト ID を受け渡す HTTP ヘッダ名。
+const RequestIDHeader = "X-Request-ID"
+
+// maxRequestIDLength を超えるクライアント指定のリクエスト ID は破棄して採番し直す。
+const maxRequestIDLength = 128
+
+// requestContext はリクエスト ID を採番（または引き継ぎ）してレスポンスヘッダに返し、
+// 上流から伝搬されたトレースコンテキストとともにリクエストのコンテキストへ格納する。
+func requestContext() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		requestID := c.GetHeader(RequestIDHeader)
+		if requestID == "" || len(requestID) > maxRequestIDLength {
+			requestID = newRequestID()
+		}
+		c.Header(RequestIDHeader, requestID)
+
+		ctx := otel.GetTextMapPropagator().Extract(c.Request.Context(), propagation.HeaderCarrier(c.Request.Header))
+		ctx = logging.WithRequestID(ctx, requestID)
+		c.Request = c.Request.WithContext(ctx)
+
+		c.Next()
+	}
+}
+
+// requestLogger は gin.Logger() の代わりに、1 リクエストにつき 1 行の構造化ログを出力する。
+func requestLogger() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		start := time.Now()
+		c.Next()
+
+		attrs := []any{
+			slog.String("method", c.Request.Method),
+			slog.String("path", c.Request.URL.Path),
+			slog.String("route", c.FullPath()),
+			slog.Int("status", c.Writer.Status()),
+			slog.Duration("latency", time.Since(start)),
+			slog.String("client_ip", c.ClientIP()),
+		}
+		if client, ok := Client(c); ok {
+			attrs = append(attrs, slog.String("client", client))
+		}
+		level := slog.LevelInfo
+		if len(c.Errors) > 0 {
+			attrs = append(attrs, slog.String("error", c.Errors.Last().Error()))
+			if c.Writer.Status() >= 500 {
+				level = slog.LevelError
+			} else {
+				level = slog.LevelWarn
+			}
+		}
+
+		slog.Log(c.Request.Context(), level, "request completed", attrs...)
+	}
+}
+
+func newRequestID() string {
+	var b [16]byte
+	_, _ = rand.Read(b[:])
+	return hex.EncodeToString(b[:])
+}
@@ -0,0 +1,86 @@
+package httpserver
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/2509-hackz-ichthyo/main/api/internal/logging"
+	"github.com/gin-gonic/gin"
+)
+
+func captureLogs(t *testing.T) *bytes.Buffer {
+	t.Helper()
+
+	var buf bytes.Buffer
+	original := slog.Default()
+	slog.SetDefault(logging.NewJSONLogger(&buf, slog.LevelInfo))
+	t.Cleanup(func() { slog.SetDefault(original) })
+	return &buf
+}
+
+func TestRequestID_EchoesClientValue(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	logs := captureLogs(t)
+	r := NewRouter(&stubUsecase{})
+
+	req := httptest.NewRequest(http.MethodGet, "/healthz", nil)
+	req.Header.Set(RequestIDHeader, "lambda-archive-123")
+	rec := httptest.NewRecorder()
+
+	r.ServeHTTP(rec, req)
+
+	if got := rec.Header().Get(RequestIDHeader); got != "lambda-archive-123" {
+		t.Fatalf("%s = %q, want %q", RequestIDHeader, got, "lambda-archive-123")
+	}
+
+	var line map[string]any
+	if err := json.Unmarshal(logs.Bytes(), &line); err != nil {
+		t.Fatalf("failed to unmarshal log line %q: %v", logs.String(), err)
+	}
+	if line["request_id"] != "lambda-archive-123" || line["route"] != "/healthz" || line["status"] != float64(http.StatusOK) {
+		t.Fatalf("unexpected log line: %v", line)
+	}
+}
+
+func TestRequestID_GeneratesWhenMissing(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	captureLogs(t)
+	r := NewRouter(&stubUsecase{})
+
+	for _, header := range []string{"", strings.Repeat("x", maxRequestIDLength+1)} {
+		req := httptest.NewRequest(http.MethodGet, "/healthz", nil)
+		if header != "" {
+			req.Header.Set(RequestIDHeader, header)
+		}
+		rec := httptest.NewRecorder()
+
+		r.ServeHTTP(rec, req)
+
+		if got := rec.Header().Get(RequestIDHeader); len(got) != 32 {
+			t.Fatalf("%s = %q, want generated 32 hex chars", RequestIDHeader, got)
+		}
+	}
+}
+
+func TestRequestLogger_LogsErrors(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	logs := captureLogs(t)
+	r := NewRouter(&stubUsecase{})
+
+	req := httptest.NewRequest(http.MethodPost, "/v1/decode", bytes.NewBufferString("{"))
+	req.Header.Set("Content-Type", "application/json")
+	r.ServeHTTP(httptest.NewRecorder(), req)
+
+	var line map[string]any
+	if err := json.Unmarshal(logs.Bytes(), &line); err != nil {
+		t.Fatalf("failed to unmarshal log line %q: %v", logs.String(), err)
+	}
+	if line["level"] != "WARN" || line["error"] == nil {
+		t.Fatalf("unexpected log line: %v", line)
+	}
+}
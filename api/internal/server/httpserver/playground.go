@@ -0,0 +1,22 @@
+package httpserver
+
+import (
+	_ "embed"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// playgroundHTML は GET /playground で配信する変換プレイグラウンドのページ。
+// ページ内のスクリプトが同一オリジンの POST /v1/decode を呼び出して結果を表示する。
+//
+//go:embed playground.html
+var playgroundHTML []byte
+
+// playgroundContentSecurityPolicy はページ内のインラインスクリプト・スタイルと同一オリジンへの通信のみを許可する。
+const playgroundContentSecurityPolicy = "default-src 'none'; script-src 'unsafe-inline'; style-src 'unsafe-inline'; connect-src 'self'; frame-ancestors 'none'"
+
+func playgroundHandler(c *gin.Context) {
+	c.Header("Content-Security-Policy", playgroundContentSecurityPolicy)
+	c.Data(http.StatusOK, "text/html; charset=utf-8", playgroundHTML)
+}
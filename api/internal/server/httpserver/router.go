@@ -7,19 +7,18 @@ import (
 	"errors"
 	"fmt"
 	"net/http"
-	"net/url"
 	"strings"
-	"time"
 
 	"github.com/2509-hackz-ichthyo/main/api/internal/app"
 	"github.com/2509-hackz-ichthyo/main/api/internal/domain"
 	"github.com/gin-gonic/gin"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
 )
 
-var (
-	parseCommandTypeFn = domain.ParseCommandType
-	pathUnescapeFn     = url.PathUnescape
-)
+var tracer = otel.Tracer("github.com/2509-hackz-ichthyo/main/api/internal/server/httpserver")
 
 // WhitespaceUsecase はハンドラが依存する最小限のインタフェースを表す。
 // ユースケース層の実装は Execute メソッドのみを公開すれば良い。
@@ -27,19 +26,130 @@ type WhitespaceUsecase interface {
 	Execute(ctx context.Context, command app.WhitespaceCommand) (app.WhitespaceResult, error)
 }
 
+// DiffUsecase は POST /v1/diff が依存するインタフェースを表す。
+type DiffUsecase interface {
+	Diff(ctx context.Context, command app.DiffCommand) (app.DiffResult, error)
+}
+
+// Option は NewRouter の任意設定を表す。
+type Option func(*routerOptions)
+
+type routerOptions struct {
+	diff      DiffUsecase
+	metrics   *Metrics
+	auth      *Authenticator
+	cors      *CORSConfig
+	readiness *Readiness
+	jobs      JobManager
+	proxies   []string
+}
+
+// WithDiffUsecase は POST /v1/diff を有効にする。
+func WithDiffUsecase(uc DiffUsecase) Option {
+	return func(o *routerOptions) {
+		o.diff = uc
+	}
+}
+
+// WithMetrics は GET /metrics を公開し、全リクエストを計測する。
+// 認証が有効な場合、GET /metrics にも /v1 と同じ API キー認証とレート制限を適用する。
+func WithMetrics(m *Metrics) Option {
+	return func(o *routerOptions) {
+		o.metrics = m
+	}
+}
+
+// WithAuth は /v1 配下に API キー認証とレート制限を適用する。
+func WithAuth(cfg AuthConfig) Option {
+	return WithAuthenticator(NewAuthenticator(cfg))
+}
+
+// WithAuthenticator は /v1 配下に a による認証とレート制限を適用する。
+// gRPC サーバと同じ a を渡すと、トークンバケットを両方の経路で共有する。
+func WithAuthenticator(a *Authenticator) Option {
+	return func(o *routerOptions) {
+		o.auth = a
+	}
+}
+
+// WithTrustedProxies は X-Forwarded-For を信頼するプロキシの IP / CIDR を指定する。
+// 指定しない場合はヘッダを無視し、IP 単位のレート制限は TCP 接続の相手に対して適用する。
+func WithTrustedProxies(proxies []string) Option {
+	return func(o *routerOptions) {
+		o.proxies = proxies
+	}
+}
+
+// WithCORS はブラウザからのクロスオリジン呼び出しを許可する。
+func WithCORS(cfg CORSConfig) Option {
+	return func(o *routerOptions) {
+		o.cors = &cfg
+	}
+}
+
+// WithReadiness は GET /readyz の応答を readiness の状態に連動させる。
+func WithReadiness(readiness *Readiness) Option {
+	return func(o *routerOptions) {
+		o.readiness = readiness
+	}
+}
+
+// WithJobs は /v1/jobs 配下の非同期変換ジョブ API を有効にする。
+func WithJobs(m JobManager) Option {
+	return func(o *routerOptions) {
+		o.jobs = m
+	}
+}
+
 // NewRouter は Gin の Engine を生成し、エンドポイントを束ねる。
 // ここでミドルウェアやルーティングを一元的に設定する。
-func NewRouter(uc WhitespaceUsecase) *gin.Engine {
+func NewRouter(uc WhitespaceUsecase, opts ...Option) *gin.Engine {
+	var options routerOptions
+	for _, opt := range opts {
+		opt(&options)
+	}
+
 	r := gin.New()
-	r.Use(gin.Logger(), gin.Recovery())
+	// 既定の Gin は全てのプロキシを信頼するため、クライアントが X-Forwarded-For で接続元 IP を偽れてしまう。
+	// 不正な値が渡された場合もどのプロキシも信頼しない側に倒す。
+	if err := r.SetTrustedProxies(options.proxies); err != nil {
+		_ = r.SetTrustedProxies(nil)
+	}
+	r.Use(requestContext(), requestLogger(), gin.Recovery(), securityHeaders())
+	if options.cors != nil {
+		r.Use(cors(*options.cors))
+	}
+	if options.metrics != nil {
+		r.Use(options.metrics.middleware())
+		// メトリクスにはルートやエラーの内訳が含まれるため、認証の外に置かない。
+		metricsHandlers := []gin.HandlerFunc{options.metrics.handler()}
+		if options.auth != nil {
+			metricsHandlers = append([]gin.HandlerFunc{options.auth.middleware()}, metricsHandlers...)
+		}
+		r.GET("/metrics", metricsHandlers...)
+	}
 
-	r.GET("/healthz", func(c *gin.Context) {
-		c.JSON(http.StatusOK, gin.H{"status": "ok", "timestamp": time.Now().UTC()})
-	})
+	r.GET("/healthz", healthzHandler)
+	r.GET("/livez", livezHandler)
+	r.GET("/readyz", readyzHandler(options.readiness))
+	r.GET("/version", versionHandler)
+
+	r.GET("/playground", playgroundHandler)
 
 	v1 := r.Group("/v1")
+	if options.auth != nil {
+		v1.Use(options.auth.middleware())
+	}
 	{
 		v1.POST("/decode", decodeHandler(uc))
+		if options.diff != nil {
+			v1.POST("/diff", diffHandler(options.diff))
+		}
+		if options.jobs != nil {
+			v1.POST("/jobs", submitJobHandler(options.jobs))
+			v1.GET("/jobs/:id", getJobHandler(options.jobs))
+			v1.GET("/jobs/:id/result", jobResultHandler(options.jobs))
+		}
 	}
 
 	return r
@@ -48,16 +158,22 @@ func NewRouter(uc WhitespaceUsecase) *gin.Engine {
 func decodeHandler(uc WhitespaceUsecase) gin.HandlerFunc {
 	// decodeHandler は POST /v1/decode に届いたリクエストをユースケースへ委譲する。
 	return func(c *gin.Context) {
+		ctx, span := tracer.Start(c.Request.Context(), "decodeHandler")
+		defer span.End()
+
 		var req decodeRequest
 		if err := c.ShouldBindJSON(&req); err != nil {
 			writeError(c, http.StatusBadRequest, "リクエストボディの形式が不正です", err)
 			return
 		}
+		observeDecode(c, req.CommandType, req.Payload)
 
 		payloadSlice := make([]string, len(req.Payload))
 		copy(payloadSlice, req.Payload)
 
-		payload, err := normalizePayload(req.CommandType, payloadSlice)
+		_, normalizeSpan := tracer.Start(ctx, "normalizePayload")
+		payload, err := app.NormalizePayload(req.CommandType, payloadSlice)
+		endSpan(normalizeSpan, err)
 		if err != nil {
 			writeError(c, http.StatusBadRequest, "ペイロードが不正です", err)
 			return
@@ -68,8 +184,12 @@ func decodeHandler(uc WhitespaceUsecase) gin.HandlerFunc {
 			Payload:     payload,
 		}
 
-		result, err := uc.Execute(c.Request.Context(), command)
+		span.SetAttributes(attribute.String("ws.command_type", req.CommandType), attribute.Int("ws.sentences", len(payload)))
+
+		result, err := uc.Execute(ctx, command)
 		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
 			handleUsecaseError(c, err)
 			return
 		}
@@ -78,24 +198,37 @@ func decodeHandler(uc WhitespaceUsecase) gin.HandlerFunc {
 	}
 }
 
+// endSpan はエラーがあればスパンに記録してから終了する。
+func endSpan(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
+
 func handleUsecaseError(c *gin.Context, err error) {
 	// handleUsecaseError はユースケース層から返却されたエラーを HTTP ステータスへ写像する。
+	// メッセージは WASM と共通の app.ErrorMessage から引く。
+	status := http.StatusInternalServerError
 	switch {
-	case errors.Is(err, app.ErrValidationFailed):
-		writeError(c, http.StatusBadRequest, "入力値が不正です", err)
-	case errors.Is(err, domain.ErrInvalidPayload):
-		writeError(c, http.StatusBadRequest, "ペイロードが不正です", err)
-	case errors.Is(err, domain.ErrInvalidCommandType):
-		writeError(c, http.StatusBadRequest, "サポートされていない命令種別です", err)
-	case errors.Is(err, domain.ErrTypeMismatch):
-		writeError(c, http.StatusBadRequest, "命令と処理が一致しません", err)
-	default:
-		writeError(c, http.StatusInternalServerError, "内部エラーが発生しました", err)
+	case errors.Is(err, app.ErrValidationFailed),
+		errors.Is(err, domain.ErrInvalidPayload),
+		errors.Is(err, domain.ErrInvalidCommandType),
+		errors.Is(err, app.ErrEnvelopeVerificationFailed),
+		errors.Is(err, domain.ErrInvalidPayloadFormat),
+		errors.Is(err, domain.ErrTypeMismatch):
+		status = http.StatusBadRequest
+	case errors.Is(err, app.ErrEnvelopeKeyNotConfigured):
+		status = http.StatusServiceUnavailable
 	}
+	writeError(c, status, app.ErrorMessage(err), err)
 }
 
 func writeError(c *gin.Context, status int, message string, err error) {
 	// writeError は共通のエラーレスポンス JSON を構築して返す。
+	// エラーは gin.Context にも積み、メトリクスなどのミドルウェアから参照できるようにする。
+	_ = c.Error(err)
 	c.JSON(status, gin.H{
 		"error":   message,
 		"details": err.Error(),
@@ -172,32 +305,3 @@ func newDecodeResponse(result app.WhitespaceResult) decodeResponse {
 
 	return resp
 }
-
-func normalizePayload(commandType string, values []string) ([]string, error) {
-	ct, err := parseCommandTypeFn(commandType)
-	if err != nil {
-		return nil, err
-	}
-
-	normalized := make([]string, len(values))
-	for i, value := range values {
-		switch ct {
-		case domain.CommandTypeWhitespaceToBinary, domain.CommandTypeWhitespaceToDecimal:
-			decoded, err := pathUnescapeFn(value)
-			if err != nil {
-				return nil, fmt.Errorf("%w: failed to decode percent-encoded payload", domain.ErrInvalidPayload)
-			}
-			normalized[i] = decoded
-		case domain.CommandTypeDecimalToWhitespace, domain.CommandTypeBinariesToWhitespace:
-			normalized[i] = strings.TrimSpace(value)
-		default:
-			normalized[i] = value
-		}
-	}
-
-	if len(normalized) == 0 {
-		return nil, fmt.Errorf("%w: payload must not be empty", app.ErrValidationFailed)
-	}
-
-	return normalized, nil
-}
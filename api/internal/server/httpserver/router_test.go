@@ -7,6 +7,7 @@ import (
 	"errors"
 	"net/http"
 	"net/http/httptest"
+	"runtime/debug"
 	"testing"
 
 	"github.com/2509-hackz-ichthyo/main/api/internal/app"
@@ -136,6 +137,9 @@ func TestDecodeHandler_UsecaseErrorMapping(t *testing.T) {
 		domain.ErrInvalidPayload,
 		domain.ErrInvalidCommandType,
 		domain.ErrTypeMismatch,
+		domain.ErrInvalidPayloadFormat,
+		app.ErrEnvelopeVerificationFailed,
+		app.ErrEnvelopeKeyNotConfigured,
 		errors.New("boom"),
 	}
 
@@ -144,6 +148,9 @@ func TestDecodeHandler_UsecaseErrorMapping(t *testing.T) {
 		http.StatusBadRequest,
 		http.StatusBadRequest,
 		http.StatusBadRequest,
+		http.StatusBadRequest,
+		http.StatusBadRequest,
+		http.StatusServiceUnavailable,
 		http.StatusInternalServerError,
 	}
 
@@ -257,69 +264,96 @@ func TestNewDecodeResponse(t *testing.T) {
 	}
 }
 
-func TestNormalizePayload(t *testing.T) {
-	t.Run("whitespace unescape", func(t *testing.T) {
-		values, err := normalizePayload("WhitespaceToDecimal", []string{"%20%09"})
-		if err != nil {
-			t.Fatalf("unexpected error: %v", err)
-		}
-		if values[0] != " \t" {
-			t.Fatalf("value = %q, want \" \\t\"", values[0])
-		}
-	})
+func TestNewRouter_Playground(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	r := NewRouter(&stubUsecase{})
 
-	t.Run("decimal trim", func(t *testing.T) {
-		values, err := normalizePayload("DecimalToWhitespace", []string{"  1 2 3  "})
-		if err != nil {
-			t.Fatalf("unexpected error: %v", err)
-		}
-		if values[0] != "1 2 3" {
-			t.Fatalf("value = %q, want %q", values[0], "1 2 3")
-		}
-	})
+	req := httptest.NewRequest(http.MethodGet, "/playground", nil)
+	rec := httptest.NewRecorder()
 
-	t.Run("binary trim", func(t *testing.T) {
-		values, err := normalizePayload("BinariesToWhitespace", []string{" 0101 "})
-		if err != nil {
-			t.Fatalf("unexpected error: %v", err)
-		}
-		if values[0] != "0101" {
-			t.Fatalf("value = %q, want %q", values[0], "0101")
-		}
-	})
+	r.ServeHTTP(rec, req)
 
-	t.Run("empty payload", func(t *testing.T) {
-		if _, err := normalizePayload("WhitespaceToBinary", nil); err == nil {
-			t.Fatalf("expected error but got nil")
-		}
-	})
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want %d", rec.Code, http.StatusOK)
+	}
 
-	t.Run("invalid command", func(t *testing.T) {
-		if _, err := normalizePayload("Unknown", []string{""}); err == nil {
-			t.Fatalf("expected error but got nil")
-		}
-	})
+	if got := rec.Header().Get("Content-Type"); got != "text/html; charset=utf-8" {
+		t.Fatalf("Content-Type = %q, want text/html", got)
+	}
 
-	t.Run("invalid unescape", func(t *testing.T) {
-		if _, err := normalizePayload("WhitespaceToBinary", []string{"%ZZ"}); err == nil {
-			t.Fatalf("expected error but got nil")
-		}
-	})
+	if !bytes.Contains(rec.Body.Bytes(), []byte(`fetch("v1/decode"`)) {
+		t.Fatalf("playground page does not call the decode API")
+	}
+}
 
-	t.Run("default branch", func(t *testing.T) {
-		original := parseCommandTypeFn
-		parseCommandTypeFn = func(string) (domain.CommandType, error) {
-			return domain.CommandType("Custom"), nil
-		}
-		defer func() { parseCommandTypeFn = original }()
+func TestNewRouter_Probes(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	readiness := NewReadiness()
+	r := NewRouter(&stubUsecase{}, WithReadiness(readiness))
 
-		values, err := normalizePayload("Custom", []string{" keep "})
-		if err != nil {
-			t.Fatalf("unexpected error: %v", err)
-		}
+	get := func(path string) int {
+		rec := httptest.NewRecorder()
+		r.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, nil))
+		return rec.Code
+	}
 
-		if values[0] != " keep " {
-			t.Fatalf("value = %q, want %q", values[0], " keep ")
-		}
-	})
+	if code := get("/livez"); code != http.StatusOK {
+		t.Fatalf("/livez status = %d, want %d", code, http.StatusOK)
+	}
+	if code := get("/readyz"); code != http.StatusOK {
+		t.Fatalf("/readyz status = %d, want %d", code, http.StatusOK)
+	}
+
+	readiness.SetReady(false)
+
+	if code := get("/readyz"); code != http.StatusServiceUnavailable {
+		t.Fatalf("/readyz status = %d, want %d", code, http.StatusServiceUnavailable)
+	}
+	if code := get("/livez"); code != http.StatusOK {
+		t.Fatalf("/livez status = %d, want %d while shutting down", code, http.StatusOK)
+	}
+}
+
+func TestNewRouter_Version(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	origRead, origRevision := readBuildInfoFn, buildRevision
+	defer func() { readBuildInfoFn, buildRevision = origRead, origRevision }()
+
+	readBuildInfoFn = func() (*debug.BuildInfo, bool) {
+		return &debug.BuildInfo{
+			GoVersion: "go1.25.1",
+			Main:      debug.Module{Path: "github.com/2509-hackz-ichthyo/main/api", Version: "v1.2.3"},
+			Settings: []debug.BuildSetting{
+				{Key: "vcs.revision", Value: "abc123"},
+				{Key: "vcs.time", Value: "2025-09-20T10:00:00Z"},
+				{Key: "vcs.modified", Value: "true"},
+			},
+		}, true
+	}
+
+	r := NewRouter(&stubUsecase{})
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/version", nil))
+
+	var got BuildInfo
+	if err := json.Unmarshal(rec.Body.Bytes(), &got); err != nil {
+		t.Fatalf("failed to unmarshal response: %v", err)
+	}
+	want := BuildInfo{
+		Module:    "github.com/2509-hackz-ichthyo/main/api",
+		Version:   "v1.2.3",
+		GoVersion: "go1.25.1",
+		Revision:  "abc123",
+		BuildTime: "2025-09-20T10:00:00Z",
+		Modified:  true,
+	}
+	if got != want {
+		t.Fatalf("/version = %+v, want %+v", got, want)
+	}
+
+	buildRevision = "def456"
+	if info := ReadBuildInfo(); info.Revision != "def456" {
+		t.Fatalf("Revision = %q, want ldflags value", info.Revision)
+	}
 }
@@ -0,0 +1,70 @@
+// Package tracing は OpenTelemetry の TracerProvider を設定に応じて初期化する。
+package tracing
+
+import (
+	"context"
+	"fmt"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+)
+
+// Exporter はスパンの出力先を表す。
+type Exporter string
+
+const (
+	// ExporterNone はスパンを出力しない。
+	ExporterNone Exporter = "none"
+
+	// ExporterStdout はスパンを標準出力へ JSON で書き出す。ローカル確認用。
+	ExporterStdout Exporter = "stdout"
+
+	// ExporterOTLP は OTLP/HTTP でコレクタへ送信する。
+	// 送信先は OTEL_EXPORTER_OTLP_ENDPOINT などの標準環境変数で指定する。
+	ExporterOTLP Exporter = "otlp"
+)
+
+// ServiceName はスパンのリソース属性に付与するサービス名。
+const ServiceName = "ws-decode-api"
+
+// Setup は exporter に応じた TracerProvider と W3C Trace Context の伝搬設定をグローバルに登録する。
+// 返却する関数はバッファ済みのスパンを送信して TracerProvider を終了する。
+func Setup(ctx context.Context, exporter Exporter) (func(context.Context) error, error) {
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(
+		propagation.TraceContext{},
+		propagation.Baggage{},
+	))
+
+	var spanExporter sdktrace.SpanExporter
+	switch exporter {
+	case ExporterNone, "":
+		return func(context.Context) error { return nil }, nil
+	case ExporterStdout:
+		exp, err := stdouttrace.New()
+		if err != nil {
+			return nil, fmt.Errorf("failed to create stdout exporter: %w", err)
+		}
+		spanExporter = exp
+	case ExporterOTLP:
+		exp, err := otlptracehttp.New(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create otlp exporter: %w", err)
+		}
+		spanExporter = exp
+	default:
+		return nil, fmt.Errorf("unsupported trace exporter %q", exporter)
+	}
+
+	provider := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(spanExporter),
+		sdktrace.WithResource(resource.NewSchemaless(semconv.ServiceName(ServiceName))),
+	)
+	otel.SetTracerProvider(provider)
+
+	return provider.Shutdown, nil
+}
@@ -0,0 +1,26 @@
+package tracing
+
+import (
+	"context"
+	"testing"
+)
+
+func TestSetup(t *testing.T) {
+	for _, exporter := range []Exporter{"", ExporterNone, ExporterStdout, ExporterOTLP} {
+		t.Run(string(exporter), func(t *testing.T) {
+			shutdown, err := Setup(context.Background(), exporter)
+			if err != nil {
+				t.Fatalf("Setup(%q) error = %v", exporter, err)
+			}
+			if err := shutdown(context.Background()); err != nil {
+				t.Fatalf("shutdown error = %v", err)
+			}
+		})
+	}
+}
+
+func TestSetupUnsupportedExporter(t *testing.T) {
+	if _, err := Setup(context.Background(), Exporter("zipkin")); err == nil {
+		t.Fatal("expected error but got nil")
+	}
+}
@@ -0,0 +1,288 @@
+// Package client は ws-decode-api の POST /v1/decode を呼び出す型付きクライアントを提供する。
+//
+//	c, err := client.New("http://localhost:3000", client.WithAPIKey(key))
+//	resp, err := c.Decode(ctx, client.DecodeRequest{
+//		CommandType: client.CommandTypeWhitespaceToDecimal,
+//		Payload:     sentences,
+//	})
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand/v2"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// 既定値。
+const (
+	DefaultTimeout     = 10 * time.Second
+	DefaultMaxAttempts = 3
+	DefaultBaseDelay   = 200 * time.Millisecond
+	DefaultMaxDelay    = 2 * time.Second
+)
+
+// apiKeyHeader は API キーを送る HTTP ヘッダ名。サーバ側の httpserver.APIKeyHeader と揃える。
+const apiKeyHeader = "X-API-Key"
+
+// maxErrorBodySize はエラーレスポンスとして読み込む最大バイト数。
+const maxErrorBodySize = 64 << 10
+
+// CommandType は変換命令の種別。
+type CommandType string
+
+// サポートしている命令種別。
+const (
+	CommandTypeWhitespaceToDecimal  = CommandType("WhitespaceToDecimal")
+	CommandTypeWhitespaceToBinary   = CommandType("WhitespaceToBinary")
+	CommandTypeDecimalToWhitespace  = CommandType("DecimalToWhitespace")
+	CommandTypeBinariesToWhitespace = CommandType("BinariesToWhitespace")
+)
+
+// DecodeRequest は POST /v1/decode のリクエストボディ。
+// Payload の各要素が 1 文（Whitespace なら 3 行、数列なら 1 行）に対応する。
+type DecodeRequest struct {
+	CommandType CommandType `json:"command_type"`
+	Payload     []string    `json:"payload"`
+}
+
+// DecodeResponse はデコード結果のレスポンスボディ。
+// 結果は ResultKind に応じて ResultDecimals / ResultBinaries / ResultWhitespace のいずれかに入る。
+type DecodeResponse struct {
+	CommandType             CommandType `json:"command_type"`
+	ResultKind              string      `json:"result_kind"`
+	ResultDecimals          []string    `json:"result_decimals,omitempty"`
+	ResultBinaries          []string    `json:"result_binaries,omitempty"`
+	DecimalString           *string     `json:"decimal_string,omitempty"`
+	BinaryString            *string     `json:"binary_string,omitempty"`
+	ResultWhitespace        []string    `json:"result_whitespace,omitempty"`
+	ResultWhitespaceEncoded []string    `json:"result_whitespace_percent_encoded,omitempty"`
+}
+
+// Option は New の任意設定を表す。
+type Option func(*Client)
+
+// WithHTTPClient は通信に用いる http.Client を差し替える。
+// 試行ごとのタイムアウトは hc.Timeout ではなく WithTimeout で設定する。
+func WithHTTPClient(hc *http.Client) Option {
+	return func(c *Client) {
+		c.http = hc
+	}
+}
+
+// WithTimeout は 1 回の試行あたりのタイムアウトを設定する。0 以下なら無制限。
+func WithTimeout(d time.Duration) Option {
+	return func(c *Client) {
+		c.timeout = d
+	}
+}
+
+// WithAPIKey は X-API-Key ヘッダで送る API キーを設定する。
+func WithAPIKey(key string) Option {
+	return func(c *Client) {
+		c.apiKey = key
+	}
+}
+
+// WithRetry は再試行の回数（初回を含む）と、指数バックオフの初期・最大待ち時間を設定する。
+// maxAttempts が 1 以下なら再試行しない。
+func WithRetry(maxAttempts int, baseDelay, maxDelay time.Duration) Option {
+	return func(c *Client) {
+		c.maxAttempts = max(maxAttempts, 1)
+		c.baseDelay = baseDelay
+		c.maxDelay = maxDelay
+	}
+}
+
+// WithUserAgent は User-Agent ヘッダを設定する。
+func WithUserAgent(ua string) Option {
+	return func(c *Client) {
+		c.userAgent = ua
+	}
+}
+
+// Client は ws-decode-api のクライアント。複数の goroutine から同時に利用できる。
+type Client struct {
+	baseURL     *url.URL
+	http        *http.Client
+	timeout     time.Duration
+	apiKey      string
+	userAgent   string
+	maxAttempts int
+	baseDelay   time.Duration
+	maxDelay    time.Duration
+}
+
+// New は baseURL（例: "http://localhost:3000"）に対するクライアントを生成する。
+func New(baseURL string, opts ...Option) (*Client, error) {
+	u, err := url.Parse(strings.TrimRight(baseURL, "/"))
+	if err != nil {
+		return nil, fmt.Errorf("client: invalid base URL: %w", err)
+	}
+	if u.Scheme != "http" && u.Scheme != "https" || u.Host == "" {
+		return nil, fmt.Errorf("client: invalid base URL %q: scheme must be http or https", baseURL)
+	}
+
+	c := &Client{
+		baseURL:     u,
+		http:        http.DefaultClient,
+		timeout:     DefaultTimeout,
+		userAgent:   "ws-decode-client",
+		maxAttempts: DefaultMaxAttempts,
+		baseDelay:   DefaultBaseDelay,
+		maxDelay:    DefaultMaxDelay,
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c, nil
+}
+
+// Decode は POST /v1/decode を呼び出す。
+// 通信エラーと再試行可能なステータス（429・502・503・504）は指数バックオフで再試行し、
+// それ以外の失敗は *APIError として返す。
+func (c *Client) Decode(ctx context.Context, req DecodeRequest) (*DecodeResponse, error) {
+	body, err := json.Marshal(req)
+	if err != nil {
+		return nil, fmt.Errorf("client: failed to marshal request: %w", err)
+	}
+
+	var resp DecodeResponse
+	if err := c.do(ctx, "/v1/decode", body, &resp); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// DecimalToWhitespace は 10 進数の文を Whitespace に変換し、文ごとの結果を返す。
+func (c *Client) DecimalToWhitespace(ctx context.Context, sentences []string) ([]string, error) {
+	resp, err := c.Decode(ctx, DecodeRequest{CommandType: CommandTypeDecimalToWhitespace, Payload: sentences})
+	if err != nil {
+		return nil, err
+	}
+	return resp.ResultWhitespace, nil
+}
+
+// WhitespaceToDecimal は Whitespace の文を 10 進数に変換し、文ごとの結果を返す。
+func (c *Client) WhitespaceToDecimal(ctx context.Context, sentences []string) ([]string, error) {
+	resp, err := c.Decode(ctx, DecodeRequest{CommandType: CommandTypeWhitespaceToDecimal, Payload: sentences})
+	if err != nil {
+		return nil, err
+	}
+	return resp.ResultDecimals, nil
+}
+
+func (c *Client) do(ctx context.Context, path string, body []byte, out any) error {
+	var lastErr error
+	for attempt := 1; attempt <= c.maxAttempts; attempt++ {
+		retryAfter, err := c.attempt(ctx, path, body, out)
+		if err == nil {
+			return nil
+		}
+		lastErr = err
+		if attempt == c.maxAttempts || !retryable(ctx, err) {
+			break
+		}
+		if err := sleepContext(ctx, c.backoff(attempt, retryAfter)); err != nil {
+			return fmt.Errorf("client: %w (last error: %w)", err, lastErr)
+		}
+	}
+	return lastErr
+}
+
+// attempt は 1 回分のリクエストを送る。失敗時はサーバが返した Retry-After も返す。
+func (c *Client) attempt(ctx context.Context, path string, body []byte, out any) (time.Duration, error) {
+	if c.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, c.timeout)
+		defer cancel()
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.baseURL.String()+path, bytes.NewReader(body))
+	if err != nil {
+		return 0, fmt.Errorf("client: failed to build request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", "application/json")
+	if c.userAgent != "" {
+		req.Header.Set("User-Agent", c.userAgent)
+	}
+	if c.apiKey != "" {
+		req.Header.Set(apiKeyHeader, c.apiKey)
+	}
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return 0, &TransportError{Err: err}
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		apiErr := newAPIError(resp)
+		return apiErr.RetryAfter, apiErr
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return 0, fmt.Errorf("client: failed to decode response: %w", err)
+	}
+	return 0, nil
+}
+
+// backoff は attempt 回目の失敗後に待つ時間を返す。
+// サーバが Retry-After を返した場合はそれを優先し、それ以外はジッター付きの指数バックオフとする。
+func (c *Client) backoff(attempt int, retryAfter time.Duration) time.Duration {
+	if retryAfter > 0 {
+		return retryAfter
+	}
+	if c.baseDelay <= 0 {
+		return 0
+	}
+	d := c.baseDelay << (attempt - 1)
+	if c.maxDelay > 0 && (d > c.maxDelay || d <= 0) {
+		d = c.maxDelay
+	}
+	return d/2 + rand.N(d/2+1)
+}
+
+// retryable は err が再試行で回復し得るかを判定する。呼び出し元の context が終わっていれば再試行しない。
+func retryable(ctx context.Context, err error) bool {
+	if ctx.Err() != nil {
+		return false
+	}
+	var transportErr *TransportError
+	if errors.As(err, &transportErr) {
+		return true
+	}
+	var apiErr *APIError
+	if errors.As(err, &apiErr) {
+		return apiErr.Temporary()
+	}
+	return false
+}
+
+func sleepContext(ctx context.Context, d time.Duration) error {
+	if d <= 0 {
+		return ctx.Err()
+	}
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-t.C:
+		return nil
+	}
+}
+
+// readErrorBody はエラーレスポンスのボディを上限付きで読み込む。
+func readErrorBody(r io.Reader) []byte {
+	data, _ := io.ReadAll(io.LimitReader(r, maxErrorBodySize))
+	return data
+}
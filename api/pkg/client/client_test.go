@@ -0,0 +1,133 @@
+package client_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/2509-hackz-ichthyo/main/api/pkg/client"
+	"github.com/2509-hackz-ichthyo/main/api/pkg/client/clienttest"
+)
+
+// sentence123 は "1 2 3" を表す Whitespace の文。
+const sentence123 = "      \t\n     \t \n         \t\t\n"
+
+func TestDecodeRoundTrip(t *testing.T) {
+	srv := clienttest.NewServer(t)
+	c := srv.Client()
+
+	whitespace, err := c.DecimalToWhitespace(context.Background(), []string{"1 2 3"})
+	if err != nil {
+		t.Fatalf("DecimalToWhitespace returned error: %v", err)
+	}
+	if len(whitespace) != 1 || whitespace[0] != sentence123 {
+		t.Fatalf("whitespace = %q, want %q", whitespace, sentence123)
+	}
+
+	resp, err := c.Decode(context.Background(), client.DecodeRequest{
+		CommandType: client.CommandTypeWhitespaceToDecimal,
+		Payload:     whitespace,
+	})
+	if err != nil {
+		t.Fatalf("Decode returned error: %v", err)
+	}
+	if resp.ResultKind != "DecimalSequence" || len(resp.ResultDecimals) != 1 || resp.ResultDecimals[0] != "1 2 3" {
+		t.Fatalf("unexpected response: %+v", resp)
+	}
+	if resp.DecimalString == nil || *resp.DecimalString != "1 2 3" {
+		t.Fatalf("DecimalString = %v", resp.DecimalString)
+	}
+
+	if got := srv.Requests(); len(got) != 2 || got[1].CommandType != client.CommandTypeWhitespaceToDecimal {
+		t.Fatalf("Requests = %+v", got)
+	}
+}
+
+func TestDecodeInvalidRequest(t *testing.T) {
+	srv := clienttest.NewServer(t)
+	c := srv.Client()
+
+	_, err := c.WhitespaceToDecimal(context.Background(), []string{"not whitespace"})
+	if !errors.Is(err, client.ErrInvalidRequest) {
+		t.Fatalf("err = %v, want ErrInvalidRequest", err)
+	}
+
+	var apiErr *client.APIError
+	if !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusBadRequest || apiErr.Message == "" || apiErr.Details == "" {
+		t.Fatalf("unexpected APIError: %#v", err)
+	}
+	if got := len(srv.Requests()); got != 1 {
+		t.Fatalf("requests = %d, want 1 (400 must not be retried)", got)
+	}
+}
+
+func TestDecodeRetriesTemporaryErrors(t *testing.T) {
+	srv := clienttest.NewServer(t)
+	c := srv.Client()
+	srv.FailNext(clienttest.Failure{StatusCode: http.StatusServiceUnavailable, Message: "unavailable"})
+	srv.FailNext(clienttest.Failure{StatusCode: http.StatusBadGateway, Message: "bad gateway"})
+
+	decimals, err := c.WhitespaceToDecimal(context.Background(), []string{sentence123})
+	if err != nil {
+		t.Fatalf("WhitespaceToDecimal returned error: %v", err)
+	}
+	if len(decimals) != 1 || decimals[0] != "1 2 3" {
+		t.Fatalf("decimals = %q", decimals)
+	}
+	if got := len(srv.Requests()); got != 3 {
+		t.Fatalf("requests = %d, want 3", got)
+	}
+}
+
+func TestDecodeGivesUpAfterMaxAttempts(t *testing.T) {
+	srv := clienttest.NewServer(t)
+	c := srv.Client(client.WithRetry(2, 0, 0))
+	for range 3 {
+		srv.FailNext(clienttest.Failure{StatusCode: http.StatusTooManyRequests, Message: "slow down", Details: "rate limit exceeded"})
+	}
+
+	_, err := c.WhitespaceToDecimal(context.Background(), []string{sentence123})
+	if !errors.Is(err, client.ErrRateLimited) {
+		t.Fatalf("err = %v, want ErrRateLimited", err)
+	}
+	if !strings.Contains(err.Error(), "slow down (rate limit exceeded)") {
+		t.Fatalf("error message = %q", err.Error())
+	}
+	if got := len(srv.Requests()); got != 2 {
+		t.Fatalf("requests = %d, want 2", got)
+	}
+}
+
+func TestDecodeAuthErrors(t *testing.T) {
+	srv := clienttest.NewServer(t)
+	c := srv.Client(client.WithAPIKey("secret"))
+	srv.FailNext(clienttest.Failure{StatusCode: http.StatusUnauthorized, Message: "API キーが不正です"})
+
+	_, err := c.WhitespaceToDecimal(context.Background(), []string{sentence123})
+	if !errors.Is(err, client.ErrUnauthorized) {
+		t.Fatalf("err = %v, want ErrUnauthorized", err)
+	}
+}
+
+func TestDecodeTransportError(t *testing.T) {
+	srv := clienttest.NewServer(t)
+	c := srv.Client(client.WithTimeout(time.Second))
+	srv.Close()
+
+	_, err := c.WhitespaceToDecimal(context.Background(), []string{sentence123})
+	var transportErr *client.TransportError
+	if !errors.As(err, &transportErr) {
+		t.Fatalf("err = %v, want *TransportError", err)
+	}
+}
+
+func TestNewRejectsInvalidBaseURL(t *testing.T) {
+	for _, raw := range []string{"", "localhost:3000", "ftp://example.com", "http://"} {
+		if _, err := client.New(raw); err == nil {
+			t.Errorf("New(%q) returned nil error", raw)
+		}
+	}
+}
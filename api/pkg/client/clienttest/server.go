@@ -0,0 +1,122 @@
+// Package clienttest は client パッケージの利用者向けに、httptest で動く ws-decode-api の偽サーバを提供する。
+//
+// 偽サーバは本物のルータとユースケースで変換するため、実サーバと同じ結果とエラーレスポンスを返す。
+// FailNext で失敗を差し込めるので、再試行やエラー処理のテストにも使える。
+//
+//	srv := clienttest.NewServer(t)
+//	c := srv.Client()
+//	srv.FailNext(clienttest.Failure{StatusCode: http.StatusServiceUnavailable, Message: "一時的に利用できません"})
+package clienttest
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"testing"
+
+	"github.com/2509-hackz-ichthyo/main/api/internal/app"
+	"github.com/2509-hackz-ichthyo/main/api/internal/server/httpserver"
+	"github.com/2509-hackz-ichthyo/main/api/pkg/client"
+	"github.com/gin-gonic/gin"
+)
+
+// Failure は偽サーバが次のリクエストに返すエラーレスポンス。
+type Failure struct {
+	StatusCode int
+	Message    string
+	Details    string
+	// RetryAfter は Retry-After ヘッダに載せる秒数。0 なら付けない。
+	RetryAfter int
+}
+
+// Server は ws-decode-api の偽サーバ。
+type Server struct {
+	*httptest.Server
+
+	mu       sync.Mutex
+	requests []client.DecodeRequest
+	failures []Failure
+}
+
+// NewServer は偽サーバを起動する。サーバはテスト終了時に停止する。
+func NewServer(t testing.TB) *Server {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+
+	s := &Server{}
+	router := httpserver.NewRouter(app.NewWhitespaceUsecase())
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		s.serve(router, w, r)
+	}))
+	t.Cleanup(s.Close)
+	return s
+}
+
+// Client は偽サーバに向けたクライアントを返す。テストが待たされないよう、再試行の待ち時間は 0 にする。
+func (s *Server) Client(opts ...client.Option) *client.Client {
+	opts = append([]client.Option{
+		client.WithHTTPClient(s.Server.Client()),
+		client.WithRetry(client.DefaultMaxAttempts, 0, 0),
+	}, opts...)
+	c, err := client.New(s.URL, opts...)
+	if err != nil {
+		panic(err)
+	}
+	return c
+}
+
+// FailNext は次の POST /v1/decode に f のエラーレスポンスを返すよう予約する。
+// 複数回呼ぶと、予約した順に 1 リクエストずつ失敗させる。
+func (s *Server) FailNext(f Failure) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.failures = append(s.failures, f)
+}
+
+// Requests は偽サーバが受け取った POST /v1/decode のリクエストを、受け取った順に返す。
+func (s *Server) Requests() []client.DecodeRequest {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]client.DecodeRequest(nil), s.requests...)
+}
+
+func (s *Server) serve(next http.Handler, w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost || r.URL.Path != "/v1/decode" {
+		next.ServeHTTP(w, r)
+		return
+	}
+
+	body, _ := io.ReadAll(r.Body)
+	r.Body = io.NopCloser(bytes.NewReader(body))
+
+	var req client.DecodeRequest
+	_ = json.Unmarshal(body, &req)
+
+	s.mu.Lock()
+	s.requests = append(s.requests, req)
+	var failure *Failure
+	if len(s.failures) > 0 {
+		failure = &s.failures[0]
+		s.failures = s.failures[1:]
+	}
+	s.mu.Unlock()
+
+	if failure == nil {
+		next.ServeHTTP(w, r)
+		return
+	}
+
+	if failure.RetryAfter > 0 {
+		w.Header().Set("Retry-After", strconv.Itoa(failure.RetryAfter))
+	}
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(failure.StatusCode)
+	_ = json.NewEncoder(w).Encode(map[string]string{
+		"error":   failure.Message,
+		"details": failure.Details,
+	})
+}
@@ -0,0 +1,119 @@
+package client
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// API のステータスコードに対応する分類。errors.Is で *APIError と比較できる。
+var (
+	// ErrInvalidRequest は入力が不正（400）であることを表す。再試行しても成功しない。
+	ErrInvalidRequest = errors.New("client: invalid request")
+
+	// ErrUnauthorized は API キーが無い、または不正（401）であることを表す。
+	ErrUnauthorized = errors.New("client: unauthorized")
+
+	// ErrRateLimited はレート制限（429）に達したことを表す。
+	ErrRateLimited = errors.New("client: rate limited")
+
+	// ErrUnavailable はサーバが一時的に利用できない（502・503・504）ことを表す。
+	ErrUnavailable = errors.New("client: service unavailable")
+
+	// ErrServer はその他のサーバ側のエラーを表す。
+	ErrServer = errors.New("client: server error")
+)
+
+// APIError は API が 200 以外を返したことを表す。
+// Message と Details はエラーレスポンス {"error": ..., "details": ...} の各フィールド。
+type APIError struct {
+	StatusCode int
+	Message    string
+	Details    string
+	// RetryAfter はサーバが Retry-After ヘッダで指定した待ち時間。指定が無ければ 0。
+	RetryAfter time.Duration
+	// Body はエラーレスポンスが JSON でなかった場合の生のボディ。
+	Body string
+}
+
+func newAPIError(resp *http.Response) *APIError {
+	apiErr := &APIError{StatusCode: resp.StatusCode}
+	if seconds, err := strconv.Atoi(strings.TrimSpace(resp.Header.Get("Retry-After"))); err == nil && seconds > 0 {
+		apiErr.RetryAfter = time.Duration(seconds) * time.Second
+	}
+
+	body := readErrorBody(resp.Body)
+	var payload struct {
+		Error   string `json:"error"`
+		Details string `json:"details"`
+	}
+	if err := json.Unmarshal(body, &payload); err == nil && payload.Error != "" {
+		apiErr.Message = payload.Error
+		apiErr.Details = payload.Details
+	} else {
+		apiErr.Body = string(body)
+	}
+	return apiErr
+}
+
+func (e *APIError) Error() string {
+	switch {
+	case e.Message != "" && e.Details != "":
+		return fmt.Sprintf("client: decode API returned %d: %s (%s)", e.StatusCode, e.Message, e.Details)
+	case e.Message != "":
+		return fmt.Sprintf("client: decode API returned %d: %s", e.StatusCode, e.Message)
+	case e.Body != "":
+		return fmt.Sprintf("client: decode API returned %d: %s", e.StatusCode, e.Body)
+	default:
+		return fmt.Sprintf("client: decode API returned %d", e.StatusCode)
+	}
+}
+
+// Is はステータスコードに対応する分類のエラーと一致するかを返す。
+func (e *APIError) Is(target error) bool {
+	return target == e.kind()
+}
+
+// Temporary は再試行で回復し得るエラーかを返す。
+func (e *APIError) Temporary() bool {
+	switch e.StatusCode {
+	case http.StatusTooManyRequests, http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	default:
+		return false
+	}
+}
+
+func (e *APIError) kind() error {
+	switch {
+	case e.StatusCode == http.StatusBadRequest:
+		return ErrInvalidRequest
+	case e.StatusCode == http.StatusUnauthorized:
+		return ErrUnauthorized
+	case e.StatusCode == http.StatusTooManyRequests:
+		return ErrRateLimited
+	case e.Temporary():
+		return ErrUnavailable
+	case e.StatusCode >= http.StatusInternalServerError:
+		return ErrServer
+	default:
+		return nil
+	}
+}
+
+// TransportError は接続失敗やタイムアウトなど、レスポンスを受け取れなかったことを表す。
+type TransportError struct {
+	Err error
+}
+
+func (e *TransportError) Error() string {
+	return fmt.Sprintf("client: failed to call decode API: %v", e.Err)
+}
+
+func (e *TransportError) Unwrap() error {
+	return e.Err
+}
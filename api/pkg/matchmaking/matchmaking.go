@@ -0,0 +1,90 @@
+// Package matchmaking は待機中のプレイヤーから対戦相手を選ぶ。
+//
+// レーティングの差が許容幅に収まる相手とだけ組ませる。許容幅は待った時間に応じて広がるため、
+// 近い強さの相手がいなくても、待ち続ければいずれ誰かと対戦できる。
+// 許容幅に収まる相手が複数いる場合は、最も長く待っている相手を選ぶ。
+//
+// DynamoDB などの外部依存を持たない純粋な関数として実装し、matchmaking_handler（Lambda）から使う。
+package matchmaking
+
+import (
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/2509-hackz-ichthyo/main/api/pkg/rating"
+)
+
+// DefaultRating はレーティングが記録されていないプレイヤーのレーティング（rating.Initial と同じ）。
+const DefaultRating = rating.Initial
+
+// Candidate は待機列に並んでいる（または並ぼうとしている）プレイヤー。
+type Candidate struct {
+	UserID   string
+	Rating   float64
+	JoinedAt time.Time
+}
+
+// Policy はレーティングの許容幅の決め方。
+type Policy struct {
+	InitialWindow float64 // 待ち始めた直後の許容幅（レーティングの差の上限）
+	WidenPerSec   float64 // 1 秒待つごとに広がる幅
+	MaxWindow     float64 // 許容幅の上限（0 以下なら上限なし）
+}
+
+// DefaultPolicy は ±100 から始めて 1 秒ごとに 5 ずつ広げ、±1000 で止まる許容幅。
+func DefaultPolicy() Policy {
+	return Policy{InitialWindow: 100, WidenPerSec: 5, MaxWindow: 1000}
+}
+
+// Window は waited だけ待ったプレイヤーの許容幅を返す。
+func (p Policy) Window(waited time.Duration) float64 {
+	if waited < 0 {
+		waited = 0
+	}
+	w := p.InitialWindow + p.WidenPerSec*waited.Seconds()
+	if p.MaxWindow > 0 && w > p.MaxWindow {
+		w = p.MaxWindow
+	}
+	return w
+}
+
+// Match は選ばれた対戦相手と、その相手を選んだ理由。
+type Match struct {
+	Opponent   Candidate
+	Difference float64       // レーティングの差（絶対値）
+	Window     float64       // 適用した許容幅
+	Waited     time.Duration // 許容幅を決めた側（長く待っていた側）の待ち時間
+}
+
+// Explanation は選んだ理由を表示用の文章で返す。
+func (m Match) Explanation() string {
+	return fmt.Sprintf("レーティング差 %.0f（許容幅 ±%.0f、待機 %d 秒）", m.Difference, m.Window, int(m.Waited.Seconds()))
+}
+
+// FindOpponent は queue の中から player の対戦相手を選ぶ。
+// 2 人のうち長く待っている側の許容幅にレーティングの差が収まる相手だけを候補とし、
+// その中で最も早く並んだ相手を返す。player 自身（同じ UserID）は候補にしない。
+// 候補がいなければ false を返す。
+func FindOpponent(p Policy, player Candidate, queue []Candidate, now time.Time) (Match, bool) {
+	var best Match
+	found := false
+	for _, c := range queue {
+		if c.UserID == player.UserID {
+			continue
+		}
+
+		waited := max(now.Sub(c.JoinedAt), now.Sub(player.JoinedAt))
+		window := p.Window(waited)
+		diff := math.Abs(player.Rating - c.Rating)
+		if diff > window {
+			continue
+		}
+
+		if !found || c.JoinedAt.Before(best.Opponent.JoinedAt) {
+			best = Match{Opponent: c, Difference: diff, Window: window, Waited: waited}
+			found = true
+		}
+	}
+	return best, found
+}
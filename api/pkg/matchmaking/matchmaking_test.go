@@ -0,0 +1,87 @@
+package matchmaking
+
+import (
+	"testing"
+	"time"
+)
+
+func TestPolicyWindow(t *testing.T) {
+	p := DefaultPolicy()
+
+	cases := map[time.Duration]float64{
+		-time.Second:     100,
+		0:                100,
+		10 * time.Second: 150,
+		time.Minute:      400,
+		time.Hour:        1000,
+	}
+	for waited, want := range cases {
+		if got := p.Window(waited); got != want {
+			t.Fatalf("Window(%v) = %v, want %v", waited, got, want)
+		}
+	}
+
+	if got := (Policy{InitialWindow: 100, WidenPerSec: 5}).Window(time.Hour); got != 18100 {
+		t.Fatalf("Window without max = %v, want 18100", got)
+	}
+}
+
+func TestFindOpponent(t *testing.T) {
+	now := time.Date(2025, 9, 1, 12, 0, 0, 0, time.UTC)
+	p := DefaultPolicy()
+	player := Candidate{UserID: "new", Rating: 1500, JoinedAt: now}
+
+	t.Run("empty queue", func(t *testing.T) {
+		if _, ok := FindOpponent(p, player, nil, now); ok {
+			t.Fatal("FindOpponent found an opponent in an empty queue")
+		}
+	})
+
+	t.Run("outside window", func(t *testing.T) {
+		queue := []Candidate{{UserID: "strong", Rating: 1800, JoinedAt: now.Add(-10 * time.Second)}}
+		if m, ok := FindOpponent(p, player, queue, now); ok {
+			t.Fatalf("FindOpponent = %+v, want no match (window is 150)", m)
+		}
+	})
+
+	t.Run("window widens with waiting time", func(t *testing.T) {
+		queue := []Candidate{{UserID: "strong", Rating: 1800, JoinedAt: now.Add(-time.Minute)}}
+		m, ok := FindOpponent(p, player, queue, now)
+		if !ok {
+			t.Fatal("FindOpponent found no opponent, want strong (window is 400)")
+		}
+		if m.Opponent.UserID != "strong" || m.Difference != 300 || m.Window != 400 || m.Waited != time.Minute {
+			t.Fatalf("unexpected match: %+v", m)
+		}
+		if got, want := m.Explanation(), "レーティング差 300（許容幅 ±400、待機 60 秒）"; got != want {
+			t.Fatalf("Explanation() = %q, want %q", got, want)
+		}
+	})
+
+	t.Run("oldest eligible wins", func(t *testing.T) {
+		queue := []Candidate{
+			{UserID: "far", Rating: 2400, JoinedAt: now.Add(-2 * time.Minute)},
+			{UserID: "close", Rating: 1510, JoinedAt: now.Add(-5 * time.Second)},
+			{UserID: "older", Rating: 1450, JoinedAt: now.Add(-30 * time.Second)},
+		}
+		m, ok := FindOpponent(p, player, queue, now)
+		if !ok || m.Opponent.UserID != "older" {
+			t.Fatalf("FindOpponent = %+v, %v; want older", m, ok)
+		}
+	})
+
+	t.Run("never matches self", func(t *testing.T) {
+		queue := []Candidate{{UserID: "new", Rating: 1500, JoinedAt: now.Add(-time.Minute)}}
+		if m, ok := FindOpponent(p, player, queue, now); ok {
+			t.Fatalf("FindOpponent = %+v, want no match", m)
+		}
+	})
+
+	t.Run("waiting player's own time counts", func(t *testing.T) {
+		waiting := Candidate{UserID: "waiting", Rating: 1500, JoinedAt: now.Add(-time.Minute)}
+		queue := []Candidate{{UserID: "strong", Rating: 1800, JoinedAt: now}}
+		if _, ok := FindOpponent(p, waiting, queue, now); !ok {
+			t.Fatal("FindOpponent found no opponent, want strong (window is 400)")
+		}
+	})
+}
@@ -0,0 +1,542 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.35.1
+// 	protoc        (unknown)
+// source: wsdecode/v1/wsdecode.proto
+
+package wsdecodev1
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	reflect "reflect"
+	sync "sync"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+// DecodeRequest は変換命令を表す。
+// HTTP API と異なり、Whitespace のペイロードはパーセントエンコードせずにそのまま渡す。
+type DecodeRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// command_type は WhitespaceToDecimal などの命令種別。
+	CommandType string `protobuf:"bytes,1,opt,name=command_type,json=commandType,proto3" json:"command_type,omitempty"`
+	// payload は 1 要素 1 文のペイロード。
+	Payload []string `protobuf:"bytes,2,rep,name=payload,proto3" json:"payload,omitempty"`
+}
+
+func (x *DecodeRequest) Reset() {
+	*x = DecodeRequest{}
+	mi := &file_wsdecode_v1_wsdecode_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DecodeRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DecodeRequest) ProtoMessage() {}
+
+func (x *DecodeRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_wsdecode_v1_wsdecode_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DecodeRequest.ProtoReflect.Descriptor instead.
+func (*DecodeRequest) Descriptor() ([]byte, []int) {
+	return file_wsdecode_v1_wsdecode_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *DecodeRequest) GetCommandType() string {
+	if x != nil {
+		return x.CommandType
+	}
+	return ""
+}
+
+func (x *DecodeRequest) GetPayload() []string {
+	if x != nil {
+		return x.Payload
+	}
+	return nil
+}
+
+// DecodeResponse は変換結果を表す。result_kind に応じていずれかの result_* が設定される。
+type DecodeResponse struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	CommandType                    string   `protobuf:"bytes,1,opt,name=command_type,json=commandType,proto3" json:"command_type,omitempty"`
+	ResultKind                     string   `protobuf:"bytes,2,opt,name=result_kind,json=resultKind,proto3" json:"result_kind,omitempty"`
+	ResultDecimals                 []string `protobuf:"bytes,3,rep,name=result_decimals,json=resultDecimals,proto3" json:"result_decimals,omitempty"`
+	ResultBinaries                 []string `protobuf:"bytes,4,rep,name=result_binaries,json=resultBinaries,proto3" json:"result_binaries,omitempty"`
+	ResultWhitespace               []string `protobuf:"bytes,5,rep,name=result_whitespace,json=resultWhitespace,proto3" json:"result_whitespace,omitempty"`
+	ResultWhitespacePercentEncoded []string `protobuf:"bytes,6,rep,name=result_whitespace_percent_encoded,json=resultWhitespacePercentEncoded,proto3" json:"result_whitespace_percent_encoded,omitempty"`
+}
+
+func (x *DecodeResponse) Reset() {
+	*x = DecodeResponse{}
+	mi := &file_wsdecode_v1_wsdecode_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DecodeResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DecodeResponse) ProtoMessage() {}
+
+func (x *DecodeResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_wsdecode_v1_wsdecode_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DecodeResponse.ProtoReflect.Descriptor instead.
+func (*DecodeResponse) Descriptor() ([]byte, []int) {
+	return file_wsdecode_v1_wsdecode_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *DecodeResponse) GetCommandType() string {
+	if x != nil {
+		return x.CommandType
+	}
+	return ""
+}
+
+func (x *DecodeResponse) GetResultKind() string {
+	if x != nil {
+		return x.ResultKind
+	}
+	return ""
+}
+
+func (x *DecodeResponse) GetResultDecimals() []string {
+	if x != nil {
+		return x.ResultDecimals
+	}
+	return nil
+}
+
+func (x *DecodeResponse) GetResultBinaries() []string {
+	if x != nil {
+		return x.ResultBinaries
+	}
+	return nil
+}
+
+func (x *DecodeResponse) GetResultWhitespace() []string {
+	if x != nil {
+		return x.ResultWhitespace
+	}
+	return nil
+}
+
+func (x *DecodeResponse) GetResultWhitespacePercentEncoded() []string {
+	if x != nil {
+		return x.ResultWhitespacePercentEncoded
+	}
+	return nil
+}
+
+type BatchDecodeRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Requests []*DecodeRequest `protobuf:"bytes,1,rep,name=requests,proto3" json:"requests,omitempty"`
+}
+
+func (x *BatchDecodeRequest) Reset() {
+	*x = BatchDecodeRequest{}
+	mi := &file_wsdecode_v1_wsdecode_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *BatchDecodeRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*BatchDecodeRequest) ProtoMessage() {}
+
+func (x *BatchDecodeRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_wsdecode_v1_wsdecode_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use BatchDecodeRequest.ProtoReflect.Descriptor instead.
+func (*BatchDecodeRequest) Descriptor() ([]byte, []int) {
+	return file_wsdecode_v1_wsdecode_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *BatchDecodeRequest) GetRequests() []*DecodeRequest {
+	if x != nil {
+		return x.Requests
+	}
+	return nil
+}
+
+type BatchDecodeResponse struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// results は requests と同じ順序で並ぶ。
+	Results []*BatchDecodeResult `protobuf:"bytes,1,rep,name=results,proto3" json:"results,omitempty"`
+}
+
+func (x *BatchDecodeResponse) Reset() {
+	*x = BatchDecodeResponse{}
+	mi := &file_wsdecode_v1_wsdecode_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *BatchDecodeResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*BatchDecodeResponse) ProtoMessage() {}
+
+func (x *BatchDecodeResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_wsdecode_v1_wsdecode_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use BatchDecodeResponse.ProtoReflect.Descriptor instead.
+func (*BatchDecodeResponse) Descriptor() ([]byte, []int) {
+	return file_wsdecode_v1_wsdecode_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *BatchDecodeResponse) GetResults() []*BatchDecodeResult {
+	if x != nil {
+		return x.Results
+	}
+	return nil
+}
+
+// BatchDecodeResult は 1 件の命令の変換結果、または失敗理由を表す。
+type BatchDecodeResult struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// index は BatchDecodeRequest.requests 内の位置。
+	Index uint32 `protobuf:"varint,1,opt,name=index,proto3" json:"index,omitempty"`
+	// Types that are assignable to Outcome:
+	//	*BatchDecodeResult_Response
+	//	*BatchDecodeResult_Error
+	Outcome isBatchDecodeResult_Outcome `protobuf_oneof:"outcome"`
+}
+
+func (x *BatchDecodeResult) Reset() {
+	*x = BatchDecodeResult{}
+	mi := &file_wsdecode_v1_wsdecode_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *BatchDecodeResult) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*BatchDecodeResult) ProtoMessage() {}
+
+func (x *BatchDecodeResult) ProtoReflect() protoreflect.Message {
+	mi := &file_wsdecode_v1_wsdecode_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use BatchDecodeResult.ProtoReflect.Descriptor instead.
+func (*BatchDecodeResult) Descriptor() ([]byte, []int) {
+	return file_wsdecode_v1_wsdecode_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *BatchDecodeResult) GetIndex() uint32 {
+	if x != nil {
+		return x.Index
+	}
+	return 0
+}
+
+func (m *BatchDecodeResult) GetOutcome() isBatchDecodeResult_Outcome {
+	if m != nil {
+		return m.Outcome
+	}
+	return nil
+}
+
+func (x *BatchDecodeResult) GetResponse() *DecodeResponse {
+	if x, ok := x.GetOutcome().(*BatchDecodeResult_Response); ok {
+		return x.Response
+	}
+	return nil
+}
+
+func (x *BatchDecodeResult) GetError() *DecodeError {
+	if x, ok := x.GetOutcome().(*BatchDecodeResult_Error); ok {
+		return x.Error
+	}
+	return nil
+}
+
+type isBatchDecodeResult_Outcome interface {
+	isBatchDecodeResult_Outcome()
+}
+
+type BatchDecodeResult_Response struct {
+	Response *DecodeResponse `protobuf:"bytes,2,opt,name=response,proto3,oneof"`
+}
+
+type BatchDecodeResult_Error struct {
+	Error *DecodeError `protobuf:"bytes,3,opt,name=error,proto3,oneof"`
+}
+
+func (*BatchDecodeResult_Response) isBatchDecodeResult_Outcome() {}
+
+func (*BatchDecodeResult_Error) isBatchDecodeResult_Outcome() {}
+
+// DecodeError は命令ごとの失敗理由を表す。
+type DecodeError struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// code は gRPC のステータスコード名（INVALID_ARGUMENT など）。
+	Code    string `protobuf:"bytes,1,opt,name=code,proto3" json:"code,omitempty"`
+	Message string `protobuf:"bytes,2,opt,name=message,proto3" json:"message,omitempty"`
+}
+
+func (x *DecodeError) Reset() {
+	*x = DecodeError{}
+	mi := &file_wsdecode_v1_wsdecode_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DecodeError) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DecodeError) ProtoMessage() {}
+
+func (x *DecodeError) ProtoReflect() protoreflect.Message {
+	mi := &file_wsdecode_v1_wsdecode_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DecodeError.ProtoReflect.Descriptor instead.
+func (*DecodeError) Descriptor() ([]byte, []int) {
+	return file_wsdecode_v1_wsdecode_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *DecodeError) GetCode() string {
+	if x != nil {
+		return x.Code
+	}
+	return ""
+}
+
+func (x *DecodeError) GetMessage() string {
+	if x != nil {
+		return x.Message
+	}
+	return ""
+}
+
+var File_wsdecode_v1_wsdecode_proto protoreflect.FileDescriptor
+
+var file_wsdecode_v1_wsdecode_proto_rawDesc = []byte{
+	0x0a, 0x1a, 0x77, 0x73, 0x64, 0x65, 0x63, 0x6f, 0x64, 0x65, 0x2f, 0x76, 0x31, 0x2f, 0x77, 0x73,
+	0x64, 0x65, 0x63, 0x6f, 0x64, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x12, 0x0b, 0x77, 0x73,
+	0x64, 0x65, 0x63, 0x6f, 0x64, 0x65, 0x2e, 0x76, 0x31, 0x22, 0x4c, 0x0a, 0x0d, 0x44, 0x65, 0x63,
+	0x6f, 0x64, 0x65, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x21, 0x0a, 0x0c, 0x63, 0x6f,
+	0x6d, 0x6d, 0x61, 0x6e, 0x64, 0x5f, 0x74, 0x79, 0x70, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09,
+	0x52, 0x0b, 0x63, 0x6f, 0x6d, 0x6d, 0x61, 0x6e, 0x64, 0x54, 0x79, 0x70, 0x65, 0x12, 0x18, 0x0a,
+	0x07, 0x70, 0x61, 0x79, 0x6c, 0x6f, 0x61, 0x64, 0x18, 0x02, 0x20, 0x03, 0x28, 0x09, 0x52, 0x07,
+	0x70, 0x61, 0x79, 0x6c, 0x6f, 0x61, 0x64, 0x22, 0x9e, 0x02, 0x0a, 0x0e, 0x44, 0x65, 0x63, 0x6f,
+	0x64, 0x65, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x21, 0x0a, 0x0c, 0x63, 0x6f,
+	0x6d, 0x6d, 0x61, 0x6e, 0x64, 0x5f, 0x74, 0x79, 0x70, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09,
+	0x52, 0x0b, 0x63, 0x6f, 0x6d, 0x6d, 0x61, 0x6e, 0x64, 0x54, 0x79, 0x70, 0x65, 0x12, 0x1f, 0x0a,
+	0x0b, 0x72, 0x65, 0x73, 0x75, 0x6c, 0x74, 0x5f, 0x6b, 0x69, 0x6e, 0x64, 0x18, 0x02, 0x20, 0x01,
+	0x28, 0x09, 0x52, 0x0a, 0x72, 0x65, 0x73, 0x75, 0x6c, 0x74, 0x4b, 0x69, 0x6e, 0x64, 0x12, 0x27,
+	0x0a, 0x0f, 0x72, 0x65, 0x73, 0x75, 0x6c, 0x74, 0x5f, 0x64, 0x65, 0x63, 0x69, 0x6d, 0x61, 0x6c,
+	0x73, 0x18, 0x03, 0x20, 0x03, 0x28, 0x09, 0x52, 0x0e, 0x72, 0x65, 0x73, 0x75, 0x6c, 0x74, 0x44,
+	0x65, 0x63, 0x69, 0x6d, 0x61, 0x6c, 0x73, 0x12, 0x27, 0x0a, 0x0f, 0x72, 0x65, 0x73, 0x75, 0x6c,
+	0x74, 0x5f, 0x62, 0x69, 0x6e, 0x61, 0x72, 0x69, 0x65, 0x73, 0x18, 0x04, 0x20, 0x03, 0x28, 0x09,
+	0x52, 0x0e, 0x72, 0x65, 0x73, 0x75, 0x6c, 0x74, 0x42, 0x69, 0x6e, 0x61, 0x72, 0x69, 0x65, 0x73,
+	0x12, 0x2b, 0x0a, 0x11, 0x72, 0x65, 0x73, 0x75, 0x6c, 0x74, 0x5f, 0x77, 0x68, 0x69, 0x74, 0x65,
+	0x73, 0x70, 0x61, 0x63, 0x65, 0x18, 0x05, 0x20, 0x03, 0x28, 0x09, 0x52, 0x10, 0x72, 0x65, 0x73,
+	0x75, 0x6c, 0x74, 0x57, 0x68, 0x69, 0x74, 0x65, 0x73, 0x70, 0x61, 0x63, 0x65, 0x12, 0x49, 0x0a,
+	0x21, 0x72, 0x65, 0x73, 0x75, 0x6c, 0x74, 0x5f, 0x77, 0x68, 0x69, 0x74, 0x65, 0x73, 0x70, 0x61,
+	0x63, 0x65, 0x5f, 0x70, 0x65, 0x72, 0x63, 0x65, 0x6e, 0x74, 0x5f, 0x65, 0x6e, 0x63, 0x6f, 0x64,
+	0x65, 0x64, 0x18, 0x06, 0x20, 0x03, 0x28, 0x09, 0x52, 0x1e, 0x72, 0x65, 0x73, 0x75, 0x6c, 0x74,
+	0x57, 0x68, 0x69, 0x74, 0x65, 0x73, 0x70, 0x61, 0x63, 0x65, 0x50, 0x65, 0x72, 0x63, 0x65, 0x6e,
+	0x74, 0x45, 0x6e, 0x63, 0x6f, 0x64, 0x65, 0x64, 0x22, 0x4c, 0x0a, 0x12, 0x42, 0x61, 0x74, 0x63,
+	0x68, 0x44, 0x65, 0x63, 0x6f, 0x64, 0x65, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x36,
+	0x0a, 0x08, 0x72, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x73, 0x18, 0x01, 0x20, 0x03, 0x28, 0x0b,
+	0x32, 0x1a, 0x2e, 0x77, 0x73, 0x64, 0x65, 0x63, 0x6f, 0x64, 0x65, 0x2e, 0x76, 0x31, 0x2e, 0x44,
+	0x65, 0x63, 0x6f, 0x64, 0x65, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x52, 0x08, 0x72, 0x65,
+	0x71, 0x75, 0x65, 0x73, 0x74, 0x73, 0x22, 0x4f, 0x0a, 0x13, 0x42, 0x61, 0x74, 0x63, 0x68, 0x44,
+	0x65, 0x63, 0x6f, 0x64, 0x65, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x38, 0x0a,
+	0x07, 0x72, 0x65, 0x73, 0x75, 0x6c, 0x74, 0x73, 0x18, 0x01, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x1e,
+	0x2e, 0x77, 0x73, 0x64, 0x65, 0x63, 0x6f, 0x64, 0x65, 0x2e, 0x76, 0x31, 0x2e, 0x42, 0x61, 0x74,
+	0x63, 0x68, 0x44, 0x65, 0x63, 0x6f, 0x64, 0x65, 0x52, 0x65, 0x73, 0x75, 0x6c, 0x74, 0x52, 0x07,
+	0x72, 0x65, 0x73, 0x75, 0x6c, 0x74, 0x73, 0x22, 0xa1, 0x01, 0x0a, 0x11, 0x42, 0x61, 0x74, 0x63,
+	0x68, 0x44, 0x65, 0x63, 0x6f, 0x64, 0x65, 0x52, 0x65, 0x73, 0x75, 0x6c, 0x74, 0x12, 0x14, 0x0a,
+	0x05, 0x69, 0x6e, 0x64, 0x65, 0x78, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x05, 0x69, 0x6e,
+	0x64, 0x65, 0x78, 0x12, 0x39, 0x0a, 0x08, 0x72, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x18,
+	0x02, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1b, 0x2e, 0x77, 0x73, 0x64, 0x65, 0x63, 0x6f, 0x64, 0x65,
+	0x2e, 0x76, 0x31, 0x2e, 0x44, 0x65, 0x63, 0x6f, 0x64, 0x65, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e,
+	0x73, 0x65, 0x48, 0x00, 0x52, 0x08, 0x72, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x30,
+	0x0a, 0x05, 0x65, 0x72, 0x72, 0x6f, 0x72, 0x18, 0x03, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x18, 0x2e,
+	0x77, 0x73, 0x64, 0x65, 0x63, 0x6f, 0x64, 0x65, 0x2e, 0x76, 0x31, 0x2e, 0x44, 0x65, 0x63, 0x6f,
+	0x64, 0x65, 0x45, 0x72, 0x72, 0x6f, 0x72, 0x48, 0x00, 0x52, 0x05, 0x65, 0x72, 0x72, 0x6f, 0x72,
+	0x42, 0x09, 0x0a, 0x07, 0x6f, 0x75, 0x74, 0x63, 0x6f, 0x6d, 0x65, 0x22, 0x3b, 0x0a, 0x0b, 0x44,
+	0x65, 0x63, 0x6f, 0x64, 0x65, 0x45, 0x72, 0x72, 0x6f, 0x72, 0x12, 0x12, 0x0a, 0x04, 0x63, 0x6f,
+	0x64, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x63, 0x6f, 0x64, 0x65, 0x12, 0x18,
+	0x0a, 0x07, 0x6d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52,
+	0x07, 0x6d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x32, 0xfb, 0x01, 0x0a, 0x11, 0x57, 0x68, 0x69,
+	0x74, 0x65, 0x73, 0x70, 0x61, 0x63, 0x65, 0x53, 0x65, 0x72, 0x76, 0x69, 0x63, 0x65, 0x12, 0x41,
+	0x0a, 0x06, 0x44, 0x65, 0x63, 0x6f, 0x64, 0x65, 0x12, 0x1a, 0x2e, 0x77, 0x73, 0x64, 0x65, 0x63,
+	0x6f, 0x64, 0x65, 0x2e, 0x76, 0x31, 0x2e, 0x44, 0x65, 0x63, 0x6f, 0x64, 0x65, 0x52, 0x65, 0x71,
+	0x75, 0x65, 0x73, 0x74, 0x1a, 0x1b, 0x2e, 0x77, 0x73, 0x64, 0x65, 0x63, 0x6f, 0x64, 0x65, 0x2e,
+	0x76, 0x31, 0x2e, 0x44, 0x65, 0x63, 0x6f, 0x64, 0x65, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73,
+	0x65, 0x12, 0x50, 0x0a, 0x0b, 0x42, 0x61, 0x74, 0x63, 0x68, 0x44, 0x65, 0x63, 0x6f, 0x64, 0x65,
+	0x12, 0x1f, 0x2e, 0x77, 0x73, 0x64, 0x65, 0x63, 0x6f, 0x64, 0x65, 0x2e, 0x76, 0x31, 0x2e, 0x42,
+	0x61, 0x74, 0x63, 0x68, 0x44, 0x65, 0x63, 0x6f, 0x64, 0x65, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73,
+	0x74, 0x1a, 0x20, 0x2e, 0x77, 0x73, 0x64, 0x65, 0x63, 0x6f, 0x64, 0x65, 0x2e, 0x76, 0x31, 0x2e,
+	0x42, 0x61, 0x74, 0x63, 0x68, 0x44, 0x65, 0x63, 0x6f, 0x64, 0x65, 0x52, 0x65, 0x73, 0x70, 0x6f,
+	0x6e, 0x73, 0x65, 0x12, 0x51, 0x0a, 0x0c, 0x44, 0x65, 0x63, 0x6f, 0x64, 0x65, 0x53, 0x74, 0x72,
+	0x65, 0x61, 0x6d, 0x12, 0x1f, 0x2e, 0x77, 0x73, 0x64, 0x65, 0x63, 0x6f, 0x64, 0x65, 0x2e, 0x76,
+	0x31, 0x2e, 0x42, 0x61, 0x74, 0x63, 0x68, 0x44, 0x65, 0x63, 0x6f, 0x64, 0x65, 0x52, 0x65, 0x71,
+	0x75, 0x65, 0x73, 0x74, 0x1a, 0x1e, 0x2e, 0x77, 0x73, 0x64, 0x65, 0x63, 0x6f, 0x64, 0x65, 0x2e,
+	0x76, 0x31, 0x2e, 0x42, 0x61, 0x74, 0x63, 0x68, 0x44, 0x65, 0x63, 0x6f, 0x64, 0x65, 0x52, 0x65,
+	0x73, 0x75, 0x6c, 0x74, 0x30, 0x01, 0x42, 0x46, 0x5a, 0x44, 0x67, 0x69, 0x74, 0x68, 0x75, 0x62,
+	0x2e, 0x63, 0x6f, 0x6d, 0x2f, 0x32, 0x35, 0x30, 0x39, 0x2d, 0x68, 0x61, 0x63, 0x6b, 0x7a, 0x2d,
+	0x69, 0x63, 0x68, 0x74, 0x68, 0x79, 0x6f, 0x2f, 0x6d, 0x61, 0x69, 0x6e, 0x2f, 0x61, 0x70, 0x69,
+	0x2f, 0x70, 0x6b, 0x67, 0x2f, 0x70, 0x62, 0x2f, 0x77, 0x73, 0x64, 0x65, 0x63, 0x6f, 0x64, 0x65,
+	0x2f, 0x76, 0x31, 0x3b, 0x77, 0x73, 0x64, 0x65, 0x63, 0x6f, 0x64, 0x65, 0x76, 0x31, 0x62, 0x06,
+	0x70, 0x72, 0x6f, 0x74, 0x6f, 0x33,
+}
+
+var (
+	file_wsdecode_v1_wsdecode_proto_rawDescOnce sync.Once
+	file_wsdecode_v1_wsdecode_proto_rawDescData = file_wsdecode_v1_wsdecode_proto_rawDesc
+)
+
+func file_wsdecode_v1_wsdecode_proto_rawDescGZIP() []byte {
+	file_wsdecode_v1_wsdecode_proto_rawDescOnce.Do(func() {
+		file_wsdecode_v1_wsdecode_proto_rawDescData = protoimpl.X.CompressGZIP(file_wsdecode_v1_wsdecode_proto_rawDescData)
+	})
+	return file_wsdecode_v1_wsdecode_proto_rawDescData
+}
+
+var file_wsdecode_v1_wsdecode_proto_msgTypes = make([]protoimpl.MessageInfo, 6)
+var file_wsdecode_v1_wsdecode_proto_goTypes = []any{
+	(*DecodeRequest)(nil),       // 0: wsdecode.v1.DecodeRequest
+	(*DecodeResponse)(nil),      // 1: wsdecode.v1.DecodeResponse
+	(*BatchDecodeRequest)(nil),  // 2: wsdecode.v1.BatchDecodeRequest
+	(*BatchDecodeResponse)(nil), // 3: wsdecode.v1.BatchDecodeResponse
+	(*BatchDecodeResult)(nil),   // 4: wsdecode.v1.BatchDecodeResult
+	(*DecodeError)(nil),         // 5: wsdecode.v1.DecodeError
+}
+var file_wsdecode_v1_wsdecode_proto_depIdxs = []int32{
+	0, // 0: wsdecode.v1.BatchDecodeRequest.requests:type_name -> wsdecode.v1.DecodeRequest
+	4, // 1: wsdecode.v1.BatchDecodeResponse.results:type_name -> wsdecode.v1.BatchDecodeResult
+	1, // 2: wsdecode.v1.BatchDecodeResult.response:type_name -> wsdecode.v1.DecodeResponse
+	5, // 3: wsdecode.v1.BatchDecodeResult.error:type_name -> wsdecode.v1.DecodeError
+	0, // 4: wsdecode.v1.WhitespaceService.Decode:input_type -> wsdecode.v1.DecodeRequest
+	2, // 5: wsdecode.v1.WhitespaceService.BatchDecode:input_type -> wsdecode.v1.BatchDecodeRequest
+	2, // 6: wsdecode.v1.WhitespaceService.DecodeStream:input_type -> wsdecode.v1.BatchDecodeRequest
+	1, // 7: wsdecode.v1.WhitespaceService.Decode:output_type -> wsdecode.v1.DecodeResponse
+	3, // 8: wsdecode.v1.WhitespaceService.BatchDecode:output_type -> wsdecode.v1.BatchDecodeResponse
+	4, // 9: wsdecode.v1.WhitespaceService.DecodeStream:output_type -> wsdecode.v1.BatchDecodeResult
+	7, // [7:10] is the sub-list for method output_type
+	4, // [4:7] is the sub-list for method input_type
+	4, // [4:4] is the sub-list for extension type_name
+	4, // [4:4] is the sub-list for extension extendee
+	0, // [0:4] is the sub-list for field type_name
+}
+
+func init() { file_wsdecode_v1_wsdecode_proto_init() }
+func file_wsdecode_v1_wsdecode_proto_init() {
+	if File_wsdecode_v1_wsdecode_proto != nil {
+		return
+	}
+	file_wsdecode_v1_wsdecode_proto_msgTypes[4].OneofWrappers = []any{
+		(*BatchDecodeResult_Response)(nil),
+		(*BatchDecodeResult_Error)(nil),
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: file_wsdecode_v1_wsdecode_proto_rawDesc,
+			NumEnums:      0,
+			NumMessages:   6,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_wsdecode_v1_wsdecode_proto_goTypes,
+		DependencyIndexes: file_wsdecode_v1_wsdecode_proto_depIdxs,
+		MessageInfos:      file_wsdecode_v1_wsdecode_proto_msgTypes,
+	}.Build()
+	File_wsdecode_v1_wsdecode_proto = out.File
+	file_wsdecode_v1_wsdecode_proto_rawDesc = nil
+	file_wsdecode_v1_wsdecode_proto_goTypes = nil
+	file_wsdecode_v1_wsdecode_proto_depIdxs = nil
+}
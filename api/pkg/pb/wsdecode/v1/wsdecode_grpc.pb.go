@@ -0,0 +1,213 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.5.1
+// - protoc             (unknown)
+// source: wsdecode/v1/wsdecode.proto
+
+package wsdecodev1
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.64.0 or later.
+const _ = grpc.SupportPackageIsVersion9
+
+const (
+	WhitespaceService_Decode_FullMethodName       = "/wsdecode.v1.WhitespaceService/Decode"
+	WhitespaceService_BatchDecode_FullMethodName  = "/wsdecode.v1.WhitespaceService/BatchDecode"
+	WhitespaceService_DecodeStream_FullMethodName = "/wsdecode.v1.WhitespaceService/DecodeStream"
+)
+
+// WhitespaceServiceClient is the client API for WhitespaceService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+//
+// WhitespaceService は POST /v1/decode と同じ変換を gRPC で提供する。
+type WhitespaceServiceClient interface {
+	// Decode は 1 件の命令を変換する。
+	Decode(ctx context.Context, in *DecodeRequest, opts ...grpc.CallOption) (*DecodeResponse, error)
+	// BatchDecode は複数の命令をまとめて変換する。
+	// 命令ごとの失敗は結果の error に格納し、RPC 自体は成功として返す。
+	BatchDecode(ctx context.Context, in *BatchDecodeRequest, opts ...grpc.CallOption) (*BatchDecodeResponse, error)
+	// DecodeStream は BatchDecode と同じ命令を 1 件ずつ変換し、変換した順に結果を返す。
+	DecodeStream(ctx context.Context, in *BatchDecodeRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[BatchDecodeResult], error)
+}
+
+type whitespaceServiceClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewWhitespaceServiceClient(cc grpc.ClientConnInterface) WhitespaceServiceClient {
+	return &whitespaceServiceClient{cc}
+}
+
+func (c *whitespaceServiceClient) Decode(ctx context.Context, in *DecodeRequest, opts ...grpc.CallOption) (*DecodeResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(DecodeResponse)
+	err := c.cc.Invoke(ctx, WhitespaceService_Decode_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *whitespaceServiceClient) BatchDecode(ctx context.Context, in *BatchDecodeRequest, opts ...grpc.CallOption) (*BatchDecodeResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(BatchDecodeResponse)
+	err := c.cc.Invoke(ctx, WhitespaceService_BatchDecode_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *whitespaceServiceClient) DecodeStream(ctx context.Context, in *BatchDecodeRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[BatchDecodeResult], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &WhitespaceService_ServiceDesc.Streams[0], WhitespaceService_DecodeStream_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[BatchDecodeRequest, BatchDecodeResult]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type WhitespaceService_DecodeStreamClient = grpc.ServerStreamingClient[BatchDecodeResult]
+
+// WhitespaceServiceServer is the server API for WhitespaceService service.
+// All implementations must embed UnimplementedWhitespaceServiceServer
+// for forward compatibility.
+//
+// WhitespaceService は POST /v1/decode と同じ変換を gRPC で提供する。
+type WhitespaceServiceServer interface {
+	// Decode は 1 件の命令を変換する。
+	Decode(context.Context, *DecodeRequest) (*DecodeResponse, error)
+	// BatchDecode は複数の命令をまとめて変換する。
+	// 命令ごとの失敗は結果の error に格納し、RPC 自体は成功として返す。
+	BatchDecode(context.Context, *BatchDecodeRequest) (*BatchDecodeResponse, error)
+	// DecodeStream は BatchDecode と同じ命令を 1 件ずつ変換し、変換した順に結果を返す。
+	DecodeStream(*BatchDecodeRequest, grpc.ServerStreamingServer[BatchDecodeResult]) error
+	mustEmbedUnimplementedWhitespaceServiceServer()
+}
+
+// UnimplementedWhitespaceServiceServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedWhitespaceServiceServer struct{}
+
+func (UnimplementedWhitespaceServiceServer) Decode(context.Context, *DecodeRequest) (*DecodeResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Decode not implemented")
+}
+func (UnimplementedWhitespaceServiceServer) BatchDecode(context.Context, *BatchDecodeRequest) (*BatchDecodeResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method BatchDecode not implemented")
+}
+func (UnimplementedWhitespaceServiceServer) DecodeStream(*BatchDecodeRequest, grpc.ServerStreamingServer[BatchDecodeResult]) error {
+	return status.Errorf(codes.Unimplemented, "method DecodeStream not implemented")
+}
+func (UnimplementedWhitespaceServiceServer) mustEmbedUnimplementedWhitespaceServiceServer() {}
+func (UnimplementedWhitespaceServiceServer) testEmbeddedByValue()                           {}
+
+// UnsafeWhitespaceServiceServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to WhitespaceServiceServer will
+// result in compilation errors.
+type UnsafeWhitespaceServiceServer interface {
+	mustEmbedUnimplementedWhitespaceServiceServer()
+}
+
+func RegisterWhitespaceServiceServer(s grpc.ServiceRegistrar, srv WhitespaceServiceServer) {
+	// If the following call pancis, it indicates UnimplementedWhitespaceServiceServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&WhitespaceService_ServiceDesc, srv)
+}
+
+func _WhitespaceService_Decode_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DecodeRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(WhitespaceServiceServer).Decode(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: WhitespaceService_Decode_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(WhitespaceServiceServer).Decode(ctx, req.(*DecodeRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _WhitespaceService_BatchDecode_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(BatchDecodeRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(WhitespaceServiceServer).BatchDecode(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: WhitespaceService_BatchDecode_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(WhitespaceServiceServer).BatchDecode(ctx, req.(*BatchDecodeRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _WhitespaceService_DecodeStream_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(BatchDecodeRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(WhitespaceServiceServer).DecodeStream(m, &grpc.GenericServerStream[BatchDecodeRequest, BatchDecodeResult]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type WhitespaceService_DecodeStreamServer = grpc.ServerStreamingServer[BatchDecodeResult]
+
+// WhitespaceService_ServiceDesc is the grpc.ServiceDesc for WhitespaceService service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var WhitespaceService_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "wsdecode.v1.WhitespaceService",
+	HandlerType: (*WhitespaceServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "Decode",
+			Handler:    _WhitespaceService_Decode_Handler,
+		},
+		{
+			MethodName: "BatchDecode",
+			Handler:    _WhitespaceService_BatchDecode_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "DecodeStream",
+			Handler:       _WhitespaceService_DecodeStream_Handler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "wsdecode/v1/wsdecode.proto",
+}
@@ -0,0 +1,37 @@
+// Package rating は対局の結果からプレイヤーのレーティング（Elo）を更新する。
+//
+// 対局前の 2 人のレーティングから期待勝率を求め、実際の結果との差に K を掛けた分だけ動かす。
+// 2 人の変化量は符号が逆で大きさが等しいため、レーティングの合計は対局の前後で変わらない。
+//
+// DynamoDB などの外部依存を持たない純粋な関数として実装し、game_handler（Lambda）から使う。
+package rating
+
+import "math"
+
+// Initial はまだ対局していないプレイヤーのレーティング。
+const Initial = 1500
+
+// K は 1 局で動くレーティングの最大幅。
+const K = 32
+
+// Outcome はプレイヤー A から見た対局の結果（勝ち 1、引き分け 0.5、負け 0）。
+type Outcome float64
+
+// プレイヤー A から見た対局の結果。
+const (
+	Loss Outcome = 0
+	Draw Outcome = 0.5
+	Win  Outcome = 1
+)
+
+// Expected はレーティング a のプレイヤーがレーティング b のプレイヤーに勝つ期待値（0〜1）を返す。
+func Expected(a, b float64) float64 {
+	return 1 / (1 + math.Pow(10, (b-a)/400))
+}
+
+// Update は対局前のレーティング a・b と A から見た結果から、対局後の 2 人のレーティングを返す。
+// 結果は小数点以下を四捨五入する。
+func Update(a, b float64, result Outcome) (newA, newB float64) {
+	delta := math.Round(K * (float64(result) - Expected(a, b)))
+	return a + delta, b - delta
+}
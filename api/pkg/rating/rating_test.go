@@ -0,0 +1,43 @@
+package rating
+
+import "testing"
+
+func TestExpected(t *testing.T) {
+	if got := Expected(1500, 1500); got != 0.5 {
+		t.Fatalf("Expected(1500, 1500) = %v, want 0.5", got)
+	}
+	if got := Expected(1900, 1500); got < 0.909 || got > 0.910 {
+		t.Fatalf("Expected(1900, 1500) = %v, want about 0.909", got)
+	}
+	if sum := Expected(1620, 1480) + Expected(1480, 1620); sum < 0.999999 || sum > 1.000001 {
+		t.Fatalf("expected scores sum to %v, want 1", sum)
+	}
+}
+
+func TestUpdate(t *testing.T) {
+	cases := []struct {
+		name   string
+		a, b   float64
+		result Outcome
+		wantA  float64
+		wantB  float64
+	}{
+		{"equal win", 1500, 1500, Win, 1516, 1484},
+		{"equal loss", 1500, 1500, Loss, 1484, 1516},
+		{"equal draw", 1500, 1500, Draw, 1500, 1500},
+		{"favourite wins", 1900, 1500, Win, 1903, 1497},
+		{"underdog wins", 1500, 1900, Win, 1529, 1871},
+		{"underdog draws", 1500, 1900, Draw, 1513, 1887},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			gotA, gotB := Update(tc.a, tc.b, tc.result)
+			if gotA != tc.wantA || gotB != tc.wantB {
+				t.Fatalf("Update(%v, %v, %v) = (%v, %v), want (%v, %v)", tc.a, tc.b, tc.result, gotA, gotB, tc.wantA, tc.wantB)
+			}
+			if gotA+gotB != tc.a+tc.b {
+				t.Fatalf("total rating changed: %v -> %v", tc.a+tc.b, gotA+gotB)
+			}
+		})
+	}
+}
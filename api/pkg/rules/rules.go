@@ -0,0 +1,333 @@
+// Package rules は対局の盤面とルール（着手の合法性、挟んだコマの色の変化、勝敗）を提供する。
+//
+// 8x8 の盤面に 0〜255 の色を持つコマを置いていく。置いたマスから 8 方向それぞれについて、
+// 連続するコマの列が 2 つ以上あれば、最奥のコマ（端のコマ）を除いた列が挟まれたことになる。
+// 挟まれたコマの色は、置いたコマの色 a1・挟まれたうち最も奥のコマの色 a2・元の色 b1 から (a1 + a2 + b1) / 3 に変わる。
+// 1 方向も挟めないマスには置けない。
+//
+// 外部依存を持たないため、ブラウザのクライアント（WASM）・対局再生プレーヤー・サーバ（Lambda）が
+// 同じ手順から同じ盤面を計算できる。
+package rules
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Size は盤面の一辺のマス数。
+const Size = 8
+
+// 勝敗を分ける色の平均値。これ未満なら黒、以上なら白の勝ち。
+const whiteThreshold = 127.5
+
+var (
+	// ErrOutOfBounds は盤面の外に置こうとした場合に返される。
+	ErrOutOfBounds = errors.New("rules: position out of bounds")
+
+	// ErrOccupied は既にコマがあるマスに置こうとした場合に返される。
+	ErrOccupied = errors.New("rules: square is occupied")
+
+	// ErrNoFlank は 1 方向もコマを挟めないマスに置こうとした場合に返される。
+	ErrNoFlank = errors.New("rules: move does not flank any pieces")
+)
+
+// Square は盤面の 1 マス。Occupied が false の場合 Color は意味を持たない。
+type Square struct {
+	Occupied bool
+	Color    uint8
+}
+
+// Board は盤面。Board[row][col] でマスを参照する。値として扱い、コピーしても元の盤面に影響しない。
+type Board [Size][Size]Square
+
+// EmptyCell は Cells の表現で空きマスを表す値。
+const EmptyCell = -1
+
+// Cells は盤面を JSON などで送受信するための表現（Size x Size の 2 次元配列）に変換する。
+// 空きマスは EmptyCell、コマのあるマスはその色（0〜255）になる。
+func (b Board) Cells() [][]int {
+	cells := make([][]int, Size)
+	for row := range Size {
+		cells[row] = make([]int, Size)
+		for col := range Size {
+			cells[row][col] = EmptyCell
+			if b[row][col].Occupied {
+				cells[row][col] = int(b[row][col].Color)
+			}
+		}
+	}
+	return cells
+}
+
+// FromCells は Cells の表現から盤面を復元する。大きさや値が不正な場合はエラーを返す。
+func FromCells(cells [][]int) (Board, error) {
+	var b Board
+	if len(cells) != Size {
+		return b, fmt.Errorf("rules: board must have %d rows, got %d", Size, len(cells))
+	}
+	for row := range Size {
+		if len(cells[row]) != Size {
+			return b, fmt.Errorf("rules: row %d must have %d cells, got %d", row, Size, len(cells[row]))
+		}
+		for col, v := range cells[row] {
+			switch {
+			case v == EmptyCell:
+			case v >= 0 && v <= 255:
+				b[row][col] = Square{Occupied: true, Color: uint8(v)}
+			default:
+				return b, fmt.Errorf("rules: cell (%d, %d) has invalid value %d", row, col, v)
+			}
+		}
+	}
+	return b, nil
+}
+
+// Position は盤面上の位置。
+type Position struct {
+	Row, Col int
+}
+
+// Move は 1 手。Color は置くコマの色。
+type Move struct {
+	Row   int
+	Col   int
+	Color uint8
+}
+
+// directions は挟みを調べる 8 方向。
+var directions = [...]Position{
+	{-1, -1}, {-1, 0}, {-1, 1},
+	{0, -1}, {0, 1},
+	{1, -1}, {1, 0}, {1, 1},
+}
+
+// NewBoard は初期配置の盤面を返す。中央の 4 マスに黒（0）と白（255）を 2 つずつ置く。
+func NewBoard() Board {
+	var b Board
+	b[3][3] = Square{Occupied: true, Color: 0}
+	b[4][4] = Square{Occupied: true, Color: 0}
+	b[3][4] = Square{Occupied: true, Color: 255}
+	b[4][3] = Square{Occupied: true, Color: 255}
+	return b
+}
+
+// InBounds は (row, col) が盤面の内側かを返す。
+func InBounds(row, col int) bool {
+	return row >= 0 && row < Size && col >= 0 && col < Size
+}
+
+// Full は全てのマスが埋まっているかを返す。
+func (b Board) Full() bool {
+	for row := range Size {
+		for col := range Size {
+			if !b[row][col].Occupied {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// EmptySquares は空いているマスを行優先の順に返す。
+func (b Board) EmptySquares() []Position {
+	var empty []Position
+	for row := range Size {
+		for col := range Size {
+			if !b[row][col].Occupied {
+				empty = append(empty, Position{Row: row, Col: col})
+			}
+		}
+	}
+	return empty
+}
+
+// Flanks は (row, col) に置いた場合に挟まれるコマの列を方向ごとに返す。
+// 各列は置いたマスに近い順で、端のコマ（挟んでいる側）は含まない。
+// 盤面の外や埋まっているマスでは nil を返す。
+func Flanks(b Board, row, col int) [][]Position {
+	lines := runs(b, row, col)
+	for i, line := range lines {
+		lines[i] = line[:len(line)-1]
+	}
+	return lines
+}
+
+// runs は (row, col) から各方向に連続するコマの列のうち、挟みが成立するもの（2 つ以上）を返す。
+// 各列の最後の要素が端のコマになる。
+func runs(b Board, row, col int) [][]Position {
+	if !InBounds(row, col) || b[row][col].Occupied {
+		return nil
+	}
+
+	var lines [][]Position
+	for _, dir := range directions {
+		var line []Position
+		r, c := row+dir.Row, col+dir.Col
+		for InBounds(r, c) && b[r][c].Occupied {
+			line = append(line, Position{Row: r, Col: c})
+			r, c = r+dir.Row, c+dir.Col
+		}
+
+		// 少なくとも 1 つを挟み、もう 1 つで端とする
+		if len(line) >= 2 {
+			lines = append(lines, line)
+		}
+	}
+	return lines
+}
+
+// Legal は (row, col) に置けるかを返す。
+func Legal(b Board, row, col int) bool {
+	return len(runs(b, row, col)) > 0
+}
+
+// LegalMoves は color のコマを置ける手を行優先の順に返す。
+func LegalMoves(b Board, color uint8) []Move {
+	var moves []Move
+	for _, pos := range b.EmptySquares() {
+		if Legal(b, pos.Row, pos.Col) {
+			moves = append(moves, Move{Row: pos.Row, Col: pos.Col, Color: color})
+		}
+	}
+	return moves
+}
+
+// Apply は b に m を適用した盤面を返す。b 自体は変更しない。
+// 置けない手の場合は ErrOutOfBounds・ErrOccupied・ErrNoFlank のいずれかを返す。
+func Apply(b Board, m Move) (Board, error) {
+	switch {
+	case !InBounds(m.Row, m.Col):
+		return b, fmt.Errorf("%w: (%d, %d)", ErrOutOfBounds, m.Row, m.Col)
+	case b[m.Row][m.Col].Occupied:
+		return b, fmt.Errorf("%w: (%d, %d)", ErrOccupied, m.Row, m.Col)
+	}
+
+	lines := runs(b, m.Row, m.Col)
+	if len(lines) == 0 {
+		return b, fmt.Errorf("%w: (%d, %d)", ErrNoFlank, m.Row, m.Col)
+	}
+
+	// 色は置く前の盤面の値から計算する。端のコマは色を変えず、
+	// a2 には挟まれたコマのうち最も奥のもの（端の 1 つ手前）の色を用いる。
+	next := b
+	for _, line := range lines {
+		flanked := line[:len(line)-1]
+		last := flanked[len(flanked)-1]
+		a1 := uint16(m.Color)
+		a2 := uint16(b[last.Row][last.Col].Color)
+		for _, pos := range flanked {
+			b1 := uint16(b[pos.Row][pos.Col].Color)
+			next[pos.Row][pos.Col].Color = uint8((a1 + a2 + b1) / 3)
+		}
+	}
+	next[m.Row][m.Col] = Square{Occupied: true, Color: m.Color}
+	return next, nil
+}
+
+// Replay は初期配置から moves を順に適用した盤面を返す。
+// 置けない手があれば、その手の番号（0 始まり）を含むエラーを返す。
+func Replay(moves []Move) (Board, error) {
+	b := NewBoard()
+	for i, m := range moves {
+		var err error
+		if b, err = Apply(b, m); err != nil {
+			return b, fmt.Errorf("move %d: %w", i, err)
+		}
+	}
+	return b, nil
+}
+
+// Turn は着手後に手番をどう進めるかを表す。
+type Turn int
+
+// 着手後の手番の進め方。
+const (
+	// TurnNext は次のプレイヤーに手番を渡す。
+	TurnNext Turn = iota
+	// TurnPass は次のプレイヤーが置けないためパスとし、直前に置いたプレイヤーがもう一度置く。
+	TurnPass
+	// TurnGameOver はどちらも置けないため対局を終える。
+	TurnGameOver
+)
+
+// String は手番の進め方を名前で返す。
+func (t Turn) String() string {
+	switch t {
+	case TurnNext:
+		return "next"
+	case TurnPass:
+		return "pass"
+	default:
+		return "gameOver"
+	}
+}
+
+// NextTurn は着手後の盤面 b で、次のプレイヤー（色 nextColor）と直前に置いたプレイヤー（色 moverColor）が
+// 置けるかどうかから手番の進め方を決める。
+// 現在の規則では置けるかどうかはコマの色に依存しないため、盤面が埋まる前に TurnPass にはならないが、
+// 呼び出し側はこの関数の結果に従えば規則が変わっても対局が止まらない。
+func NextTurn(b Board, nextColor, moverColor uint8) Turn {
+	switch {
+	case len(LegalMoves(b, nextColor)) > 0:
+		return TurnNext
+	case len(LegalMoves(b, moverColor)) > 0:
+		return TurnPass
+	default:
+		return TurnGameOver
+	}
+}
+
+// Side は勝敗の結果。
+type Side int
+
+// 勝敗の結果。
+const (
+	Draw Side = iota
+	Black
+	White
+)
+
+// String は結果を画面表示・記録に使う名前（"黒" / "白" / "引き分け"）で返す。
+func (s Side) String() string {
+	switch s {
+	case Black:
+		return "黒"
+	case White:
+		return "白"
+	default:
+		return "引き分け"
+	}
+}
+
+// Result は盤面の集計結果。
+type Result struct {
+	Pieces   int     // 盤面上のコマの数
+	ColorSum int     // コマの色の合計
+	Average  float64 // コマの色の平均（コマが無い場合は 0）
+	Winner   Side
+}
+
+// Score は盤面上の全てのコマの色の平均から勝敗を決める。
+// 平均が 127.5 未満なら黒、以上なら白の勝ちとし、コマが 1 つも無ければ引き分けとする。
+func Score(b Board) Result {
+	var r Result
+	for row := range Size {
+		for col := range Size {
+			if b[row][col].Occupied {
+				r.Pieces++
+				r.ColorSum += int(b[row][col].Color)
+			}
+		}
+	}
+	if r.Pieces == 0 {
+		return r
+	}
+
+	r.Average = float64(r.ColorSum) / float64(r.Pieces)
+	if r.Average < whiteThreshold {
+		r.Winner = Black
+	} else {
+		r.Winner = White
+	}
+	return r
+}
@@ -0,0 +1,185 @@
+package rules
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestNewBoard(t *testing.T) {
+	b := NewBoard()
+
+	want := map[Position]uint8{{3, 3}: 0, {4, 4}: 0, {3, 4}: 255, {4, 3}: 255}
+	for row := range Size {
+		for col := range Size {
+			color, ok := want[Position{row, col}]
+			if got := b[row][col]; got.Occupied != ok || got.Color != color {
+				t.Fatalf("b[%d][%d] = %+v, want occupied=%v color=%d", row, col, got, ok, color)
+			}
+		}
+	}
+}
+
+func TestApplyMixesFlankedColors(t *testing.T) {
+	b := NewBoard()
+
+	// (2, 3) からは下方向に (3, 3) を挟み、(4, 3) が端になる。
+	// a2 は挟まれたコマのうち最も奥の (3, 3) の色を用いる
+	next, err := Apply(b, Move{Row: 2, Col: 3, Color: 90})
+	if err != nil {
+		t.Fatalf("Apply returned error: %v", err)
+	}
+
+	if got, want := next[3][3].Color, uint8((90+0+0)/3); got != want {
+		t.Fatalf("flanked color = %d, want %d", got, want)
+	}
+	if got := next[4][3]; got.Color != 255 {
+		t.Fatalf("end piece changed: %+v", got)
+	}
+	if got := next[2][3]; !got.Occupied || got.Color != 90 {
+		t.Fatalf("placed square = %+v", got)
+	}
+	if b[2][3].Occupied || b[3][3].Color != 0 {
+		t.Fatal("Apply modified the original board")
+	}
+}
+
+func TestApplyRejectsIllegalMoves(t *testing.T) {
+	b := NewBoard()
+
+	cases := map[string]struct {
+		move Move
+		want error
+	}{
+		"out of bounds": {move: Move{Row: 8, Col: 0}, want: ErrOutOfBounds},
+		"occupied":      {move: Move{Row: 3, Col: 3}, want: ErrOccupied},
+		"no flank":      {move: Move{Row: 0, Col: 0}, want: ErrNoFlank},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			got, err := Apply(b, tc.move)
+			if !errors.Is(err, tc.want) {
+				t.Fatalf("Apply error = %v, want %v", err, tc.want)
+			}
+			if got != b {
+				t.Fatal("Apply changed the board for an illegal move")
+			}
+		})
+	}
+}
+
+func TestLegalMovesOnInitialBoard(t *testing.T) {
+	moves := LegalMoves(NewBoard(), 42)
+
+	// 中央の 2x2 を縦・横・斜めに 2 つ貫けるマスだけが置ける
+	want := []Move{
+		{2, 3, 42}, {2, 4, 42},
+		{3, 2, 42}, {3, 5, 42},
+		{4, 2, 42}, {4, 5, 42},
+		{5, 3, 42}, {5, 4, 42},
+		{2, 2, 42}, {2, 5, 42}, {5, 2, 42}, {5, 5, 42},
+	}
+	if len(moves) != len(want) {
+		t.Fatalf("LegalMoves = %v, want %d moves", moves, len(want))
+	}
+	for _, m := range want {
+		if !Legal(NewBoard(), m.Row, m.Col) {
+			t.Fatalf("Legal(%d, %d) = false, want true", m.Row, m.Col)
+		}
+	}
+}
+
+func TestReplay(t *testing.T) {
+	moves := []Move{{2, 3, 90}, {5, 4, 200}, {0, 0, 10}}
+
+	b, err := Replay(moves[:2])
+	if err != nil {
+		t.Fatalf("Replay returned error: %v", err)
+	}
+	step, _ := Apply(NewBoard(), moves[0])
+	step, _ = Apply(step, moves[1])
+	if b != step {
+		t.Fatal("Replay differs from applying moves one by one")
+	}
+
+	if _, err := Replay(moves); !errors.Is(err, ErrNoFlank) {
+		t.Fatalf("Replay error = %v, want ErrNoFlank", err)
+	}
+}
+
+func TestScore(t *testing.T) {
+	if got := Score(Board{}); got.Winner != Draw || got.Pieces != 0 {
+		t.Fatalf("Score(empty) = %+v", got)
+	}
+
+	// 初期配置は平均 127.5 なので白
+	got := Score(NewBoard())
+	if got.Pieces != 4 || got.ColorSum != 510 || got.Average != 127.5 || got.Winner != White {
+		t.Fatalf("Score(NewBoard()) = %+v", got)
+	}
+
+	b := NewBoard()
+	b[3][4].Color = 254
+	if got := Score(b); got.Winner != Black {
+		t.Fatalf("Winner = %v, want %v", got.Winner, Black)
+	}
+	if Black.String() != "黒" || White.String() != "白" || Draw.String() != "引き分け" {
+		t.Fatal("unexpected Side names")
+	}
+}
+
+func TestCellsRoundTrip(t *testing.T) {
+	b, err := Replay([]Move{{2, 3, 0}, {5, 4, 200}})
+	if err != nil {
+		t.Fatalf("Replay returned error: %v", err)
+	}
+
+	cells := b.Cells()
+	if cells[0][0] != EmptyCell || cells[2][3] != 0 || cells[5][4] != 200 {
+		t.Fatalf("unexpected cells: %v", cells)
+	}
+
+	got, err := FromCells(cells)
+	if err != nil {
+		t.Fatalf("FromCells returned error: %v", err)
+	}
+	if got != b {
+		t.Fatal("FromCells(b.Cells()) differs from b")
+	}
+
+	cells[1][1] = 256
+	if _, err := FromCells(cells); err == nil {
+		t.Fatal("expected error for out-of-range color")
+	}
+	if _, err := FromCells(cells[:7]); err == nil {
+		t.Fatal("expected error for missing row")
+	}
+}
+
+func TestNextTurn(t *testing.T) {
+	if got := NextTurn(NewBoard(), 200, 10); got != TurnNext {
+		t.Fatalf("NextTurn(initial) = %v, want %v", got, TurnNext)
+	}
+
+	// 盤面が埋まっていなくても、どこにも挟める列が無ければ対局は終わる
+	var b Board
+	b[0][0] = Square{Occupied: true, Color: 10}
+	if got := NextTurn(b, 200, 10); got != TurnGameOver {
+		t.Fatalf("NextTurn(isolated piece) = %v, want %v", got, TurnGameOver)
+	}
+
+	full, err := Replay(nil)
+	if err != nil {
+		t.Fatalf("Replay returned error: %v", err)
+	}
+	for full.EmptySquares() != nil {
+		moves := LegalMoves(full, 1)
+		if len(moves) == 0 {
+			break
+		}
+		full, _ = Apply(full, moves[0])
+	}
+	if got := NextTurn(full, 200, 10); got != TurnGameOver {
+		t.Fatalf("NextTurn(final) = %v, want %v", got, TurnGameOver)
+	}
+}
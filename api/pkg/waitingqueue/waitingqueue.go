@@ -0,0 +1,106 @@
+// Package waitingqueue は DynamoDB 上の対戦待ちの列と、プレイヤーが待機中であることを示すマーカーを扱う。
+//
+// 待機列の項目は PK = WAITING_QUEUE、SK = timestamp#userId に、マーカーは PK = WAITING#userId、SK = QUEUE に置く。
+// マーカーは対応する項目の SK を queueKey に持ち、項目と同じトランザクションで書き込み・削除するため、
+// マーカーが存在する間だけプレイヤーは待機列にちょうど 1 件の項目を持つ。
+//
+// 待機列から取り除く処理を matchmaking_handler・disconnect_handler・cleanup_handler（Lambda）で共通にする。
+package waitingqueue
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbiface"
+)
+
+// PartitionKey は待機列の項目の PK。
+const PartitionKey = "WAITING_QUEUE"
+
+// Entry は待機列の 1 項目のうち、取り除くのに必要な値を表す。QueueKey は項目の SK。
+type Entry struct {
+	UserID   string
+	QueueKey string
+}
+
+// ParseEntry は待機列の項目から Entry を読み取る。
+// userId か SK が無い項目はマーカーを特定できないため ok = false を返す。
+func ParseEntry(item map[string]*dynamodb.AttributeValue) (entry Entry, ok bool) {
+	if v, found := item["userId"]; found && v.S != nil {
+		entry.UserID = *v.S
+	}
+	if v, found := item["SK"]; found && v.S != nil {
+		entry.QueueKey = *v.S
+	}
+	return entry, entry.UserID != "" && entry.QueueKey != ""
+}
+
+// EntryKey は SK が queueKey の待機列の項目のキーを返す。
+func EntryKey(queueKey string) map[string]*dynamodb.AttributeValue {
+	return map[string]*dynamodb.AttributeValue{
+		"PK": {S: aws.String(PartitionKey)},
+		"SK": {S: aws.String(queueKey)},
+	}
+}
+
+// MarkerKey は userID が待機中であることを示すマーカーのキーを返す。
+func MarkerKey(userID string) map[string]*dynamodb.AttributeValue {
+	return map[string]*dynamodb.AttributeValue{
+		"PK": {S: aws.String(fmt.Sprintf("WAITING#%s", userID))},
+		"SK": {S: aws.String("QUEUE")},
+	}
+}
+
+// DequeueItems は entry とそのマーカーを削除するトランザクションの要素を返す。
+// 項目がまだ存在することを条件とするため、他のリクエストが先に取り除いていれば要素 0 の条件で失敗する。
+// マーカーは entry を指している場合（または既に無い場合）だけ削除し、後から並び直した新しい項目のマーカーは残す。
+func DequeueItems(tableName string, entry Entry) []*dynamodb.TransactWriteItem {
+	return []*dynamodb.TransactWriteItem{
+		{Delete: &dynamodb.Delete{
+			TableName:           aws.String(tableName),
+			Key:                 EntryKey(entry.QueueKey),
+			ConditionExpression: aws.String("attribute_exists(PK)"),
+		}},
+		{Delete: &dynamodb.Delete{
+			TableName:           aws.String(tableName),
+			Key:                 MarkerKey(entry.UserID),
+			ConditionExpression: aws.String("attribute_not_exists(PK) OR queueKey = :queueKey"),
+			ExpressionAttributeValues: map[string]*dynamodb.AttributeValue{
+				":queueKey": {S: aws.String(entry.QueueKey)},
+			},
+		}},
+	}
+}
+
+// Remove は entries をマーカーとともに 1 件ずつ削除し、削除した件数を返す。
+// 他のリクエストが先に取り除いた項目は数えずに読み飛ばす。
+// 削除に失敗した項目があっても残りの削除を続け、失敗をまとめたエラーを返す。
+func Remove(dynamo dynamodbiface.DynamoDBAPI, tableName string, entries []Entry) (int, error) {
+	removed := 0
+	var errs []error
+	for _, entry := range entries {
+		_, err := dynamo.TransactWriteItems(&dynamodb.TransactWriteItemsInput{
+			TransactItems: DequeueItems(tableName, entry),
+		})
+		switch {
+		case err == nil:
+			removed++
+		case ConditionFailedAt(err, 0):
+			// 待機列を読んでから、対戦の成立や別の削除で既に取り除かれている
+		default:
+			errs = append(errs, fmt.Errorf("remove %s: %w", entry.QueueKey, err))
+		}
+	}
+	return removed, errors.Join(errs...)
+}
+
+// ConditionFailedAt は err が取り消されたトランザクションで、index 番目の要素の条件が満たされなかったことを示すかを返す。
+func ConditionFailedAt(err error, index int) bool {
+	var canceled *dynamodb.TransactionCanceledException
+	if !errors.As(err, &canceled) || index >= len(canceled.CancellationReasons) {
+		return false
+	}
+	return aws.StringValue(canceled.CancellationReasons[index].Code) == "ConditionalCheckFailed"
+}
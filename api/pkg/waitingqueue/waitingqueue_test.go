@@ -0,0 +1,89 @@
+package waitingqueue
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbiface"
+)
+
+// fakeDynamo は TransactWriteItems の呼び出しを記録し、errs の先頭から順にエラーを返す。
+type fakeDynamo struct {
+	dynamodbiface.DynamoDBAPI
+
+	errs         []error
+	transactions []*dynamodb.TransactWriteItemsInput
+}
+
+func (f *fakeDynamo) TransactWriteItems(input *dynamodb.TransactWriteItemsInput) (*dynamodb.TransactWriteItemsOutput, error) {
+	f.transactions = append(f.transactions, input)
+	var err error
+	if len(f.errs) > 0 {
+		err, f.errs = f.errs[0], f.errs[1:]
+	}
+	return &dynamodb.TransactWriteItemsOutput{}, err
+}
+
+func canceledAt(code string) error {
+	return &dynamodb.TransactionCanceledException{CancellationReasons: []*dynamodb.CancellationReason{
+		{Code: aws.String(code)}, {Code: aws.String("None")},
+	}}
+}
+
+func TestParseEntry(t *testing.T) {
+	entry, ok := ParseEntry(map[string]*dynamodb.AttributeValue{
+		"PK":     {S: aws.String(PartitionKey)},
+		"SK":     {S: aws.String("100#alice")},
+		"userId": {S: aws.String("alice")},
+	})
+	if !ok || entry != (Entry{UserID: "alice", QueueKey: "100#alice"}) {
+		t.Fatalf("ParseEntry = (%+v, %v)", entry, ok)
+	}
+
+	// userId の無い項目はマーカーを特定できない
+	if _, ok := ParseEntry(map[string]*dynamodb.AttributeValue{"SK": {S: aws.String("100#")}}); ok {
+		t.Fatal("ParseEntry accepted an item without userId")
+	}
+}
+
+func TestDequeueItems(t *testing.T) {
+	items := DequeueItems("game-service", Entry{UserID: "alice", QueueKey: "100#alice"})
+	if len(items) != 2 || items[0].Delete == nil || items[1].Delete == nil {
+		t.Fatalf("DequeueItems = %v, want the entry and marker deletes", items)
+	}
+	if got := aws.StringValue(items[0].Delete.Key["SK"].S); got != "100#alice" {
+		t.Fatalf("entry SK = %q", got)
+	}
+	if got := aws.StringValue(items[1].Delete.Key["PK"].S); got != "WAITING#alice" {
+		t.Fatalf("marker PK = %q", got)
+	}
+	if got := aws.StringValue(items[1].Delete.ExpressionAttributeValues[":queueKey"].S); got != "100#alice" {
+		t.Fatalf("marker condition queueKey = %q", got)
+	}
+}
+
+func TestRemove(t *testing.T) {
+	boom := errors.New("boom")
+	f := &fakeDynamo{errs: []error{nil, canceledAt("ConditionalCheckFailed"), boom, nil}}
+	entries := []Entry{
+		{UserID: "alice", QueueKey: "1#alice"},
+		{UserID: "bob", QueueKey: "2#bob"},
+		{UserID: "carol", QueueKey: "3#carol"},
+		{UserID: "dave", QueueKey: "4#dave"},
+	}
+
+	removed, err := Remove(f, "game-service", entries)
+
+	// 既に取り除かれていた bob は数えず、carol の失敗の後も dave の削除を続ける
+	if removed != 2 {
+		t.Fatalf("removed = %d, want 2", removed)
+	}
+	if !errors.Is(err, boom) {
+		t.Fatalf("err = %v, want it to wrap the failure", err)
+	}
+	if len(f.transactions) != len(entries) {
+		t.Fatalf("ran %d transactions, want %d", len(f.transactions), len(entries))
+	}
+}
@@ -0,0 +1,269 @@
+// Package wscodec は Whitespace の文と、2 進数・10 進数表現との相互変換を提供する。
+//
+// 1 文は "SSS{4bit}L SSS{4bit}L SSS{8bit}L" の 3 行で、各行は半角スペース 3 つの後に
+// ビット列（スペース = 0、タブ = 1）が続き、改行で終わる。16bit の値を 4/4/8bit に区切って表す。
+//
+// Gin などのサーバ依存を持たないため、API サーバだけでなく Lambda や WASM からも同じ規則で変換できる。
+package wscodec
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// ErrInvalidPayload は文の構文または値が不正な場合に返される。
+var ErrInvalidPayload = errors.New("wscodec: invalid payload")
+
+// LinesPerSentence は Whitespace の 1 文を構成する行数。
+const LinesPerSentence = 3
+
+// linePrefix は各行の先頭に置く半角スペース。
+const linePrefix = "   "
+
+// segmentBits は各セグメントのビット数。
+var segmentBits = [LinesPerSentence]int{4, 4, 8}
+
+// Sentence は 1 文分の 3 つの値を表す。先頭 2 つは 0〜15、最後の 1 つは 0〜255。
+type Sentence [LinesPerSentence]uint8
+
+// NewSentence は値の範囲を検証して Sentence を生成する。
+func NewSentence(a, b, c int) (Sentence, error) {
+	var s Sentence
+	for i, value := range [LinesPerSentence]int{a, b, c} {
+		if value < 0 || value >= 1<<segmentBits[i] {
+			return Sentence{}, fmt.Errorf("%w: value %d out of range for %d-bit segment", ErrInvalidPayload, value, segmentBits[i])
+		}
+		s[i] = uint8(value)
+	}
+	return s, nil
+}
+
+// SentenceFromBytes は 16bit の値（上位バイト hi、下位バイト lo）から Sentence を生成する。
+func SentenceFromBytes(hi, lo byte) Sentence {
+	return Sentence{hi >> 4, hi & 0x0f, lo}
+}
+
+// Bytes は Sentence を 16bit の値として上位・下位バイトの順に返す。
+func (s Sentence) Bytes() [2]byte {
+	return [2]byte{s[0]<<4 | s[1]&0x0f, s[2]}
+}
+
+// Whitespace は Sentence を Whitespace の 1 文（末尾の改行を含む 3 行）に変換する。
+func (s Sentence) Whitespace() string {
+	var builder strings.Builder
+	for _, segment := range s.segments() {
+		builder.WriteString(linePrefix)
+		for _, bit := range segment {
+			if bit == '1' {
+				builder.WriteByte('\t')
+			} else {
+				builder.WriteByte(' ')
+			}
+		}
+		builder.WriteByte('\n')
+	}
+	return builder.String()
+}
+
+// Decimal は Sentence を "1 2 3" 形式の 10 進数表現に変換する。
+func (s Sentence) Decimal() string {
+	return fmt.Sprintf("%d %d %d", s[0], s[1], s[2])
+}
+
+// Binary は Sentence を "0001 0010 00000011" 形式の 2 進数表現に変換する。
+func (s Sentence) Binary() string {
+	segments := s.segments()
+	return strings.Join(segments[:], " ")
+}
+
+// Bits は Sentence を区切り無しの 16bit の 2 進数文字列に変換する。
+func (s Sentence) Bits() string {
+	segments := s.segments()
+	return strings.Join(segments[:], "")
+}
+
+func (s Sentence) segments() [LinesPerSentence]string {
+	var segments [LinesPerSentence]string
+	for i, value := range s {
+		segments[i] = fmt.Sprintf("%0*b", segmentBits[i], value)
+	}
+	return segments
+}
+
+// ParseWhitespace は Whitespace の 1 文を解釈する。
+// 改行は LF / CRLF / CR のいずれも受け付け、空行は無視する。
+func ParseWhitespace(sentence string) (Sentence, error) {
+	if sentence == "" {
+		return Sentence{}, fmt.Errorf("%w: sentence must not be blank", ErrInvalidPayload)
+	}
+
+	normalized := newlineReplacer.Replace(sentence)
+
+	segments := make([]string, 0, LinesPerSentence)
+	for _, line := range strings.Split(normalized, "\n") {
+		if line == "" {
+			continue
+		}
+		if !strings.HasPrefix(line, linePrefix) {
+			return Sentence{}, fmt.Errorf("%w: line must start with three spaces", ErrInvalidPayload)
+		}
+		segments = append(segments, line[len(linePrefix):])
+	}
+
+	if len(segments) != LinesPerSentence {
+		return Sentence{}, fmt.Errorf("%w: sentence must contain three lines", ErrInvalidPayload)
+	}
+
+	var s Sentence
+	for i, segment := range segments {
+		if len([]rune(segment)) != segmentBits[i] {
+			return Sentence{}, fmt.Errorf("%w: line %d must contain %d characters", ErrInvalidPayload, i+1, segmentBits[i])
+		}
+
+		var value uint8
+		for _, r := range segment {
+			switch r {
+			case ' ':
+				value <<= 1
+			case '\t':
+				value = value<<1 | 1
+			default:
+				return Sentence{}, fmt.Errorf("%w: unsupported rune %#U", ErrInvalidPayload, r)
+			}
+		}
+		s[i] = value
+	}
+	return s, nil
+}
+
+// ParseDecimal は "1 2 3" 形式の 10 進数表現を解釈する。区切りは任意の空白で良い。
+func ParseDecimal(decimal string) (Sentence, error) {
+	tokens := strings.Fields(decimal)
+	if len(tokens) != LinesPerSentence {
+		return Sentence{}, fmt.Errorf("%w: decimal must contain three numbers", ErrInvalidPayload)
+	}
+
+	var s Sentence
+	for i, token := range tokens {
+		value, err := strconv.Atoi(token)
+		if err != nil {
+			return Sentence{}, fmt.Errorf("%w: token %q is not an integer", ErrInvalidPayload, token)
+		}
+		if value < 0 || value >= 1<<segmentBits[i] {
+			return Sentence{}, fmt.Errorf("%w: decimal %q out of range", ErrInvalidPayload, token)
+		}
+		s[i] = uint8(value)
+	}
+	return s, nil
+}
+
+// ParseBinary は 16bit の 2 進数表現を解釈する。"0001 0010 00000011" のようにスペースで区切っても良い。
+func ParseBinary(binary string) (Sentence, error) {
+	trimmed := strings.TrimSpace(binary)
+	if trimmed == "" {
+		return Sentence{}, fmt.Errorf("%w: binary must not be blank", ErrInvalidPayload)
+	}
+
+	clean := strings.ReplaceAll(trimmed, " ", "")
+	if len(clean) != 16 {
+		return Sentence{}, fmt.Errorf("%w: binary must be 16 bits", ErrInvalidPayload)
+	}
+
+	var bits uint16
+	for _, r := range clean {
+		switch r {
+		case '0':
+			bits <<= 1
+		case '1':
+			bits = bits<<1 | 1
+		default:
+			return Sentence{}, fmt.Errorf("%w: binary contains invalid rune %#U", ErrInvalidPayload, r)
+		}
+	}
+	return SentenceFromBytes(byte(bits>>8), byte(bits)), nil
+}
+
+// newlineReplacer は CRLF と CR を LF に揃える。
+var newlineReplacer = strings.NewReplacer("\r\n", "\n", "\r", "\n")
+
+// WhitespacePart は SplitWhitespaceLines が返す 1 文と、その文が始まる行番号（1 始まり）を表す。
+type WhitespacePart struct {
+	Line int
+	Text string
+}
+
+// IncompleteSentenceError は末尾の文の行数が足りないことを表す。Line は余った行の先頭の行番号（1 始まり）。
+type IncompleteSentenceError struct {
+	Line  int
+	Lines int
+}
+
+func (e *IncompleteSentenceError) Error() string {
+	return fmt.Sprintf("incomplete sentence: expected %d lines, got %d", LinesPerSentence, e.Lines)
+}
+
+// SplitWhitespace は複数の文を連結した Whitespace を文ごとに分割する。
+// 空行を除いた 3 行を 1 文とし、各文は改行で終わる形に揃える。行数が 3 の倍数でなければエラーを返す。
+func SplitWhitespace(text string) ([]string, error) {
+	parts, err := SplitWhitespaceLines(text)
+	sentences := make([]string, len(parts))
+	for i, part := range parts {
+		sentences[i] = part.Text
+	}
+	return sentences, err
+}
+
+// SplitWhitespaceLines は SplitWhitespace と同じ規則で分割し、各文が始まる行番号も返す。
+// 改行は ParseWhitespace と同じく LF / CRLF / CR のいずれも受け付け、CRLF は 1 行として数える。
+// 行数が 3 の倍数でなければ、分割できた文とともに ErrInvalidPayload と *IncompleteSentenceError を包んだエラーを返す。
+func SplitWhitespaceLines(text string) ([]WhitespacePart, error) {
+	var (
+		parts     []WhitespacePart
+		pending   []string
+		startLine int
+	)
+	for i, line := range strings.Split(newlineReplacer.Replace(text), "\n") {
+		if line == "" {
+			continue
+		}
+		if len(pending) == 0 {
+			startLine = i + 1
+		}
+		pending = append(pending, line)
+		if len(pending) == LinesPerSentence {
+			parts = append(parts, WhitespacePart{Line: startLine, Text: strings.Join(pending, "\n") + "\n"})
+			pending = pending[:0]
+		}
+	}
+	if len(pending) > 0 {
+		return parts, fmt.Errorf("%w: %w", ErrInvalidPayload, &IncompleteSentenceError{Line: startLine, Lines: len(pending)})
+	}
+	return parts, nil
+}
+
+// DecodeWhitespace は複数の文を連結した Whitespace を解釈する。
+func DecodeWhitespace(text string) ([]Sentence, error) {
+	parts, err := SplitWhitespace(text)
+	if err != nil {
+		return nil, err
+	}
+
+	sentences := make([]Sentence, len(parts))
+	for i, part := range parts {
+		if sentences[i], err = ParseWhitespace(part); err != nil {
+			return nil, fmt.Errorf("sentence %d: %w", i, err)
+		}
+	}
+	return sentences, nil
+}
+
+// EncodeWhitespace は文を順に Whitespace に変換して連結する。
+func EncodeWhitespace(sentences []Sentence) string {
+	var builder strings.Builder
+	for _, s := range sentences {
+		builder.WriteString(s.Whitespace())
+	}
+	return builder.String()
+}
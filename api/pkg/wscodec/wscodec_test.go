@@ -0,0 +1,158 @@
+package wscodec
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+// sentence123 は "1 2 3" を表す Whitespace の文。
+const sentence123 = "      \t\n     \t \n         \t\t\n"
+
+func TestSentenceRoundTrip(t *testing.T) {
+	s, err := ParseDecimal("1 2 3")
+	if err != nil {
+		t.Fatalf("ParseDecimal returned error: %v", err)
+	}
+
+	if got := s.Whitespace(); got != sentence123 {
+		t.Fatalf("Whitespace() = %q, want %q", got, sentence123)
+	}
+	if got := s.Binary(); got != "0001 0010 00000011" {
+		t.Fatalf("Binary() = %q", got)
+	}
+	if got := s.Bits(); got != "0001001000000011" {
+		t.Fatalf("Bits() = %q", got)
+	}
+	if got := s.Bytes(); got != [2]byte{0x12, 0x03} {
+		t.Fatalf("Bytes() = %#v", got)
+	}
+	if got := SentenceFromBytes(0x12, 0x03); got != s {
+		t.Fatalf("SentenceFromBytes = %v, want %v", got, s)
+	}
+
+	parsed, err := ParseWhitespace(sentence123)
+	if err != nil || parsed != s {
+		t.Fatalf("ParseWhitespace = %v, %v; want %v", parsed, err, s)
+	}
+	parsed, err = ParseBinary(" 0001 0010 00000011 ")
+	if err != nil || parsed != s {
+		t.Fatalf("ParseBinary = %v, %v; want %v", parsed, err, s)
+	}
+	if got := parsed.Decimal(); got != "1 2 3" {
+		t.Fatalf("Decimal() = %q", got)
+	}
+}
+
+func TestParseWhitespaceAcceptsCRLF(t *testing.T) {
+	s, err := ParseWhitespace("   \t \t\t\r\n    \t\t \r\n   \t\t \t  \t \r\n")
+	if err != nil {
+		t.Fatalf("ParseWhitespace returned error: %v", err)
+	}
+	if s != (Sentence{11, 6, 210}) {
+		t.Fatalf("ParseWhitespace = %v", s)
+	}
+}
+
+func TestNewSentence(t *testing.T) {
+	if s, err := NewSentence(15, 15, 255); err != nil || s != (Sentence{15, 15, 255}) {
+		t.Fatalf("NewSentence = %v, %v", s, err)
+	}
+	for _, values := range [][3]int{{16, 0, 0}, {0, -1, 0}, {0, 0, 256}} {
+		if _, err := NewSentence(values[0], values[1], values[2]); !errors.Is(err, ErrInvalidPayload) {
+			t.Errorf("NewSentence%v error = %v, want ErrInvalidPayload", values, err)
+		}
+	}
+}
+
+func TestParseDecimalErrors(t *testing.T) {
+	cases := map[string]string{
+		"too few tokens": "1 2",
+		"non integer":    "a b c",
+		"negative":       "-1 0 0",
+		"out of range":   "16 0 0",
+	}
+
+	for name, input := range cases {
+		t.Run(name, func(t *testing.T) {
+			if _, err := ParseDecimal(input); !errors.Is(err, ErrInvalidPayload) {
+				t.Fatalf("expected ErrInvalidPayload, got %v", err)
+			}
+		})
+	}
+}
+
+func TestParseBinaryErrors(t *testing.T) {
+	cases := map[string]string{
+		"blank":          "",
+		"invalid length": "1010",
+		"invalid rune":   "0000000000000002",
+	}
+
+	for name, input := range cases {
+		t.Run(name, func(t *testing.T) {
+			if _, err := ParseBinary(input); !errors.Is(err, ErrInvalidPayload) {
+				t.Fatalf("expected ErrInvalidPayload, got %v", err)
+			}
+		})
+	}
+}
+
+func TestParseWhitespaceErrors(t *testing.T) {
+	cases := map[string]string{
+		"blank":              "",
+		"missing prefix":     "abc",
+		"insufficient lines": "   abcd\n   abcd",
+		"invalid length":     "   abcd\n   abcd\n   abcdefg",
+		"unsupported rune":   "   abcd\n   abcd\n   abcdefgh",
+	}
+
+	for name, sentence := range cases {
+		t.Run(name, func(t *testing.T) {
+			if _, err := ParseWhitespace(sentence); !errors.Is(err, ErrInvalidPayload) {
+				t.Fatalf("expected ErrInvalidPayload, got %v", err)
+			}
+		})
+	}
+}
+
+func TestDecodeAndEncodeWhitespace(t *testing.T) {
+	text := "\n" + sentence123 + "\n" + Sentence{15, 15, 255}.Whitespace()
+
+	sentences, err := DecodeWhitespace(text)
+	if err != nil {
+		t.Fatalf("DecodeWhitespace returned error: %v", err)
+	}
+	want := []Sentence{{1, 2, 3}, {15, 15, 255}}
+	if len(sentences) != len(want) || sentences[0] != want[0] || sentences[1] != want[1] {
+		t.Fatalf("DecodeWhitespace = %v, want %v", sentences, want)
+	}
+
+	if got := EncodeWhitespace(sentences); got != sentence123+want[1].Whitespace() {
+		t.Fatalf("EncodeWhitespace = %q", got)
+	}
+}
+
+func TestSplitWhitespaceIncomplete(t *testing.T) {
+	sentences, err := SplitWhitespace(sentence123 + "   \t\n")
+	if !errors.Is(err, ErrInvalidPayload) {
+		t.Fatalf("expected ErrInvalidPayload, got %v", err)
+	}
+	if len(sentences) != 1 || sentences[0] != sentence123 {
+		t.Fatalf("sentences = %q", sentences)
+	}
+}
+
+func TestSplitWhitespaceLines(t *testing.T) {
+	// CR のみの改行も行の区切りとして扱い、CRLF は 1 行として数える
+	text := "\r\n" + strings.ReplaceAll(sentence123, "\n", "\r") + strings.ReplaceAll(sentence123, "\n", "\r\n") + "   \t\n"
+	parts, err := SplitWhitespaceLines(text)
+
+	var incomplete *IncompleteSentenceError
+	if !errors.Is(err, ErrInvalidPayload) || !errors.As(err, &incomplete) || incomplete.Line != 8 || incomplete.Lines != 1 {
+		t.Fatalf("expected incomplete sentence at line 8, got %v", err)
+	}
+	if len(parts) != 2 || parts[0] != (WhitespacePart{Line: 2, Text: sentence123}) || parts[1] != (WhitespacePart{Line: 5, Text: sentence123}) {
+		t.Fatalf("parts = %q", parts)
+	}
+}
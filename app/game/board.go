@@ -1,81 +1,15 @@
 package main
 
 import (
-	"fmt"
 	"log"
-)
-
-// NewBoard は初期設定済みの新しいボードを作成する
-func NewBoard() *Board {
-	board := &Board{}
-
-	// 中央に開始時のコマを配置
-	board.Squares[3][3].Piece = &Piece{Color: 0}   // 初期コマ（黒色）
-	board.Squares[4][4].Piece = &Piece{Color: 0}   // 初期コマ（黒色）
-	board.Squares[3][4].Piece = &Piece{Color: 255} // 初期コマ（白色）
-	board.Squares[4][3].Piece = &Piece{Color: 255} // 初期コマ（白色）
-
-	return board
-}
-
-// isValidPosition は位置がボードの境界内かをチェックする
-func isValidPosition(x, y int) bool {
-	return x >= 0 && x < 8 && y >= 0 && y < 8
-}
-
-// findFlankingPieces は(x, y)にコマを置いた時に挟まれるコマを見つける
-func (g *Game) findFlankingPieces(x, y int) [][]Position {
-	log.Println(g.GameRecord)
-	if !g.Board.Squares[x][y].IsEmpty() {
-		return nil
-	}
-
-	var flankingLines [][]Position
-
-	for _, dir := range directions {
-		var line []Position
-		nx, ny := x+dir.dx, y+dir.dy
 
-		// この方向にコマがあるかを探す
-		for isValidPosition(nx, ny) && !g.Board.Squares[nx][ny].IsEmpty() {
-			// コマを潜在的な挟みラインに追加
-			line = append(line, Position{nx, ny})
-			nx, ny = nx+dir.dx, ny+dir.dy
-		}
-
-		// ラインに少なくとも2つのコマがある場合、挟みが成立
-		// （最低でも1つのコマを挟み、もう1つで終端とする）
-		if len(line) >= 2 {
-			// 最奥のコマは対象から外す
-			line = line[:len(line)-1]
-			flankingLines = append(flankingLines, line)
-		}
-	}
-
-	fmt.Println("Found flanking lines:", flankingLines)
-	return flankingLines
-}
+	"github.com/2509-hackz-ichthyo/main/api/pkg/rules"
+)
 
 // isValidMove は(x, y)にコマを置くことが有効な手かをチェックする
+// 盤面のルールは対局再生プレーヤー・サーバと共通の rules パッケージで判定する
 func (g *Game) isValidMove(x, y int) bool {
-	if !isValidPosition(x, y) || !g.Board.Squares[x][y].IsEmpty() {
-		return false
-	}
-
-	flankingLines := g.findFlankingPieces(x, y)
-	return len(flankingLines) > 0
-}
-
-// isBoardFull は全てのマスが埋まっているかを判定する
-func (g *Game) isBoardFull() bool {
-	for x := 0; x < BoardSize; x++ {
-		for y := 0; y < BoardSize; y++ {
-			if g.Board.Squares[x][y].IsEmpty() {
-				return false
-			}
-		}
-	}
-	return true
+	return rules.Legal(g.Board, x, y)
 }
 
 // placePiece は(x, y)にコマを置き、ルールに従って色変更を適用する
@@ -88,37 +22,12 @@ func (g *Game) placePiece(x, y int) bool {
 		}
 	}
 
-	if !g.isValidMove(x, y) {
+	// 挟み処理（色変更）を適用して新しいコマを配置
+	next, err := rules.Apply(g.Board, rules.Move{Row: x, Col: y, Color: g.NextColor})
+	if err != nil {
 		return false
 	}
-
-	// すべての挟みラインを見つける
-	flankingLines := g.findFlankingPieces(x, y)
-	fmt.Println("Flanking Lines:", flankingLines)
-
-	// 各挟みラインを処理
-	for _, line := range flankingLines {
-		if len(line) > 0 {
-			// 挟んでいるコマを取得
-			end := line[len(line)-1]
-
-			a1 := g.NextColor                               // 新しく配置されたコマ
-			a2 := g.Board.Squares[end.X][end.Y].Piece.Color // 遠端の挟んでいるコマ
-
-			// 間のすべてのコマに色変更を適用
-			for _, pos := range line {
-				piece := g.Board.Squares[pos.X][pos.Y].Piece
-				b1 := piece.Color
-
-				// 色変更式を適用: c = (a1 + a2 + b1) / 3
-				newColor := uint8((uint16(a1) + uint16(a2) + uint16(b1)) / 3)
-				piece.Color = newColor
-			}
-		}
-	}
-
-	// 新しいコマを配置
-	g.Board.Squares[x][y].Piece = &Piece{Color: g.NextColor}
+	g.Board = next
 
 	// 手を記録
 	g.recordMove(x, y, g.NextColor)
@@ -127,12 +36,14 @@ func (g *Game) placePiece(x, y int) bool {
 	// ここでは盤面処理のみを行う
 
 	// ゲーム終了判定（オンライン・オフライン共通）
-	// TODO: 本来はリバーシルールで両プレイヤーが打てる手がない場合も終了
-	boardFull := g.isBoardFull()
-	log.Printf("Board full check: %v", boardFull)
-
-	if boardFull {
-		log.Printf("Game ending - board is full")
+	// 置けるかどうかはコマの色に依らないため、どちらのプレイヤーも置けなければ終局とする。
+	// オンラインのパスはサーバが判定して通知する
+	g.PassMessage = ""
+	gameOver := len(rules.LegalMoves(g.Board, g.NextColor)) == 0
+	log.Printf("Game over check: %v", gameOver)
+
+	if gameOver {
+		log.Printf("Game ending - no legal moves remain")
 		g.GameOver = true
 		g.calculateWinner()
 
@@ -158,16 +69,3 @@ func (g *Game) placePiece(x, y int) bool {
 
 	return true
 }
-
-// getEmptySquares はボード上の全ての空きマスの座標を返す
-func (g *Game) getEmptySquares() []Position {
-	var emptySquares []Position
-	for x := 0; x < BoardSize; x++ {
-		for y := 0; y < BoardSize; y++ {
-			if g.Board.Squares[x][y].IsEmpty() {
-				emptySquares = append(emptySquares, Position{X: x, Y: y})
-			}
-		}
-	}
-	return emptySquares
-}
@@ -6,6 +6,7 @@ import (
 	"math/rand"
 	"time"
 
+	"github.com/2509-hackz-ichthyo/main/api/pkg/rules"
 	"github.com/hajimehoshi/ebiten/v2"
 	"github.com/hajimehoshi/ebiten/v2/inpututil"
 )
@@ -16,7 +17,7 @@ func NewGame() *Game {
 	r := rand.New(source)
 
 	game := &Game{
-		Board:       NewBoard(),
+		Board:       rules.NewBoard(),
 		CurrentTurn: true, // プレイヤー1から開始
 		Rand:        r,
 
@@ -115,6 +116,9 @@ func (g *Game) handleWebSocketMessage(message WSMessage) {
 	switch message.Type {
 	case "matchFound":
 		log.Printf("Match found! Room: %s, Role: %s", message.RoomID, message.Role)
+		if message.RatingBand != nil {
+			log.Printf("Matched by rating band: %s", message.RatingBand.Explanation)
+		}
 		g.RoomID = message.RoomID
 		g.PlayerRole = message.Role
 		g.State = GameStateInGame
@@ -131,12 +135,27 @@ func (g *Game) handleWebSocketMessage(message WSMessage) {
 		// ゲーム開始時に初期盤面をリセット（必要に応じて）
 		g.GameOver = false
 		g.Winner = ""
+		g.RatingChange = nil
 
 	case "waiting":
 		log.Printf("Waiting for opponent...")
 		g.State = GameStateWaiting
 		g.ErrorMessage = "新しいプレイヤーの到着を待っています..."
 
+	case "queueStatus":
+		log.Printf("Queue status: position=%d/%d", message.Position, message.QueueLength)
+		g.QueuePosition = message.Position
+		g.QueueLength = message.QueueLength
+		g.QueueEstimate = message.EstimatedWaitSeconds
+		g.queueStatusCounter = queueStatusInterval
+
+	case "queueLeft":
+		// マッチ済みの場合も届くため、待機中のときだけ状態を変える
+		log.Printf("Left the waiting queue")
+		if g.State == GameStateWaiting {
+			g.State = GameStateLeftQueue
+		}
+
 	case "gameUpdate":
 		log.Printf("Game update received: %+v", message.Data)
 		g.handleGameUpdate(message)
@@ -145,6 +164,32 @@ func (g *Game) handleWebSocketMessage(message WSMessage) {
 		log.Printf("Piece placed: user=%s, row=%d, col=%d, color=%d", message.UserID, message.Row, message.Col, message.Color)
 		g.handlePiecePlaced(message)
 
+	case "pass":
+		log.Printf("Pass: user=%s, next=%s, next color=%d", message.UserID, message.NextPlayer, message.NextColor)
+		g.handlePass(message)
+
+	case "gameFinished":
+		// 勝敗はサーバが自身の盤面から計算したものを正とする
+		log.Printf("Game finished by server: winner=%s", message.Winner)
+		g.GameOver = true
+		if message.Winner != "" {
+			g.Winner = message.Winner
+		}
+		for i := range message.RatingChanges {
+			if change := message.RatingChanges[i]; change.UserID == g.PlayerID {
+				log.Printf("Rating changed: %.0f -> %.0f (%+.0f)", change.Before, change.After, change.Delta)
+				g.RatingChange = &change
+			}
+		}
+
+	case "gameFinishedAck":
+		// 終了処理はサーバで一度だけ行われるため、ここでは結果を記録するだけ
+		log.Printf("gameFinished acknowledged: status=%s, winner=%s", message.Status, message.Winner)
+
+	case "moveRejected":
+		log.Printf("Move rejected: reason=%s, row=%d, col=%d, message=%s", message.Reason, message.Row, message.Col, message.Message)
+		g.handleMoveRejected(message)
+
 	case "opponentMove":
 		log.Printf("Opponent move: x=%d, y=%d, color=%d", message.X, message.Y, message.Color)
 		g.handleOpponentMove(message.X, message.Y, message.Color)
@@ -173,42 +218,43 @@ func (g *Game) handlePiecePlaced(message WSMessage) {
 		return
 	}
 
-	// サーバ確定手を盤面へ反映 (挟み処理を再計算するため placePiece 相当を再実装)
-	if g.Board.Squares[x][y].IsEmpty() {
-		// 既存ロジックに合わせて挟みライン検出
-		flankingLines := g.findFlankingPieces(x, y)
-		for _, line := range flankingLines {
-			if len(line) > 0 {
-				end := line[len(line)-1]
-				a1 := color
-				a2 := g.Board.Squares[end.X][end.Y].Piece.Color
-				for _, pos := range line {
-					piece := g.Board.Squares[pos.X][pos.Y].Piece
-					b1 := piece.Color
-					newColor := uint8((uint16(a1) + uint16(a2) + uint16(b1)) / 3)
-					piece.Color = newColor
-				}
-			}
-		}
-		g.Board.Squares[x][y].Piece = &Piece{Color: color}
+	// サーバが計算した着手後の盤面をそのまま反映する (挟み処理はクライアントで再計算しない)
+	board, err := rules.FromCells(message.Board)
+	if err != nil {
+		log.Printf("Invalid board in piecePlaced: %v; skipping", err)
+	} else {
+		g.Board = board
 		log.Printf("Applied confirmed move at (%d, %d) color %d", x, y, color)
 
 		// オンラインモードでも手を記録
 		g.recordMove(x, y, color)
-	} else {
-		// 既にコマが存在する場合は上書きしない (サーバと競合があるケースは将来 syncState で解決)
-		log.Printf("Square (%d,%d) already occupied; skipping overwrite", x, y)
 	}
 
-	if g.isBoardFull() {
+	// ターン情報を更新
+	g.PassMessage = ""
+	if message.NextPlayer != "" {
+		g.CurrentTurn = (message.NextPlayer == g.PlayerID)
+		g.NextColor = uint8(message.NextColor)
+		log.Printf("Next turn: %s (my turn: %v), next color: %d", message.NextPlayer, g.CurrentTurn, message.NextColor)
+	}
+
+	// ゲーム終了判定（どちらも置けなくなった時点でサーバが FINISHED にする）
+	if message.GamePhase == "FINISHED" {
 		g.GameOver = true
-		g.calculateWinner()
+		if message.Winner != "" {
+			// サーバが計算した勝者を表示する
+			g.Winner = message.Winner
+		} else {
+			// 勝者が指定されていない場合（旧サーバ）は、自分で計算
+			g.calculateWinner()
+		}
 		log.Printf("Game ended! Winner: %s", g.Winner)
 
 		// 対局記録を完了
 		g.finishGameRecord()
 
 		// オンラインモードの場合は、サーバーに終了と対局データを送信
+		// （記録・勝敗はサーバが自身の履歴から決めるため、整合性の確認にのみ使われる）
 		log.Printf("g.IsOnline: %v, g.State: %v, g.WSConnection: %v", g.IsOnline, g.State, g.WSConnection)
 		if g.IsOnline && g.State == GameStateInGame && g.WSConnection != nil {
 			err := g.WSConnection.FinishGameWithTextData(g.PlayerID, g.RoomID, g.Winner, g.GameRecord)
@@ -221,29 +267,32 @@ func (g *Game) handlePiecePlaced(message WSMessage) {
 			log.Printf("Not sending game data - conditions not met")
 		}
 	}
+}
 
-	// ターン情報を更新
-	if message.NextPlayer != "" {
-		g.CurrentTurn = (message.NextPlayer == g.PlayerID)
-		g.NextColor = uint8(message.NextColor)
-		log.Printf("Next turn: %s (my turn: %v), next color: %d", message.NextPlayer, g.CurrentTurn, message.NextColor)
+// passメッセージを処理（相手または自分に置ける手が無く、手番が続く場合）
+// 手番と色は直前の piecePlaced で更新済みのため、ここでは表示だけを行う
+func (g *Game) handlePass(message WSMessage) {
+	if message.UserID == g.PlayerID {
+		g.PassMessage = "置ける場所が無いためパスしました"
+	} else {
+		g.PassMessage = "相手はパスしました"
 	}
+	g.CurrentTurn = (message.NextPlayer == g.PlayerID)
+	g.NextColor = uint8(message.NextColor)
+}
 
-	// ゲーム終了判定
-	if message.GamePhase == "FINISHED" {
-		g.GameOver = true
-		if message.Winner != "" {
-			g.Winner = message.Winner
-			if message.Winner == g.PlayerID {
-				log.Printf("You won!")
-			} else {
-				log.Printf("Opponent won!")
-			}
-		} else {
-			// 勝者が指定されていない場合は、自分で計算
-			g.calculateWinner()
-			log.Printf("Game finished, calculated winner: %s", g.Winner)
-		}
+// moveRejectedメッセージを処理（サーバが着手を拒否した場合）
+func (g *Game) handleMoveRejected(message WSMessage) {
+	// 盤面がずれている可能性があるため、サーバの盤面に合わせる
+	if board, err := rules.FromCells(message.Board); err == nil {
+		g.Board = board
+	}
+	switch {
+	case message.Reason == "notYourTurn":
+		g.CurrentTurn = false
+	case message.Retryable:
+		// 同時に届いた別の手が先に確定した。手番は続く piecePlaced で更新されるため、同期後にもう一度置ける
+		log.Printf("Move was not applied because the game state changed; board resynchronized")
 	}
 }
 
@@ -255,7 +304,7 @@ func (g *Game) handleOpponentMove(x, y int, color uint8) {
 	}
 
 	// 相手のコマを配置（バリデーションは省略、サーバーで検証済みと仮定）
-	g.Board.Squares[x][y].Piece = &Piece{Color: color}
+	g.Board[x][y] = rules.Square{Occupied: true, Color: color}
 
 	// ターンを切り替え
 	g.switchTurn()
@@ -282,10 +331,10 @@ func (g *Game) handleGameUpdate(message WSMessage) {
 					color := gameState.BoardState[x][y]
 					if color != 0 {
 						// サーバーからの盤面データを反映
-						g.Board.Squares[x][y].Piece = &Piece{Color: uint8(color)}
+						g.Board[x][y] = rules.Square{Occupied: true, Color: uint8(color)}
 					} else {
-						// 空のマスは nil にする
-						g.Board.Squares[x][y].Piece = nil
+						// 空のマスにする
+						g.Board[x][y] = rules.Square{}
 					}
 				}
 			}
@@ -324,8 +373,9 @@ func (g *Game) Update() error {
 		return nil
 	}
 
-	// オンラインモードでゲーム中でない場合は、ゲーム操作を受け付けない
+	// オンラインモードでゲーム中でない場合は、待機列の操作のみ受け付ける
 	if g.IsOnline && g.State != GameStateInGame {
+		g.updateQueue()
 		return nil
 	}
 
@@ -374,6 +424,44 @@ func (g *Game) Update() error {
 	return nil
 }
 
+// updateQueue はマッチメイキング待機中の操作と queueStatus の定期問い合わせを行う
+func (g *Game) updateQueue() {
+	if g.WSConnection == nil {
+		return
+	}
+
+	switch g.State {
+	case GameStateWaiting:
+		// Escキーで待機をやめる
+		if inpututil.IsKeyJustPressed(ebiten.KeyEscape) {
+			if err := g.WSConnection.LeaveQueue(g.PlayerID); err != nil {
+				log.Printf("Failed to leave queue: %v", err)
+			}
+			return
+		}
+
+		g.queueStatusCounter--
+		if g.queueStatusCounter <= 0 {
+			g.queueStatusCounter = queueStatusInterval
+			if err := g.WSConnection.RequestQueueStatus(g.PlayerID); err != nil {
+				log.Printf("Failed to request queue status: %v", err)
+			}
+		}
+
+	case GameStateLeftQueue:
+		// Enterキーで再び待機列に並ぶ
+		if inpututil.IsKeyJustPressed(ebiten.KeyEnter) {
+			if err := g.WSConnection.JoinGame(g.PlayerID); err != nil {
+				log.Printf("Failed to join game: %v", err)
+				return
+			}
+			g.State = GameStateWaiting
+			g.QueuePosition = 0
+			g.QueueEstimate = nil
+		}
+	}
+}
+
 // generateNextColor はランダムに次のコマの色を生成する
 func (g *Game) generateNextColor() {
 	// 0-255の全範囲からランダムに色を生成
@@ -396,31 +484,7 @@ func (g *Game) getCurrentPlayer() string {
 
 // calculateWinner は全駒の色の平均値から勝者を決定する
 func (g *Game) calculateWinner() {
-	var colorSum int
-	var pieceCount int
-
-	for x := 0; x < BoardSize; x++ {
-		for y := 0; y < BoardSize; y++ {
-			if !g.Board.Squares[x][y].IsEmpty() {
-				colorSum += int(g.Board.Squares[x][y].Piece.Color)
-				pieceCount++
-			}
-		}
-	}
-
-	if pieceCount == 0 {
-		g.Winner = "引き分け"
-		return
-	}
-
-	average := float64(colorSum) / float64(pieceCount)
-
-	// 平均値が127.5未満なら黒、以上なら白の勝利
-	if average < 127.5 {
-		g.Winner = "黒"
-	} else {
-		g.Winner = "白"
-	}
+	g.Winner = rules.Score(g.Board).Winner.String()
 }
 
 // initializeGameRecord は対局記録を初期化する
@@ -502,24 +566,24 @@ func (g *Game) finishGameRecord() {
 		g.GameRecord.StartTime, g.GameRecord.EndTime)
 }
 
-// autoPlaceRandomPiece はランダムな空きマスにコマを自動配置する
+// autoPlaceRandomPiece はランダムな置けるマスにコマを自動配置する
 func (g *Game) autoPlaceRandomPiece() bool {
-	emptySquares := g.getEmptySquares()
-	if len(emptySquares) == 0 {
-		log.Printf("Debug mode: No empty squares available")
+	// 置ける手の中からランダムに選ぶ
+	moves := rules.LegalMoves(g.Board, g.NextColor)
+	if len(moves) == 0 {
+		log.Printf("Debug mode: No legal moves available")
 		return false
 	}
 
 	// ランダム選択
-	randomIndex := g.Rand.Intn(len(emptySquares))
-	selectedPos := emptySquares[randomIndex]
+	selected := moves[g.Rand.Intn(len(moves))]
 
 	// 配置実行
-	success := g.placePiece(selectedPos.X, selectedPos.Y)
+	success := g.placePiece(selected.Row, selected.Col)
 	if success {
-		log.Printf("Debug mode: Auto-placed piece at (%d, %d)", selectedPos.X, selectedPos.Y)
+		log.Printf("Debug mode: Auto-placed piece at (%d, %d)", selected.Row, selected.Col)
 	} else {
-		log.Printf("Debug mode: Failed to place piece at (%d, %d)", selectedPos.X, selectedPos.Y)
+		log.Printf("Debug mode: Failed to place piece at (%d, %d)", selected.Row, selected.Col)
 	}
 	return success
 }
@@ -59,9 +59,8 @@ func (g *Game) Draw(screen *ebiten.Image) {
 	// コマを描画
 	for x := 0; x < BoardSize; x++ {
 		for y := 0; y < BoardSize; y++ {
-			if !g.Board.Squares[x][y].IsEmpty() {
-				piece := g.Board.Squares[x][y].Piece
-				pieceColor := colorToRGB(piece.Color)
+			if square := g.Board[x][y]; square.Occupied {
+				pieceColor := colorToRGB(square.Color)
 
 				centerX := float32(BoardOffset + x*CellSize + CellSize/2)
 				centerY := float32(BoardOffset + y*CellSize + CellSize/2)
@@ -78,8 +77,20 @@ func (g *Game) Draw(screen *ebiten.Image) {
 		// 画面中央に勝利メッセージを表示
 		winMessage := fmt.Sprintf("どちらかというと %s の勝利！", g.Winner)
 
+		// オンライン対局ではサーバから届いたレーティングの変化を 2 行目に表示
+		ratingMessage := ""
+		if g.RatingChange != nil {
+			ratingMessage = fmt.Sprintf("レーティング %.0f → %.0f (%+.0f)", g.RatingChange.Before, g.RatingChange.After, g.RatingChange.Delta)
+		}
+
 		// テキストの大きさを測定
 		textWidth, textHeight := text.Measure(winMessage, g.FontFace, 0)
+		lineHeight := textHeight
+		if ratingMessage != "" {
+			ratingWidth, ratingHeight := text.Measure(ratingMessage, g.FontFace, 0)
+			textWidth = max(textWidth, ratingWidth)
+			textHeight += lineHeight/2 + ratingHeight
+		}
 
 		// メッセージを画面中央に配置
 		screenWidth, screenHeight := 800, 600
@@ -98,15 +109,33 @@ func (g *Game) Draw(screen *ebiten.Image) {
 
 		// テキストを描画
 		textOptions := &text.DrawOptions{}
-		textOptions.GeoM.Translate(messageX, messageY)
+		winWidth, _ := text.Measure(winMessage, g.FontFace, 0)
+		textOptions.GeoM.Translate(float64(screenWidth/2)-winWidth/2, messageY)
 		textOptions.ColorScale.ScaleWithColor(color.Black)
 		text.Draw(screen, winMessage, g.FontFace, textOptions)
+
+		if ratingMessage != "" {
+			ratingColor := color.RGBA{0, 120, 0, 255}
+			if g.RatingChange.Delta < 0 {
+				ratingColor = color.RGBA{180, 0, 0, 255}
+			}
+			ratingWidth, _ := text.Measure(ratingMessage, g.FontFace, 0)
+			ratingOptions := &text.DrawOptions{}
+			ratingOptions.GeoM.Translate(float64(screenWidth/2)-ratingWidth/2, messageY+lineHeight*1.5)
+			ratingOptions.ColorScale.ScaleWithColor(ratingColor)
+			text.Draw(screen, ratingMessage, g.FontFace, ratingOptions)
+		}
 	} else {
 		// 現在の手番を表示
 		g.drawCurrentTurnInfo(screen)
 
 		// 次のコマのプレビューを描画（右側に移動）
 		g.drawNextPiecePreview(screen)
+
+		// パスの通知を盤面の下に表示
+		if g.PassMessage != "" {
+			g.drawCenteredText(screen, g.PassMessage, 0, 270, color.RGBA{200, 80, 0, 255})
+		}
 	}
 }
 
@@ -129,6 +158,9 @@ func (g *Game) drawStatusScreen(screen *ebiten.Image) {
 	case GameStateWaiting:
 		message = "新しいプレイヤーの到着を待っています..."
 		bgColor = color.RGBA{255, 248, 200, 255}
+	case GameStateLeftQueue:
+		message = "マッチメイキングをキャンセルしました"
+		bgColor = color.RGBA{230, 230, 230, 255}
 	case GameStateError:
 		if g.ErrorMessage != "" {
 			message = g.ErrorMessage
@@ -154,9 +186,19 @@ func (g *Game) drawStatusScreen(screen *ebiten.Image) {
 	g.drawCenteredText(screen, message, 0, 0, color.Black)
 
 	// 状態に応じた追加情報
-	if g.State == GameStateWaiting {
+	switch g.State {
+	case GameStateWaiting:
 		subMessage := "しばらくお待ちください"
+		if g.QueuePosition > 0 {
+			subMessage = fmt.Sprintf("待機列 %d 番目 / %d 人", g.QueuePosition, g.QueueLength)
+			if g.QueueEstimate != nil {
+				subMessage += fmt.Sprintf("（あと約 %d 秒）", *g.QueueEstimate)
+			}
+		}
 		g.drawCenteredText(screen, subMessage, 0, 50, color.RGBA{120, 120, 120, 255})
+		g.drawCenteredText(screen, "Esc でキャンセル", 0, 100, color.RGBA{150, 150, 150, 255})
+	case GameStateLeftQueue:
+		g.drawCenteredText(screen, "Enter で再び対戦相手を探す", 0, 50, color.RGBA{120, 120, 120, 255})
 	}
 }
 
@@ -5,12 +5,13 @@ import (
 	"math/rand"
 	"strings"
 
+	"github.com/2509-hackz-ichthyo/main/api/pkg/rules"
 	"github.com/hajimehoshi/ebiten/v2/text/v2"
 )
 
 // 定数定義
 const (
-	BoardSize   = 8
+	BoardSize   = rules.Size
 	CellSize    = 60
 	BoardOffset = 50
 
@@ -27,37 +28,24 @@ const (
 	GameStateWaiting                       // マッチメイキング待機中
 	GameStateInGame                        // ゲーム中
 	GameStateError                         // エラー状態
+	GameStateLeftQueue                     // マッチメイキング待機をやめた状態
 )
 
-// Piece は0-255の色を持つゲームの駒を表す
-type Piece struct {
-	Color uint8 // 0-255: 0-127が黒側、128-255が白側
-}
-
-// Square はコマを最大1つまで保持できるボードのマスを表す
-type Square struct {
-	Piece *Piece // 空の場合はnil、そうでなければコマを格納
-}
-
-// IsEmpty はマスが空かどうかを返す
-func (s *Square) IsEmpty() bool {
-	return s.Piece == nil
-}
-
-// Board は8x8のリバーシ盤を表す
-type Board struct {
-	Squares [8][8]Square
-}
+// queueStatusInterval は待機中に queueStatus を問い合わせる間隔（ティック数、60 TPS で 10 秒）。
+// 問い合わせは表示を更新するための読み取りだけで、マッチングの再試行はサーバーが 1 分ごとに行う（その際にも queueStatus が届く）
+const queueStatusInterval = 600
 
 // Game はゲームの状態を表す
 type Game struct {
-	Board       *Board
-	CurrentTurn bool  // trueが黒 (0-127)、falseが白 (128-255)
-	NextColor   uint8 // 次に配置するコマの色
-	Rand        *rand.Rand
-	GameOver    bool             // ゲーム終了フラグ
-	Winner      string           // 勝者（"黒" または "白"）
-	FontFace    *text.GoTextFace // 勝利メッセージ用フォント
+	Board        rules.Board // 盤面（Board[x][y]）
+	CurrentTurn  bool        // trueが黒 (0-127)、falseが白 (128-255)
+	NextColor    uint8       // 次に配置するコマの色
+	Rand         *rand.Rand
+	GameOver     bool             // ゲーム終了フラグ
+	Winner       string           // 勝者（"黒" または "白"）
+	FontFace     *text.GoTextFace // 勝利メッセージ用フォント
+	PassMessage  string           // パスの通知（次の配置で消える）
+	RatingChange *RatingChange    // 自分のレーティングの変化（gameFinished で受け取るまでは nil）
 
 	// WebSocket関連
 	State        GameState     // 現在のゲーム状態
@@ -68,6 +56,12 @@ type Game struct {
 	IsOnline     bool          // オンラインモードフラグ
 	ErrorMessage string        // エラーメッセージ
 
+	// マッチメイキング待機関連
+	QueuePosition      int  // 待機列での順番（1 が次にマッチする、0 は未取得）
+	QueueLength        int  // 待機中のプレイヤー数
+	QueueEstimate      *int // 推定待ち時間（秒、サーバに実績が無い場合は nil）
+	queueStatusCounter int  // 次の queueStatus 問い合わせまでのティック数
+
 	// 対局記録関連
 	GameRecord *GameRecord // 対局記録
 
@@ -75,22 +69,6 @@ type Game struct {
 	DebugMode bool // デバッグモードフラグ
 }
 
-// Position はボード上の位置を表す
-type Position struct {
-	X, Y int
-}
-
-// Direction はボード上の8方向を表す
-type Direction struct {
-	dx, dy int
-}
-
-var directions = []Direction{
-	{-1, -1}, {-1, 0}, {-1, 1},
-	{0, -1}, {0, 1},
-	{1, -1}, {1, 0}, {1, 1},
-}
-
 // GameMove は1手の記録を表す
 type GameMove struct {
 	TurnNumber int    // ターン番号
@@ -36,22 +36,58 @@ type WSMessage struct {
 	Color     uint8            `json:"color,omitempty"`
 
 	// 新しいpiecePlaced用フィールド
-	NextPlayer string `json:"nextPlayer,omitempty"` // 次のターンのプレイヤー
-	NextColor  int    `json:"nextColor,omitempty"`  // 次に配置する色
-	GamePhase  string `json:"gamePhase,omitempty"`  // "PLAYING" or "FINISHED"
-	Winner     string `json:"winner,omitempty"`     // 勝者
+	NextPlayer string  `json:"nextPlayer,omitempty"` // 次のターンのプレイヤー
+	NextColor  int     `json:"nextColor,omitempty"`  // 次に配置する色
+	GamePhase  string  `json:"gamePhase,omitempty"`  // "PLAYING" or "FINISHED"
+	Winner     string  `json:"winner,omitempty"`     // 勝者
+	Board      [][]int `json:"board,omitempty"`      // 着手後の盤面（rules.Board.Cells 形式、-1 が空きマス）
+
+	// moveRejected用フィールド
+	Reason    string `json:"reason,omitempty"`    // 拒否理由 ("gameOver" / "notYourTurn" / "invalidPosition" / "occupied" / "noFlank" / "staleState")
+	Message   string `json:"message,omitempty"`   // 拒否の詳細
+	Retryable bool   `json:"retryable,omitempty"` // 盤面を同期し直せば再送できるか
+
+	// gameFinishedAck用フィールド
+	Status string `json:"status,omitempty"` // "finished" / "alreadyFinished" / "notFinished"
+
+	// gameFinished用フィールド
+	RatingChanges []RatingChange `json:"ratingChanges,omitempty"` // 両プレイヤーのレーティングの変化
+
+	// matchFound用フィールド
+	RatingBand *RatingBand `json:"ratingBand,omitempty"` // 対戦相手を選んだレーティング帯
+
+	// queueStatus用フィールド
+	Position             int  `json:"position,omitempty"`             // 待機列での順番
+	QueueLength          int  `json:"queueLength,omitempty"`          // 待機中のプレイヤー数
+	EstimatedWaitSeconds *int `json:"estimatedWaitSeconds,omitempty"` // 推定待ち時間（秒）
 
 	// 対局データ送信用フィールド
 	GameData *GameData `json:"gameData,omitempty"` // 対局データ
 }
 
+// RatingBand はマッチメイキングで対戦相手を選んだ理由
+type RatingBand struct {
+	Difference    float64 `json:"difference"`    // レーティング差
+	Window        float64 `json:"window"`        // マッチ時点の許容幅
+	WaitedSeconds int     `json:"waitedSeconds"` // 長く待っていた側の待ち時間
+	Explanation   string  `json:"explanation"`   // 表示用の説明
+}
+
+// RatingChange は対局の結果による 1 人分のレーティングの変化
+type RatingChange struct {
+	UserID string  `json:"userId"`
+	Before float64 `json:"before"` // 対局前のレーティング
+	After  float64 `json:"after"`  // 対局後のレーティング
+	Delta  float64 `json:"delta"`  // 変化量
+}
+
 // GameData は対局データをWebSocket送信用に変換した構造体
 type GameData struct {
 	GameID    string     `json:"gameId"`
 	StartTime string     `json:"startTime"`
 	EndTime   string     `json:"endTime"`
-	Moves     []MoveData `json:"moves"`     // 既存フィールド（互換性用）
-	GameText  string     `json:"gameText"`  // 新フィールド：テキスト形式
+	Moves     []MoveData `json:"moves"`    // 既存フィールド（互換性用）
+	GameText  string     `json:"gameText"` // 新フィールド：テキスト形式
 }
 
 // MoveData は1手の情報をWebSocket送信用に変換した構造体
@@ -199,6 +235,24 @@ func (ws *WSConnection) JoinGame(playerID string) error {
 	return ws.SendMessage(message)
 }
 
+// マッチメイキングの待機をやめる
+func (ws *WSConnection) LeaveQueue(playerID string) error {
+	message := WSMessage{
+		Action: "leaveQueue",
+		UserID: playerID,
+	}
+	return ws.SendMessage(message)
+}
+
+// 待機列での順番と推定待ち時間を問い合わせる（応答は queueStatus メッセージ、サーバー側の状態は変えない）
+func (ws *WSConnection) RequestQueueStatus(playerID string) error {
+	message := WSMessage{
+		Action: "queueStatus",
+		UserID: playerID,
+	}
+	return ws.SendMessage(message)
+}
+
 // ゲーム内でのコマ移動を送信
 func (ws *WSConnection) MakeMove(userID, roomID string, x, y int, color uint8) error {
 	message := WSMessage{
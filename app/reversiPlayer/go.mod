@@ -1,8 +1,11 @@
 module 2509_hackz_ichthyo
 
-go 1.24.1
+go 1.25.1
 
-require github.com/hajimehoshi/ebiten/v2 v2.8.8
+require (
+	github.com/2509-hackz-ichthyo/main/api v0.0.0-00010101000000-000000000000
+	github.com/hajimehoshi/ebiten/v2 v2.8.8
+)
 
 require (
 	github.com/ebitengine/gomobile v0.0.0-20240911145611-4856209ac325 // indirect
@@ -11,7 +14,10 @@ require (
 	github.com/go-text/typesetting v0.2.0 // indirect
 	github.com/jezek/xgb v1.1.1 // indirect
 	golang.org/x/image v0.20.0 // indirect
-	golang.org/x/sync v0.8.0 // indirect
-	golang.org/x/sys v0.25.0 // indirect
-	golang.org/x/text v0.18.0 // indirect
+	golang.org/x/sync v0.9.0 // indirect
+	golang.org/x/sys v0.27.0 // indirect
+	golang.org/x/text v0.20.0 // indirect
 )
+
+// Whitespace の変換規則（wscodec）と盤面のルール（rules）はリポジトリ内の api モジュールから取り込む
+replace github.com/2509-hackz-ichthyo/main/api => ../../api
@@ -9,6 +9,7 @@ import (
 	"log"
 	"syscall/js"
 
+	"github.com/2509-hackz-ichthyo/main/api/pkg/rules"
 	"github.com/hajimehoshi/ebiten/v2"
 	"github.com/hajimehoshi/ebiten/v2/text/v2"
 	"github.com/hajimehoshi/ebiten/v2/vector"
@@ -19,7 +20,7 @@ var fontData []byte
 
 type Game struct {
 	gameData          *GameData        // パースした対局データ
-	board             *Board           // 現在のボード状態
+	board             rules.Board      // 現在のボード状態
 	currentMove       int              // 現在の手数
 	timer             float64          // 経過時間（秒）
 	interval          float64          // コマ配置間隔（3秒）
@@ -53,90 +54,11 @@ type RandomGameResponse struct {
 }
 
 const (
-	BoardSize   = 8
+	BoardSize   = rules.Size
 	CellSize    = 60
 	BoardOffset = 50
 )
 
-// Position はボード上の位置を表す
-type Position struct {
-	X, Y int
-}
-
-// Direction はボード上の8方向を表す
-type Direction struct {
-	dx, dy int
-}
-
-var directions = []Direction{
-	{-1, -1}, {-1, 0}, {-1, 1},
-	{0, -1}, {0, 1},
-	{1, -1}, {1, 0}, {1, 1},
-}
-
-// isValidPosition は位置がボードの境界内かをチェックする
-func isValidPosition(x, y int) bool {
-	return x >= 0 && x < 8 && y >= 0 && y < 8
-}
-
-// findFlankingPieces は(x, y)にコマを置いた時に挟まれるコマを見つける
-func (g *Game) findFlankingPieces(x, y int, newColor uint8) [][]Position {
-	if !g.board.Squares[x][y].IsEmpty() {
-		return nil
-	}
-
-	var flankingLines [][]Position
-
-	for _, dir := range directions {
-		var line []Position
-		nx, ny := x+dir.dx, y+dir.dy
-
-		// この方向にコマがあるかを探す
-		for isValidPosition(nx, ny) && !g.board.Squares[nx][ny].IsEmpty() {
-			// コマを潜在的な挟みラインに追加
-			line = append(line, Position{nx, ny})
-			nx, ny = nx+dir.dx, ny+dir.dy
-		}
-
-		// ラインに少なくとも2つのコマがある場合、挟みが成立
-		if len(line) >= 2 {
-			// 最奥のコマは対象から外す
-			line = line[:len(line)-1]
-			flankingLines = append(flankingLines, line)
-		}
-	}
-
-	return flankingLines
-}
-
-// applyFlankingEffect は挟み処理（色変更）を適用する
-func (g *Game) applyFlankingEffect(x, y int, newColor uint8) {
-	flankingLines := g.findFlankingPieces(x, y, newColor)
-
-	// 各挟みラインを処理
-	for _, line := range flankingLines {
-		if len(line) > 0 {
-			// 挟んでいるコマを取得
-			end := line[len(line)-1]
-
-			a1 := newColor                                  // 新しく配置されたコマ
-			a2 := g.board.Squares[end.X][end.Y].Piece.Color // 遠端の挟んでいるコマ
-
-			// 間のすべてのコマに色変更を適用
-			for _, pos := range line {
-				piece := g.board.Squares[pos.X][pos.Y].Piece
-				if piece != nil {
-					b1 := piece.Color
-
-					// 色変更式を適用: c = (a1 + a2 + b1) / 3
-					newColorValue := uint8((uint16(a1) + uint16(a2) + uint16(b1)) / 3)
-					piece.Color = newColorValue
-				}
-			}
-		}
-	}
-}
-
 // colorToRGB は0-255の色値をRGBに変換する
 func colorToRGB(c uint8) color.RGBA {
 	return color.RGBA{R: c, G: c, B: c, A: 255}
@@ -144,35 +66,10 @@ func colorToRGB(c uint8) color.RGBA {
 
 // calculateWinner は全駒の色の平均値から勝者を決定する
 func (g *Game) calculateWinner() {
-	total := 0
-	count := 0
-
-	// 全ての駒の色を合計
-	for row := 0; row < BoardSize; row++ {
-		for col := 0; col < BoardSize; col++ {
-			square := &g.board.Squares[row][col]
-			if !square.IsEmpty() {
-				total += int(square.Piece.Color)
-				count++
-			}
-		}
-	}
+	result := rules.Score(g.board)
+	g.winner = result.Winner.String()
 
-	if count == 0 {
-		g.winner = "引き分け"
-		return
-	}
-
-	average := float64(total) / float64(count)
-
-	// 平均値が127.5未満なら黒、以上なら白の勝利
-	if average < 127.5 {
-		g.winner = "黒"
-	} else {
-		g.winner = "白"
-	}
-
-	fmt.Printf("対局終了！平均色値: %.2f, 勝者: %s\n", average, g.winner)
+	fmt.Printf("対局終了！平均色値: %.2f, 勝者: %s\n", result.Average, g.winner)
 }
 
 // initializeFont はフォントを初期化する
@@ -255,16 +152,9 @@ func fetchRandomGameData() (*GameArchive, error) {
 }
 
 // initializeBoard はリバーシの初期盤面を設定する
+// 初期配置は対局したクライアントと同じ rules.NewBoard を使う
 func (g *Game) initializeBoard() {
-	// ボードを空にする
-	g.board = &Board{}
-
-	// 中央の4つのコマを配置（リバーシの初期配置）
-	// 中央は (3,3), (3,4), (4,3), (4,4)
-	g.board.Squares[3][3].Piece = &Piece{Color: 255} // 白 (3,3)
-	g.board.Squares[3][4].Piece = &Piece{Color: 0}   // 黒 (3,4)
-	g.board.Squares[4][3].Piece = &Piece{Color: 0}   // 黒 (4,3)
-	g.board.Squares[4][4].Piece = &Piece{Color: 255} // 白 (4,4)
+	g.board = rules.NewBoard()
 }
 
 // NewGame は新しいゲームインスタンスを作成する
@@ -342,8 +232,8 @@ func (g *Game) loadNext24Data() error {
 		fmt.Println("DecodedData（10進数形式）を使用...")
 		gameData, err = parseGameData(gameArchive.DecodedData)
 	} else {
-		fmt.Println("GameData（レガシー形式）を使用...")
-		gameData, err = parseGameData(gameArchive.GameData)
+		fmt.Println("GameData（Whitespace形式）をデコード中...")
+		gameData, err = parseWhitespaceGameData(gameArchive.GameData)
 	}
 
 	if err != nil {
@@ -433,8 +323,12 @@ func (g *Game) placeNextPiece() {
 	}
 
 	// ボードに反映（挟み処理を適用）
-	g.applyFlankingEffect(move.Row, move.Col, move.Color)
-	g.board.Squares[move.Row][move.Col].Piece = &Piece{Color: move.Color}
+	next, err := rules.Apply(g.board, move)
+	if err != nil {
+		fmt.Printf("手%d を適用できません: %v\n", g.currentMove+1, err)
+		return
+	}
+	g.board = next
 
 	fmt.Printf("手%d: (%d,%d) 色=%d\n", g.currentMove+1, move.Row, move.Col, move.Color)
 }
@@ -507,15 +401,15 @@ func (g *Game) Draw(screen *ebiten.Image) {
 func (g *Game) drawPieces(screen *ebiten.Image) {
 	for row := 0; row < BoardSize; row++ {
 		for col := 0; col < BoardSize; col++ {
-			square := &g.board.Squares[row][col]
-			if !square.IsEmpty() {
+			square := g.board[row][col]
+			if square.Occupied {
 				// コマの位置を計算（row/colを入れ替えて回転を修正）
 				centerX := float32(BoardOffset + row*CellSize + CellSize/2)
 				centerY := float32(BoardOffset + col*CellSize + CellSize/2)
 				radius := float32(CellSize/2 - 4) // 少し余白を残す
 
 				// 色を取得してRGBAに変換
-				pieceColor := colorToRGB(square.Piece.Color)
+				pieceColor := colorToRGB(square.Color)
 
 				// 円を描画
 				vector.DrawFilledCircle(screen, centerX, centerY, radius, pieceColor, false)
@@ -1,67 +1,63 @@
 package main
 
-const mockPlayData = `0 0 0
-0 1 1
-0 2 2
-0 3 3
-0 4 4
-0 5 5
-0 6 6
-0 7 7
-1 0 8
-1 1 9
-1 2 10
-1 3 11
-1 4 12
-1 5 13
-1 6 14
-1 7 15
-2 0 16
-2 1 17
-2 2 18
-2 3 19
-2 4 20
-2 5 21
-2 6 22
-2 7 23
-3 0 24
-3 1 25
-3 2 26
-3 3 27
-3 4 28
-3 5 29
-3 6 30
-3 7 31
-4 0 32
-4 1 33
-4 2 34
-4 3 35
-4 4 36
-4 5 37
-4 6 38
-4 7 39
-5 0 40
-5 1 41
-5 2 42
-5 3 43
-5 4 44
-5 5 45
-5 6 46
-5 7 47
-6 0 48
-6 1 49
-6 2 50
-6 3 51
-6 4 52
-6 5 53
-6 6 54
-6 7 55
-7 0 56
-7 1 57
-7 2 58
-7 3 59
-7 4 60
-7 5 61
-7 6 62
-7 7 63
-`
\ No newline at end of file
+// mockPlayData は対局データを取得できない場合に再生する対局（全ての手が rules で置ける 60 手）
+const mockPlayData = `2 2 0
+4 5 182
+2 3 74
+3 2 161
+4 6 19
+1 2 140
+2 4 93
+5 2 246
+1 1 38
+1 5 225
+4 7 112
+1 3 204
+2 1 57
+3 1 183
+5 6 2
+4 0 162
+0 2 76
+5 3 141
+3 6 21
+4 1 247
+6 3 95
+6 7 226
+2 7 40
+0 0 205
+5 4 114
+5 5 184
+5 1 59
+1 4 163
+0 6 4
+6 6 142
+6 5 78
+0 3 248
+7 2 23
+4 2 227
+7 4 97
+6 1 206
+3 7 42
+7 1 185
+6 2 116
+6 0 164
+7 0 61
+7 7 143
+1 7 6
+5 7 249
+1 0 80
+0 1 228
+0 4 25
+2 5 207
+0 5 99
+2 0 186
+0 7 44
+7 3 165
+5 0 118
+1 6 144
+2 6 63
+3 0 250
+3 5 8
+6 4 229
+7 5 82
+7 6 208`
@@ -4,14 +4,13 @@ import (
 	"fmt"
 	"strconv"
 	"strings"
+
+	"github.com/2509-hackz-ichthyo/main/api/pkg/rules"
+	"github.com/2509-hackz-ichthyo/main/api/pkg/wscodec"
 )
 
-// Move は対局の1手を表す
-type Move struct {
-	Row   int   // 行位置 (0-7)
-	Col   int   // 列位置 (0-7)
-	Color uint8 // 色値 (0-255)
-}
+// Move は対局の1手を表す（クライアント・サーバと共通の rules.Move）
+type Move = rules.Move
 
 // GameData は対局データ全体を表す
 type GameData struct {
@@ -72,49 +71,37 @@ func parseGameData(data string) (*GameData, error) {
 	return gameData, nil
 }
 
-// Square はコマを最大1つまで保持できるボードのマスを表す（gameパッケージ互換）
-type Square struct {
-	Piece *Piece // 空の場合はnil、そうでなければコマを格納
-}
-
-// Piece は0-255の色を持つゲームの駒を表す（gameパッケージ互換）
-type Piece struct {
-	Color uint8 // 0-255: 0-127が黒側、128-255が白側
-}
+// parseWhitespaceGameData はWhitespace形式（アーカイブのgameData）の対局データをデコードする
+// Decode API と同じ規則（wscodec）でブラウザ内で変換するため、バックエンドを必要としない
+func parseWhitespaceGameData(data string) (*GameData, error) {
+	sentences, err := wscodec.DecodeWhitespace(data)
+	if err != nil {
+		return nil, err
+	}
 
-// Board は8x8のリバーシ盤を表す（gameパッケージ互換）
-type Board struct {
-	Squares [8][8]Square
-}
+	gameData := &GameData{}
+	for i, sentence := range sentences {
+		row, col, color := int(sentence[0]), int(sentence[1]), sentence[2]
+		if row > 7 || col > 7 {
+			return nil, fmt.Errorf("move %d: position (%d, %d) out of range (0-7)", i+1, row, col)
+		}
+		gameData.Moves = append(gameData.Moves, Move{Row: row, Col: col, Color: color})
+	}
 
-// IsEmpty はマスが空かどうかを返す
-func (s *Square) IsEmpty() bool {
-	return s.Piece == nil
+	return gameData, nil
 }
 
-// ApplyToBoard は指定した手数までをボードに適用する
-func (g *GameData) ApplyToBoard(board *Board, moveIndex int) error {
+// ApplyToBoard は初期盤面から指定した手数までを適用したボードを返す
+// 挟み処理（色変更）もクライアントと同じ rules で計算する
+func (g *GameData) ApplyToBoard(moveIndex int) (rules.Board, error) {
 	if moveIndex < 0 {
-		return fmt.Errorf("moveIndex cannot be negative")
+		return rules.Board{}, fmt.Errorf("moveIndex cannot be negative")
 	}
 	if moveIndex >= len(g.Moves) {
-		return fmt.Errorf("moveIndex %d exceeds available moves (%d)", moveIndex, len(g.Moves))
-	}
-
-	// ボードをクリア
-	for row := 0; row < 8; row++ {
-		for col := 0; col < 8; col++ {
-			board.Squares[row][col].Piece = nil
-		}
-	}
-
-	// 指定したインデックスまでの手を適用
-	for i := 0; i <= moveIndex; i++ {
-		move := g.Moves[i]
-		board.Squares[move.Row][move.Col].Piece = &Piece{Color: move.Color}
+		return rules.Board{}, fmt.Errorf("moveIndex %d exceeds available moves (%d)", moveIndex, len(g.Moves))
 	}
 
-	return nil
+	return rules.Replay(g.Moves[:moveIndex+1])
 }
 
 // Validate は対局データの整合性をチェックする
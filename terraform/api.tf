@@ -111,6 +111,38 @@ resource "aws_ecs_cluster" "hackz_ichthyo_ecs_cluster" {
   name = "hackz-ichthyo-ecs-cluster"
 }
 
+# Decode API の API キー
+# ゲームの Lambda 用のキーを生成し、API_KEY_FILE と同じ形式の JSON として Secrets Manager に保存する。
+# ECS タスクには API_KEYS_JSON として注入し、game_replay_handler は実行時にシークレットから読む
+data "aws_secretsmanager_random_password" "decode_api_game_lambda_key" {
+  password_length     = 40
+  exclude_punctuation = true
+}
+
+resource "aws_secretsmanager_secret" "decode_api_keys" {
+  name        = "decode-api/api-keys"
+  description = "API keys and per-key rate limits for the Decode API (API_KEYS_JSON)"
+}
+
+resource "aws_secretsmanager_secret_version" "decode_api_keys" {
+  secret_id = aws_secretsmanager_secret.decode_api_keys.id
+  # ゲームの Lambda はプレイヤーのリクエストをまとめて送るため、既定（10 rps / 20）より高い上限を割り当てる
+  secret_string = jsonencode([
+    {
+      client = "game-lambda"
+      key    = data.aws_secretsmanager_random_password.decode_api_game_lambda_key.random_password
+      rate   = 100
+      burst  = 200
+    }
+  ])
+
+  # data source は plan のたびに新しい値を返すため、最初に生成したキーを使い続ける。
+  # キーや上限を変える場合はシークレットを直接更新し、ECS タスクを再起動する
+  lifecycle {
+    ignore_changes = [secret_string]
+  }
+}
+
 # ECS Task Definition
 resource "aws_ecs_task_definition" "hackz_ichthyo_ecs_task_definition" {
   family                   = "hackz-ichthyo-ecs-task-definition"
@@ -127,6 +159,21 @@ resource "aws_ecs_task_definition" "hackz_ichthyo_ecs_task_definition" {
       memory    = 512
       essential = true
 
+      # /readyz が 503 になってから NLB が振り分け対象から外すまで（interval × unhealthy_threshold）受け付けを続ける
+      environment = [
+        {
+          name  = "SHUTDOWN_DRAIN_DELAY"
+          value = "20s"
+        }
+      ]
+      secrets = [
+        {
+          name      = "API_KEYS_JSON"
+          valueFrom = aws_secretsmanager_secret.decode_api_keys.arn
+        }
+      ]
+      stopTimeout = 40
+
       portMappings = [
         {
           containerPort = 3000
@@ -186,6 +233,23 @@ resource "aws_iam_role_policy_attachment" "hackz_ichthyo_ecs_task_execution_role
   policy_arn = "arn:aws:iam::aws:policy/service-role/AmazonECSTaskExecutionRolePolicy"
 }
 
+# API キーのシークレットをコンテナの環境変数に注入するために必要
+resource "aws_iam_role_policy" "hackz_ichthyo_ecs_task_execution_secrets" {
+  name = "hackz-ichthyo-ecs-task-execution-secrets"
+  role = aws_iam_role.hackz_ichthyo_ecs_task_execution_role.id
+
+  policy = jsonencode({
+    Version = "2012-10-17"
+    Statement = [
+      {
+        Effect   = "Allow"
+        Action   = ["secretsmanager:GetSecretValue"]
+        Resource = aws_secretsmanager_secret.decode_api_keys.arn
+      }
+    ]
+  })
+}
+
 # CloudWatch Log Group
 resource "aws_cloudwatch_log_group" "hackz_ichthyo_log_group" {
   name              = "/ecs/hackz-ichthyo-ecs"
@@ -229,7 +293,10 @@ resource "aws_lb_target_group" "hackz_ichthyo_tg" {
   health_check {
     enabled             = true
     healthy_threshold   = 2
-    protocol            = "TCP"
+    protocol            = "HTTP"
+    path                = "/readyz"
+    matcher             = "200"
+    interval            = 10
     unhealthy_threshold = 2
   }
 
@@ -377,11 +444,195 @@ resource "aws_api_gateway_integration_response" "replay_random_options_integrati
   depends_on = [aws_api_gateway_integration.replay_random_options_integration]
 }
 
+# API Gateway Resource: /leaderboard
+# レーティング順の一覧（?limit= と ?cursor= でページング）。game_replay_handler が処理する
+resource "aws_api_gateway_resource" "leaderboard_resource" {
+  rest_api_id = aws_api_gateway_rest_api.game_replay_api.id
+  parent_id   = aws_api_gateway_rest_api.game_replay_api.root_resource_id
+  path_part   = "leaderboard"
+}
+
+# API Gateway Method: GET /leaderboard
+resource "aws_api_gateway_method" "leaderboard_get" {
+  rest_api_id   = aws_api_gateway_rest_api.game_replay_api.id
+  resource_id   = aws_api_gateway_resource.leaderboard_resource.id
+  http_method   = "GET"
+  authorization = "NONE"
+}
+
+# API Gateway Method: OPTIONS /leaderboard (for CORS)
+resource "aws_api_gateway_method" "leaderboard_options" {
+  rest_api_id   = aws_api_gateway_rest_api.game_replay_api.id
+  resource_id   = aws_api_gateway_resource.leaderboard_resource.id
+  http_method   = "OPTIONS"
+  authorization = "NONE"
+}
+
+# API Gateway Integration: GET /leaderboard
+resource "aws_api_gateway_integration" "leaderboard_get_integration" {
+  rest_api_id = aws_api_gateway_rest_api.game_replay_api.id
+  resource_id = aws_api_gateway_resource.leaderboard_resource.id
+  http_method = aws_api_gateway_method.leaderboard_get.http_method
+
+  integration_http_method = "POST"
+  type                   = "AWS_PROXY"
+  uri                    = aws_lambda_function.game_replay_handler.invoke_arn
+}
+
+# API Gateway Integration: OPTIONS /leaderboard (for CORS)
+resource "aws_api_gateway_integration" "leaderboard_options_integration" {
+  rest_api_id = aws_api_gateway_rest_api.game_replay_api.id
+  resource_id = aws_api_gateway_resource.leaderboard_resource.id
+  http_method = aws_api_gateway_method.leaderboard_options.http_method
+
+  type                 = "MOCK"
+  passthrough_behavior = "WHEN_NO_MATCH"
+
+  request_templates = {
+    "application/json" = jsonencode({
+      statusCode = 200
+    })
+  }
+}
+
+# API Gateway Method Response: OPTIONS /leaderboard
+resource "aws_api_gateway_method_response" "leaderboard_options_response" {
+  rest_api_id = aws_api_gateway_rest_api.game_replay_api.id
+  resource_id = aws_api_gateway_resource.leaderboard_resource.id
+  http_method = aws_api_gateway_method.leaderboard_options.http_method
+  status_code = "200"
+
+  response_parameters = {
+    "method.response.header.Access-Control-Allow-Origin"  = true
+    "method.response.header.Access-Control-Allow-Methods" = true
+    "method.response.header.Access-Control-Allow-Headers" = true
+  }
+}
+
+# API Gateway Integration Response: OPTIONS /leaderboard
+resource "aws_api_gateway_integration_response" "leaderboard_options_integration_response" {
+  rest_api_id = aws_api_gateway_rest_api.game_replay_api.id
+  resource_id = aws_api_gateway_resource.leaderboard_resource.id
+  http_method = aws_api_gateway_method.leaderboard_options.http_method
+  status_code = aws_api_gateway_method_response.leaderboard_options_response.status_code
+
+  response_parameters = {
+    "method.response.header.Access-Control-Allow-Origin"  = "'*'"
+    "method.response.header.Access-Control-Allow-Methods" = "'GET,OPTIONS'"
+    "method.response.header.Access-Control-Allow-Headers" = "'Content-Type,X-Amz-Date,Authorization,X-Api-Key,X-Amz-Security-Token'"
+  }
+
+  depends_on = [aws_api_gateway_integration.leaderboard_options_integration]
+}
+
+# API Gateway Resource: /players
+resource "aws_api_gateway_resource" "players_resource" {
+  rest_api_id = aws_api_gateway_rest_api.game_replay_api.id
+  parent_id   = aws_api_gateway_rest_api.game_replay_api.root_resource_id
+  path_part   = "players"
+}
+
+# API Gateway Resource: /players/{id}
+resource "aws_api_gateway_resource" "player_resource" {
+  rest_api_id = aws_api_gateway_rest_api.game_replay_api.id
+  parent_id   = aws_api_gateway_resource.players_resource.id
+  path_part   = "{id}"
+}
+
+# API Gateway Resource: /players/{id}/rating
+# プレイヤー 1 人のレーティングと戦績。game_replay_handler が処理する
+resource "aws_api_gateway_resource" "player_rating_resource" {
+  rest_api_id = aws_api_gateway_rest_api.game_replay_api.id
+  parent_id   = aws_api_gateway_resource.player_resource.id
+  path_part   = "rating"
+}
+
+# API Gateway Method: GET /players/{id}/rating
+resource "aws_api_gateway_method" "player_rating_get" {
+  rest_api_id   = aws_api_gateway_rest_api.game_replay_api.id
+  resource_id   = aws_api_gateway_resource.player_rating_resource.id
+  http_method   = "GET"
+  authorization = "NONE"
+
+  request_parameters = {
+    "method.request.path.id" = true
+  }
+}
+
+# API Gateway Method: OPTIONS /players/{id}/rating (for CORS)
+resource "aws_api_gateway_method" "player_rating_options" {
+  rest_api_id   = aws_api_gateway_rest_api.game_replay_api.id
+  resource_id   = aws_api_gateway_resource.player_rating_resource.id
+  http_method   = "OPTIONS"
+  authorization = "NONE"
+}
+
+# API Gateway Integration: GET /players/{id}/rating
+resource "aws_api_gateway_integration" "player_rating_get_integration" {
+  rest_api_id = aws_api_gateway_rest_api.game_replay_api.id
+  resource_id = aws_api_gateway_resource.player_rating_resource.id
+  http_method = aws_api_gateway_method.player_rating_get.http_method
+
+  integration_http_method = "POST"
+  type                   = "AWS_PROXY"
+  uri                    = aws_lambda_function.game_replay_handler.invoke_arn
+}
+
+# API Gateway Integration: OPTIONS /players/{id}/rating (for CORS)
+resource "aws_api_gateway_integration" "player_rating_options_integration" {
+  rest_api_id = aws_api_gateway_rest_api.game_replay_api.id
+  resource_id = aws_api_gateway_resource.player_rating_resource.id
+  http_method = aws_api_gateway_method.player_rating_options.http_method
+
+  type                 = "MOCK"
+  passthrough_behavior = "WHEN_NO_MATCH"
+
+  request_templates = {
+    "application/json" = jsonencode({
+      statusCode = 200
+    })
+  }
+}
+
+# API Gateway Method Response: OPTIONS /players/{id}/rating
+resource "aws_api_gateway_method_response" "player_rating_options_response" {
+  rest_api_id = aws_api_gateway_rest_api.game_replay_api.id
+  resource_id = aws_api_gateway_resource.player_rating_resource.id
+  http_method = aws_api_gateway_method.player_rating_options.http_method
+  status_code = "200"
+
+  response_parameters = {
+    "method.response.header.Access-Control-Allow-Origin"  = true
+    "method.response.header.Access-Control-Allow-Methods" = true
+    "method.response.header.Access-Control-Allow-Headers" = true
+  }
+}
+
+# API Gateway Integration Response: OPTIONS /players/{id}/rating
+resource "aws_api_gateway_integration_response" "player_rating_options_integration_response" {
+  rest_api_id = aws_api_gateway_rest_api.game_replay_api.id
+  resource_id = aws_api_gateway_resource.player_rating_resource.id
+  http_method = aws_api_gateway_method.player_rating_options.http_method
+  status_code = aws_api_gateway_method_response.player_rating_options_response.status_code
+
+  response_parameters = {
+    "method.response.header.Access-Control-Allow-Origin"  = "'*'"
+    "method.response.header.Access-Control-Allow-Methods" = "'GET,OPTIONS'"
+    "method.response.header.Access-Control-Allow-Headers" = "'Content-Type,X-Amz-Date,Authorization,X-Api-Key,X-Amz-Security-Token'"
+  }
+
+  depends_on = [aws_api_gateway_integration.player_rating_options_integration]
+}
+
 # API Gateway Deployment
 resource "aws_api_gateway_deployment" "game_replay_api_deployment" {
   depends_on = [
     aws_api_gateway_integration.replay_random_get_integration,
-    aws_api_gateway_integration.replay_random_options_integration
+    aws_api_gateway_integration.replay_random_options_integration,
+    aws_api_gateway_integration.leaderboard_get_integration,
+    aws_api_gateway_integration.leaderboard_options_integration,
+    aws_api_gateway_integration.player_rating_get_integration,
+    aws_api_gateway_integration.player_rating_options_integration
   ]
 
   rest_api_id = aws_api_gateway_rest_api.game_replay_api.id
@@ -395,6 +646,18 @@ resource "aws_api_gateway_deployment" "game_replay_api_deployment" {
       aws_api_gateway_method.replay_random_options.id,
       aws_api_gateway_integration.replay_random_get_integration.id,
       aws_api_gateway_integration.replay_random_options_integration.id,
+      aws_api_gateway_resource.leaderboard_resource.id,
+      aws_api_gateway_method.leaderboard_get.id,
+      aws_api_gateway_method.leaderboard_options.id,
+      aws_api_gateway_integration.leaderboard_get_integration.id,
+      aws_api_gateway_integration.leaderboard_options_integration.id,
+      aws_api_gateway_resource.players_resource.id,
+      aws_api_gateway_resource.player_resource.id,
+      aws_api_gateway_resource.player_rating_resource.id,
+      aws_api_gateway_method.player_rating_get.id,
+      aws_api_gateway_method.player_rating_options.id,
+      aws_api_gateway_integration.player_rating_get_integration.id,
+      aws_api_gateway_integration.player_rating_options_integration.id,
     ]))
   }
 
@@ -419,6 +682,12 @@ output "game_replay_api_url" {
   value       = "${aws_api_gateway_deployment.game_replay_api_deployment.invoke_url}/replay/random"
 }
 
+# Output the leaderboard URL
+output "leaderboard_api_url" {
+  description = "URL for the leaderboard REST API (GET /players/{id}/rating is served from the same stage)"
+  value       = "${aws_api_gateway_deployment.game_replay_api_deployment.invoke_url}/leaderboard"
+}
+
 # Output the static IP address
 output "hackz_ichthyo_static_ip" {
   description = "Static IP address for ECS Decode API"
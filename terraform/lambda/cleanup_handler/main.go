@@ -7,6 +7,7 @@ import (
 	"strconv"
 	"time"
 
+	"github.com/2509-hackz-ichthyo/main/api/pkg/waitingqueue"
 	"github.com/aws/aws-lambda-go/events"
 	"github.com/aws/aws-lambda-go/lambda"
 	"github.com/aws/aws-sdk-go/aws"
@@ -181,13 +182,14 @@ func deleteRoomData(dynamo *dynamodb.DynamoDB, tableName, roomId string) (int, e
 	return len(deleteRequests), nil
 }
 
-// cleanupWaitingQueue removes old waiting entries
+// cleanupWaitingQueue removes old waiting entries, each together with the WAITING#userId marker
+// that stops the player from queueing twice
 func cleanupWaitingQueue(dynamo *dynamodb.DynamoDB, tableName string, cutoffTime int64) (int, error) {
 	input := &dynamodb.QueryInput{
 		TableName:              aws.String(tableName),
 		KeyConditionExpression: aws.String("PK = :pk AND SK <= :cutoff"),
 		ExpressionAttributeValues: map[string]*dynamodb.AttributeValue{
-			":pk":     {S: aws.String("WAITING_QUEUE")},
+			":pk":     {S: aws.String(waitingqueue.PartitionKey)},
 			":cutoff": {S: aws.String(strconv.FormatInt(cutoffTime, 10) + "#")},
 		},
 	}
@@ -197,27 +199,21 @@ func cleanupWaitingQueue(dynamo *dynamodb.DynamoDB, tableName string, cutoffTime
 		return 0, err
 	}
 
-	// Prepare delete requests
-	deleteRequests := []DeleteRequest{}
+	var entries []waitingqueue.Entry
 	for _, item := range result.Items {
-		if pk := item["PK"]; pk != nil && pk.S != nil {
-			if sk := item["SK"]; sk != nil && sk.S != nil {
-				deleteRequests = append(deleteRequests, DeleteRequest{
-					TableName: tableName,
-					PK:        *pk.S,
-					SK:        *sk.S,
-				})
-			}
+		entry, ok := waitingqueue.ParseEntry(item)
+		if !ok {
+			fmt.Printf("Skipping waiting entry without userId: %s\n", entry.QueueKey)
+			continue
 		}
+		entries = append(entries, entry)
 	}
 
-	// Execute batch delete
-	err = batchDeleteItems(dynamo, deleteRequests)
+	deleted, err := waitingqueue.Remove(dynamo, tableName, entries)
 	if err != nil {
-		return 0, err
+		fmt.Printf("Error deleting waiting entries: %v\n", err)
 	}
-
-	return len(deleteRequests), nil
+	return deleted, nil
 }
 
 // cleanupDisconnectedUsers removes users disconnected for more than 1 hour
@@ -1,10 +1,14 @@
 module disconnect_handler
 
-go 1.21
+go 1.25.1
 
 require (
+	github.com/2509-hackz-ichthyo/main/api v0.0.0-00010101000000-000000000000
 	github.com/aws/aws-lambda-go v1.47.0
-	github.com/aws/aws-sdk-go v1.54.6
+	github.com/aws/aws-sdk-go v1.55.8
 )
 
 require github.com/jmespath/go-jmespath v0.4.0 // indirect
+
+// 待機列から取り除く処理（waitingqueue）はリポジトリ内の api モジュールから取り込む
+replace github.com/2509-hackz-ichthyo/main/api => ../../../api
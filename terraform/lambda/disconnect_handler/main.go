@@ -5,11 +5,13 @@ import (
 	"fmt"
 	"os"
 
+	"github.com/2509-hackz-ichthyo/main/api/pkg/waitingqueue"
 	"github.com/aws/aws-lambda-go/events"
 	"github.com/aws/aws-lambda-go/lambda"
 	"github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/aws/session"
 	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbiface"
 )
 
 func handleDisconnect(ctx context.Context, request events.APIGatewayWebsocketProxyRequest) (events.APIGatewayProxyResponse, error) {
@@ -56,12 +58,56 @@ func handleDisconnect(ctx context.Context, request events.APIGatewayWebsocketPro
 
 	fmt.Printf("Connection %s deleted from DynamoDB successfully\n", connectionId)
 
+	// Remove the player from the matchmaking queue so that nobody is matched with a closed connection
+	gameTableName := os.Getenv("GAME_SERVICE_TABLE_NAME")
+	if gameTableName == "" {
+		gameTableName = "game-service"
+	}
+	removed, err := removeFromWaitingQueue(dynamo, gameTableName, connectionId)
+	if err != nil {
+		fmt.Printf("Error removing connection from waiting queue: %v\n", err)
+	} else if removed > 0 {
+		fmt.Printf("Removed %d waiting queue entries for connection %s\n", removed, connectionId)
+	}
+
 	return events.APIGatewayProxyResponse{
 		StatusCode: 200,
 		Body:       "Disconnected",
 	}, nil
 }
 
+// removeFromWaitingQueue deletes the WAITING_QUEUE entries that belong to connectionId,
+// each together with the WAITING#userId marker that stops the player from queueing twice
+func removeFromWaitingQueue(dynamo dynamodbiface.DynamoDBAPI, tableName, connectionId string) (int, error) {
+	input := &dynamodb.QueryInput{
+		TableName:              aws.String(tableName),
+		KeyConditionExpression: aws.String("PK = :pk"),
+		FilterExpression:       aws.String("connectionId = :connectionId"),
+		ExpressionAttributeValues: map[string]*dynamodb.AttributeValue{
+			":pk":           {S: aws.String(waitingqueue.PartitionKey)},
+			":connectionId": {S: aws.String(connectionId)},
+		},
+	}
+
+	var entries []waitingqueue.Entry
+	err := dynamo.QueryPages(input, func(page *dynamodb.QueryOutput, lastPage bool) bool {
+		for _, item := range page.Items {
+			entry, ok := waitingqueue.ParseEntry(item)
+			if !ok {
+				fmt.Printf("Skipping waiting queue item without userId: %s\n", entry.QueueKey)
+				continue
+			}
+			entries = append(entries, entry)
+		}
+		return true
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	return waitingqueue.Remove(dynamo, tableName, entries)
+}
+
 func main() {
 	lambda.Start(handleDisconnect)
 }
@@ -0,0 +1,115 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbiface"
+)
+
+// fakeDynamo keeps items keyed by "PK|SK" and implements the calls removeFromWaitingQueue makes.
+// Methods it does not implement panic through the embedded nil interface.
+type fakeDynamo struct {
+	dynamodbiface.DynamoDBAPI
+
+	items map[string]map[string]*dynamodb.AttributeValue
+}
+
+func itemKey(key map[string]*dynamodb.AttributeValue) string {
+	return aws.StringValue(key["PK"].S) + "|" + aws.StringValue(key["SK"].S)
+}
+
+func (f *fakeDynamo) put(item map[string]*dynamodb.AttributeValue) {
+	f.items[itemKey(item)] = item
+}
+
+func (f *fakeDynamo) enqueue(userId, connectionId, queueKey string) {
+	entry := map[string]*dynamodb.AttributeValue{
+		"PK":           {S: aws.String("WAITING_QUEUE")},
+		"SK":           {S: aws.String(queueKey)},
+		"connectionId": {S: aws.String(connectionId)},
+	}
+	if userId != "" {
+		entry["userId"] = &dynamodb.AttributeValue{S: aws.String(userId)}
+		f.put(map[string]*dynamodb.AttributeValue{
+			"PK":       {S: aws.String("WAITING#" + userId)},
+			"SK":       {S: aws.String("QUEUE")},
+			"queueKey": {S: aws.String(queueKey)},
+		})
+	}
+	f.put(entry)
+}
+
+// QueryPages serves the WAITING_QUEUE partition filtered by connectionId, in a single page
+func (f *fakeDynamo) QueryPages(input *dynamodb.QueryInput, fn func(*dynamodb.QueryOutput, bool) bool) error {
+	pk := aws.StringValue(input.ExpressionAttributeValues[":pk"].S)
+	connectionId := aws.StringValue(input.ExpressionAttributeValues[":connectionId"].S)
+	page := &dynamodb.QueryOutput{}
+	for _, item := range f.items {
+		if aws.StringValue(item["PK"].S) == pk && aws.StringValue(item["connectionId"].S) == connectionId {
+			page.Items = append(page.Items, item)
+		}
+	}
+	fn(page, true)
+	return nil
+}
+
+// TransactWriteItems applies deletes, checking attribute_exists(PK) and the marker's queueKey condition
+func (f *fakeDynamo) TransactWriteItems(input *dynamodb.TransactWriteItemsInput) (*dynamodb.TransactWriteItemsOutput, error) {
+	reasons := make([]*dynamodb.CancellationReason, len(input.TransactItems))
+	failed := false
+	for i, item := range input.TransactItems {
+		reasons[i] = &dynamodb.CancellationReason{Code: aws.String("None")}
+		existing, exists := f.items[itemKey(item.Delete.Key)]
+		holds := true
+		switch aws.StringValue(item.Delete.ConditionExpression) {
+		case "attribute_exists(PK)":
+			holds = exists
+		case "attribute_not_exists(PK) OR queueKey = :queueKey":
+			holds = !exists || aws.StringValue(existing["queueKey"].S) == aws.StringValue(item.Delete.ExpressionAttributeValues[":queueKey"].S)
+		}
+		if !holds {
+			reasons[i].Code = aws.String("ConditionalCheckFailed")
+			failed = true
+		}
+	}
+	if failed {
+		return nil, &dynamodb.TransactionCanceledException{CancellationReasons: reasons}
+	}
+	for _, item := range input.TransactItems {
+		delete(f.items, itemKey(item.Delete.Key))
+	}
+	return &dynamodb.TransactWriteItemsOutput{}, nil
+}
+
+func TestRemoveFromWaitingQueue(t *testing.T) {
+	f := &fakeDynamo{items: map[string]map[string]*dynamodb.AttributeValue{}}
+	f.enqueue("alice", "conn-a", "0001#alice")
+	f.enqueue("bob", "conn-b", "0002#bob")
+	f.enqueue("", "conn-a", "0003#") // written without userId; its marker cannot be found
+
+	removed, err := removeFromWaitingQueue(f, "game-service", "conn-a")
+	if err != nil {
+		t.Fatalf("removeFromWaitingQueue: %v", err)
+	}
+	if removed != 1 {
+		t.Fatalf("removed = %d, want 1", removed)
+	}
+	for _, key := range []string{"WAITING_QUEUE|0001#alice", "WAITING#alice|QUEUE"} {
+		if _, ok := f.items[key]; ok {
+			t.Fatalf("%s was not removed", key)
+		}
+	}
+	for _, key := range []string{"WAITING_QUEUE|0002#bob", "WAITING#bob|QUEUE", "WAITING_QUEUE|0003#"} {
+		if _, ok := f.items[key]; !ok {
+			t.Fatalf("%s was removed", key)
+		}
+	}
+
+	// A second disconnect finds nothing left to remove
+	removed, err = removeFromWaitingQueue(f, "game-service", "conn-a")
+	if err != nil || removed != 0 {
+		t.Fatalf("second removeFromWaitingQueue = %d, %v; want 0, nil", removed, err)
+	}
+}
@@ -1,25 +1,42 @@
 package main
 
 import (
-	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"math"
 	"math/rand"
-	"net/http"
 	"os"
 	"strconv"
 	"strings"
 	"time"
 
+	"github.com/2509-hackz-ichthyo/main/api/pkg/rating"
+	"github.com/2509-hackz-ichthyo/main/api/pkg/rules"
+	"github.com/2509-hackz-ichthyo/main/api/pkg/wscodec"
 	"github.com/aws/aws-lambda-go/events"
 	"github.com/aws/aws-lambda-go/lambda"
 	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
 	"github.com/aws/aws-sdk-go/aws/session"
 	"github.com/aws/aws-sdk-go/service/apigatewaymanagementapi"
+	"github.com/aws/aws-sdk-go/service/apigatewaymanagementapi/apigatewaymanagementapiiface"
 	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbiface"
 )
 
+// errStaleState is returned when another invocation has already saved the turn being written.
+// The game state has moved on, so the move can be retried against the latest state.
+var errStaleState = errors.New("stale game state: another move was saved first")
+
+// errRatingConflict is returned when a player's rating kept changing under us (they finished another game at the same time).
+// Nothing is written in that case, so the finalization is retried by the next gameFinished.
+var errRatingConflict = errors.New("rating was updated concurrently")
+
+// maxRatingAttempts bounds how many times the finalization re-reads the ratings and retries after losing a race
+const maxRatingAttempts = 3
+
 // MakeMoveRequest represents the request body for makeMove
 type MakeMoveRequest struct {
 	Action string `json:"action"`
@@ -35,8 +52,8 @@ type GameFinishedRequest struct {
 	Action   string    `json:"action"`
 	UserId   string    `json:"userId"`
 	RoomId   string    `json:"roomId"`
-	Winner   string    `json:"winner"`
-	GameData *GameData `json:"gameData,omitempty"` // クライアントから送信される対局データ
+	Winner   string    `json:"winner"`             // 整合性チェックのログにのみ使う
+	GameData *GameData `json:"gameData,omitempty"` // クライアントから送信される対局データ（整合性チェックのログにのみ使う）
 }
 
 // GameData はクライアントから送信される対局データ
@@ -55,14 +72,15 @@ type MoveData struct {
 	Color uint8 `json:"color"`
 }
 
-// GameState represents the current game state (simplified - no board state)
+// GameState represents the current game state
 type GameState struct {
-	RoomId        string `json:"roomId"`
-	TurnNumber    int    `json:"turnNumber"`
-	CurrentPlayer string `json:"currentPlayer"`
-	NextColor     int    `json:"nextColor"`
-	GamePhase     string `json:"gamePhase"`
-	Winner        string `json:"winner,omitempty"`
+	RoomId        string      `json:"roomId"`
+	TurnNumber    int         `json:"turnNumber"`
+	CurrentPlayer string      `json:"currentPlayer"`
+	NextColor     int         `json:"nextColor"`
+	GamePhase     string      `json:"gamePhase"`
+	Winner        string      `json:"winner,omitempty"`
+	Board         rules.Board `json:"-"` // Server-authoritative board, stored with each TURN# item
 }
 
 // GameUpdateResponse represents the response sent to players
@@ -73,15 +91,45 @@ type GameUpdateResponse struct {
 
 // PiecePlacedResponse represents the response when a piece is placed
 type PiecePlacedResponse struct {
-	Type       string `json:"type"`   // "piecePlaced"
-	UserId     string `json:"userId"` // 配置したプレイヤー
-	Row        int    `json:"row"`
-	Col        int    `json:"col"`
-	Color      int    `json:"color"`
-	NextPlayer string `json:"nextPlayer"` // 次のターンのプレイヤー
-	NextColor  int    `json:"nextColor"`  // 次に配置する色
-	GamePhase  string `json:"gamePhase"`  // "PLAYING" or "FINISHED"
-	Winner     string `json:"winner,omitempty"`
+	Type       string  `json:"type"`   // "piecePlaced"
+	UserId     string  `json:"userId"` // 配置したプレイヤー
+	Row        int     `json:"row"`
+	Col        int     `json:"col"`
+	Color      int     `json:"color"`
+	NextPlayer string  `json:"nextPlayer"` // 次のターンのプレイヤー
+	NextColor  int     `json:"nextColor"`  // 次に配置する色
+	GamePhase  string  `json:"gamePhase"`  // "PLAYING" or "FINISHED"
+	Winner     string  `json:"winner,omitempty"`
+	Board      [][]int `json:"board"` // Board after the move (rules.Board.Cells; -1 = empty)
+}
+
+// MoveRejectedResponse is sent only to the player whose move was rejected
+type MoveRejectedResponse struct {
+	Type      string  `json:"type"`   // "moveRejected"
+	Reason    string  `json:"reason"` // "gameOver", "notYourTurn", "invalidPosition", "occupied", "noFlank" or "staleState"
+	Message   string  `json:"message"`
+	Retryable bool    `json:"retryable,omitempty"` // true if the move may succeed after resynchronizing
+	Row       int     `json:"row"`
+	Col       int     `json:"col"`
+	Board     [][]int `json:"board"` // Current board so that the client can resynchronize
+}
+
+// PassResponse is broadcast after piecePlaced when the next player has no legal move.
+// The player who just moved keeps the turn and places again with NextColor.
+type PassResponse struct {
+	Type       string `json:"type"`       // "pass"
+	UserId     string `json:"userId"`     // パスしたプレイヤー
+	NextPlayer string `json:"nextPlayer"` // 続けて配置するプレイヤー
+	NextColor  int    `json:"nextColor"`
+}
+
+// GameFinishedAck is sent only to the player who reported gameFinished
+type GameFinishedAck struct {
+	Type              string `json:"type"`   // "gameFinishedAck"
+	Status            string `json:"status"` // "finished", "alreadyFinished" or "notFinished"
+	RoomId            string `json:"roomId"`
+	Winner            string `json:"winner,omitempty"`
+	FinalizationToken string `json:"finalizationToken,omitempty"` // SK of the final turn
 }
 
 // GameRoom represents room metadata
@@ -91,6 +139,8 @@ type GameRoom struct {
 	Player1Id   string `json:"player1Id"`
 	Player2Id   string `json:"player2Id"`
 	PlayerCount int    `json:"playerCount"`
+	// FinalizationToken is set once the game has been finalized
+	FinalizationToken string `json:"finalizationToken,omitempty"`
 }
 
 // Player represents a player in a room
@@ -100,6 +150,25 @@ type Player struct {
 	ConnectionId string `json:"connectionId"`
 }
 
+// RatingChange is one player's rating before and after a finished game, sent in gameFinished
+type RatingChange struct {
+	UserId string  `json:"userId"`
+	Before float64 `json:"before"`
+	After  float64 `json:"after"`
+	Delta  float64 `json:"delta"`
+}
+
+// PlayerRating is the PLAYER#<userId>/RATING item shared with matchmaking_handler and game_replay_handler
+type PlayerRating struct {
+	UserId  string
+	Rating  float64
+	Games   int
+	Wins    int
+	Losses  int
+	Draws   int
+	Version int // 0 if the player has no rating item yet
+}
+
 func handleGameMove(ctx context.Context, request events.APIGatewayWebsocketProxyRequest) (events.APIGatewayProxyResponse, error) {
 	connectionId := request.RequestContext.ConnectionID
 	fmt.Printf("Game request from connection: %s\n", connectionId)
@@ -171,16 +240,25 @@ func handleMakeMove(ctx context.Context, request events.APIGatewayWebsocketProxy
 		return events.APIGatewayProxyResponse{StatusCode: 500}, err
 	}
 
-	// Validate turn
-	if currentGameState.CurrentPlayer != moveRequest.UserId {
-		fmt.Printf("Not player's turn: %s, current: %s\n", moveRequest.UserId, currentGameState.CurrentPlayer)
-		return events.APIGatewayProxyResponse{StatusCode: 400}, fmt.Errorf("not your turn")
-	}
-
-	// Simple validation: check if position is valid (0-7)
-	if moveRequest.Row < 0 || moveRequest.Row >= 8 || moveRequest.Col < 0 || moveRequest.Col >= 8 {
-		fmt.Printf("Invalid position: row=%d, col=%d\n", moveRequest.Row, moveRequest.Col)
-		return events.APIGatewayProxyResponse{StatusCode: 400}, fmt.Errorf("invalid position")
+	// Validate the move against the server-side board
+	thisTurnColor := currentGameState.NextColor // クライアント送信値は無視しサーバ authoritative
+	move := rules.Move{Row: moveRequest.Row, Col: moveRequest.Col, Color: uint8(thisTurnColor)}
+	board, reason, err := applyMove(*currentGameState, moveRequest.UserId, move)
+	if err != nil {
+		fmt.Printf("Move rejected (%s): user=%s, row=%d, col=%d: %v\n", reason, moveRequest.UserId, move.Row, move.Col, err)
+		rejection := MoveRejectedResponse{
+			Type:    "moveRejected",
+			Reason:  reason,
+			Message: err.Error(),
+			Row:     move.Row,
+			Col:     move.Col,
+			Board:   currentGameState.Board.Cells(),
+		}
+		if err := sendMessage(apiGW, connectionId, rejection); err != nil {
+			fmt.Printf("Error sending rejection to %s: %v\n", connectionId, err)
+		}
+		// The rejection has been reported to the sender; returning an error would surface as "Internal server error"
+		return events.APIGatewayProxyResponse{StatusCode: 200}, nil
 	}
 
 	// Get room info for next player
@@ -190,29 +268,26 @@ func handleMakeMove(ctx context.Context, request events.APIGatewayWebsocketProxy
 		return events.APIGatewayProxyResponse{StatusCode: 500}, err
 	}
 
-	// Determine next player
-	var nextPlayer string
+	// 次ターンの色を生成 (先行:0-128, 後攻:129-255)
+	opponent := room.Player1Id
 	if currentGameState.CurrentPlayer == room.Player1Id {
-		nextPlayer = room.Player2Id
-	} else {
-		nextPlayer = room.Player1Id
+		opponent = room.Player2Id
 	}
+	opponentColor := generateNextColorForPlayer(opponent == room.Player1Id)
+	moverColor := generateNextColorForPlayer(currentGameState.CurrentPlayer == room.Player1Id)
 
-	// Check if game is finished (64 moves = full board)
-	gamePhase := "PLAYING"
-	winner := ""
-	if currentGameState.TurnNumber >= 64 {
-		gamePhase = "FINISHED"
-		fmt.Printf("Game finished due to full board (64 moves)")
+	// Decide who moves next: the opponent, the same player again (pass), or nobody (game over)
+	turn := rules.NextTurn(board, uint8(opponentColor), uint8(moverColor))
+	next := advanceTurn(turn, board, currentGameState.CurrentPlayer, opponent, moverColor, opponentColor)
+	nextPlayer, nextColor := next.Player, next.Color
+	gamePhase, winner, passedPlayer := next.GamePhase, next.Winner, next.PassedPlayer
+	switch turn {
+	case rules.TurnPass:
+		fmt.Printf("Player %s has no legal move and passes\n", opponent)
+	case rules.TurnGameOver:
+		fmt.Printf("Game finished: no legal moves remain (turn %d), winner %s\n", currentGameState.TurnNumber+1, winner)
 	}
 
-	// 現在ターンで使用する色 (クライアント送信値は無視しサーバ authoritative)
-	thisTurnColor := currentGameState.NextColor
-
-	// 次ターンの色を生成 (先行:0-128, 後攻:129-255)
-	isNextPlayer1 := (nextPlayer == room.Player1Id)
-	nextColor := generateNextColorForPlayer(isNextPlayer1)
-
 	// Create new simplified game state
 	newGameState := GameState{
 		RoomId:        moveRequest.RoomId,
@@ -221,10 +296,34 @@ func handleMakeMove(ctx context.Context, request events.APIGatewayWebsocketProxy
 		NextColor:     nextColor,
 		GamePhase:     gamePhase,
 		Winner:        winner,
+		Board:         board,
 	}
 
 	// Save new game state to DynamoDB with move details
 	err = saveGameStateWithMove(dynamo, tableName, newGameState, moveRequest.Row, moveRequest.Col, thisTurnColor)
+	if errors.Is(err, errStaleState) {
+		// Lost the race for this turn: send the latest board so that the client can retry
+		fmt.Printf("Move rejected (staleState): user=%s, turn=%d\n", moveRequest.UserId, newGameState.TurnNumber)
+		latest := currentGameState
+		if reloaded, err := getCurrentGameState(dynamo, tableName, moveRequest.RoomId); err == nil {
+			latest = reloaded
+		} else {
+			fmt.Printf("Error reloading game state: %v\n", err)
+		}
+		rejection := MoveRejectedResponse{
+			Type:      "moveRejected",
+			Reason:    "staleState",
+			Message:   err.Error(),
+			Retryable: true,
+			Row:       move.Row,
+			Col:       move.Col,
+			Board:     latest.Board.Cells(),
+		}
+		if err := sendMessage(apiGW, connectionId, rejection); err != nil {
+			fmt.Printf("Error sending rejection to %s: %v\n", connectionId, err)
+		}
+		return events.APIGatewayProxyResponse{StatusCode: 200}, nil
+	}
 	if err != nil {
 		fmt.Printf("Error saving game state: %v\n", err)
 		return events.APIGatewayProxyResponse{StatusCode: 500}, err
@@ -248,6 +347,7 @@ func handleMakeMove(ctx context.Context, request events.APIGatewayWebsocketProxy
 		NextColor:  nextColor,
 		GamePhase:  gamePhase,
 		Winner:     winner,
+		Board:      board.Cells(),
 	}
 
 	for _, player := range players {
@@ -257,9 +357,77 @@ func handleMakeMove(ctx context.Context, request events.APIGatewayWebsocketProxy
 		}
 	}
 
+	// The final move closes the room and archives the game from the server's own history
+	if gamePhase == "FINISHED" {
+		finishGame(dynamo, apiGW, tableName, moveRequest.RoomId, turnKey(newGameState.TurnNumber), winner, players)
+	}
+
+	// Tell both players about the pass so that the UI can show it
+	if passedPlayer != "" {
+		pass := PassResponse{
+			Type:       "pass",
+			UserId:     passedPlayer,
+			NextPlayer: nextPlayer,
+			NextColor:  nextColor,
+		}
+		for _, player := range players {
+			if err := sendMessage(apiGW, player.ConnectionId, pass); err != nil {
+				fmt.Printf("Error sending pass to player %s: %v\n", player.UserId, err)
+			}
+		}
+	}
+
 	return events.APIGatewayProxyResponse{StatusCode: 200}, nil
 }
 
+// nextTurn is who moves after a move has been applied, as broadcast in piecePlaced and pass
+type nextTurn struct {
+	Player       string
+	Color        int
+	GamePhase    string // "PLAYING" or "FINISHED"
+	Winner       string // set only when GamePhase is FINISHED
+	PassedPlayer string // set only when the opponent had to pass
+}
+
+// advanceTurn turns the result of rules.NextTurn into the next player, color and phase.
+// Whether a square is legal does not depend on the color under the current rules, so if the opponent
+// cannot move the mover cannot either and rules.NextTurn never returns TurnPass in a real game.
+// The pass branch is defensive: it keeps the game going if the rules ever make legality color-dependent.
+func advanceTurn(turn rules.Turn, board rules.Board, mover, opponent string, moverColor, opponentColor int) nextTurn {
+	switch turn {
+	case rules.TurnPass:
+		return nextTurn{Player: mover, Color: moverColor, GamePhase: "PLAYING", PassedPlayer: opponent}
+	case rules.TurnGameOver:
+		// Nobody moves any more; the opponent is kept as the current player as before the game ended
+		return nextTurn{Player: opponent, Color: opponentColor, GamePhase: "FINISHED", Winner: rules.Score(board).Winner.String()}
+	default:
+		return nextTurn{Player: opponent, Color: opponentColor, GamePhase: "PLAYING"}
+	}
+}
+
+// applyMove checks whose turn it is and applies the move to the current board with the shared rules.
+// If the move is rejected, it returns the reason sent to the client together with the error.
+func applyMove(state GameState, userId string, move rules.Move) (rules.Board, string, error) {
+	if state.GamePhase == "FINISHED" {
+		return state.Board, "gameOver", fmt.Errorf("game is already finished")
+	}
+	if state.CurrentPlayer != userId {
+		return state.Board, "notYourTurn", fmt.Errorf("not your turn")
+	}
+
+	board, err := rules.Apply(state.Board, move)
+	switch {
+	case err == nil:
+		return board, "", nil
+	case errors.Is(err, rules.ErrOutOfBounds):
+		return board, "invalidPosition", err
+	case errors.Is(err, rules.ErrOccupied):
+		return board, "occupied", err
+	default:
+		return board, "noFlank", err
+	}
+}
+
 func handleGameFinished(ctx context.Context, request events.APIGatewayWebsocketProxyRequest) (events.APIGatewayProxyResponse, error) {
 	connectionId := request.RequestContext.ConnectionID
 	fmt.Printf("Game finished notification from connection: %s\n", connectionId)
@@ -299,45 +467,99 @@ func handleGameFinished(ctx context.Context, request events.APIGatewayWebsocketP
 		tableName = "game-service"
 	}
 
-	// Update room status to FINISHED
-	err = updateRoomStatus(dynamo, tableName, finishRequest.RoomId, "FINISHED")
+	// The result has already been decided by handleMakeMove; the client's report is only checked for consistency
+	state, err := getCurrentGameState(dynamo, tableName, finishRequest.RoomId)
 	if err != nil {
-		fmt.Printf("Error updating room status: %v\n", err)
+		fmt.Printf("Error getting current game state: %v\n", err)
 		return events.APIGatewayProxyResponse{StatusCode: 500}, err
 	}
+	ack := GameFinishedAck{Type: "gameFinishedAck", Status: "notFinished", RoomId: finishRequest.RoomId}
+	if state.GamePhase != "FINISHED" {
+		fmt.Printf("Ignoring gameFinished from %s: room %s is still %s\n", finishRequest.UserId, finishRequest.RoomId, state.GamePhase)
+	} else {
+		if finishRequest.Winner != "" && finishRequest.Winner != state.Winner {
+			fmt.Printf("Client-reported winner mismatch in room %s: client=%s, server=%s\n", finishRequest.RoomId, finishRequest.Winner, state.Winner)
+		}
+		if finishRequest.GameData != nil {
+			checkClientGameData(dynamo, tableName, finishRequest.RoomId, *finishRequest.GameData)
+		}
+
+		// Normally the final move has already finalized, archived and rated the room. If that transaction failed,
+		// nothing was written and this call runs it again
+		ack.Winner = state.Winner
+		ack.FinalizationToken = turnKey(state.TurnNumber)
+		ack.Status = "alreadyFinished"
+		players, err := getRoomPlayers(dynamo, tableName, finishRequest.RoomId)
+		if err != nil {
+			fmt.Printf("Error getting room players: %v\n", err)
+			return events.APIGatewayProxyResponse{StatusCode: 500}, err
+		}
+		if finishGame(dynamo, apiGW, tableName, finishRequest.RoomId, ack.FinalizationToken, state.Winner, players) {
+			ack.Status = "finished"
+		}
+	}
 
-	// Get players for broadcast
-	players, err := getRoomPlayers(dynamo, tableName, finishRequest.RoomId)
+	if err := sendMessage(apiGW, connectionId, ack); err != nil {
+		fmt.Printf("Error sending gameFinishedAck to %s: %v\n", connectionId, err)
+	}
+	return events.APIGatewayProxyResponse{StatusCode: 200}, nil
+}
+
+// finishGame finalizes the room, archives the game and updates both players' ratings in one transaction,
+// then broadcasts the server-computed winner and the rating changes.
+// Everything is committed together, so a failed or interrupted attempt leaves the room unfinalized and unrated,
+// and the next call (the client's gameFinished) runs it again; once committed, later calls change nothing.
+// It reports whether this call committed the finalization.
+// The final piecePlaced has already been saved and sent, so failures here are only logged.
+func finishGame(dynamo dynamodbiface.DynamoDBAPI, apiGW apigatewaymanagementapiiface.ApiGatewayManagementApiAPI, tableName, roomId, token, winner string, players []Player) bool {
+	changes, committed, err := finalizeRoom(dynamo, tableName, roomId, token, winner)
 	if err != nil {
-		fmt.Printf("Error getting room players: %v\n", err)
-		return events.APIGatewayProxyResponse{StatusCode: 500}, err
+		fmt.Printf("Error finalizing room: %v\n", err)
+		return false
+	}
+	if !committed {
+		fmt.Printf("Room %s is already finalized, archived and rated\n", roomId)
+		return false
 	}
 
-	// Broadcast game finished event to all players
 	response := map[string]interface{}{
 		"type":   "gameFinished",
-		"roomId": finishRequest.RoomId,
-		"winner": finishRequest.Winner,
+		"roomId": roomId,
+		"winner": winner,
+	}
+	if changes != nil {
+		response["ratingChanges"] = changes
 	}
-
 	for _, player := range players {
-		err = sendMessage(apiGW, player.ConnectionId, response)
-		if err != nil {
+		if err := sendMessage(apiGW, player.ConnectionId, response); err != nil {
 			fmt.Printf("Error sending message to player %s: %v\n", player.UserId, err)
 		}
 	}
 
-	// Archive the game data
-	if err := archiveGameData(dynamo, finishRequest); err != nil {
-		fmt.Printf("Error archiving game data: %v\n", err)
-		// Don't fail the response, just log the error
+	fmt.Printf("Game finished: Room %s, Winner: %s\n", roomId, winner)
+	return true
+}
+
+// checkClientGameData compares the game record sent by a client with the server's move history and logs any difference.
+// The client's record is never stored.
+func checkClientGameData(dynamo dynamodbiface.DynamoDBAPI, tableName, roomId string, gameData GameData) {
+	clientText := gameData.GameText
+	if clientText == "" {
+		clientText = convertGameDataToText(gameData)
 	}
 
-	fmt.Printf("Game finished: Room %s, Winner: %s\n", finishRequest.RoomId, finishRequest.Winner)
-	return events.APIGatewayProxyResponse{StatusCode: 200}, nil
+	serverText, err := collectGameMoveHistory(dynamo, tableName, roomId)
+	if err != nil {
+		fmt.Printf("Error collecting move history for consistency check: %v\n", err)
+		return
+	}
+
+	if strings.TrimSpace(clientText) != strings.TrimSpace(serverText) {
+		fmt.Printf("Client game data mismatch in room %s: client=%q, server=%q\n", roomId, clientText, serverText)
+	}
 }
 
-func getCurrentGameState(dynamo *dynamodb.DynamoDB, tableName, roomId string) (*GameState, error) {
+func getCurrentGameState(dynamo dynamodbiface.DynamoDBAPI, tableName, roomId string) (*GameState, error) {
 	// Query for the latest game state (highest turn number)
 	input := &dynamodb.QueryInput{
 		TableName:              aws.String(tableName),
@@ -386,19 +608,35 @@ func getCurrentGameState(dynamo *dynamodb.DynamoDB, tableName, roomId string) (*
 		gameState.Winner = *winnerVal.S
 	}
 
-	// Board state is now managed by clients - no longer stored server-side
+	board, err := boardFromItem(item)
+	if err != nil {
+		return nil, err
+	}
+	if board == nil {
+		// Turns saved before the board was stored: rebuild it from the move history
+		moves, err := loadMoves(dynamo, tableName, roomId)
+		if err != nil {
+			return nil, err
+		}
+		replayed, err := rules.Replay(moves)
+		if err != nil {
+			return nil, fmt.Errorf("failed to replay move history: %v", err)
+		}
+		board = &replayed
+	}
+	gameState.Board = *board
 
 	return gameState, nil
 }
 
-func createInitialGameState(dynamo *dynamodb.DynamoDB, tableName, roomId string) (*GameState, error) {
+func createInitialGameState(dynamo dynamodbiface.DynamoDBAPI, tableName, roomId string) (*GameState, error) {
 	// Get room info to determine first player
 	room, err := getRoomInfo(dynamo, tableName, roomId)
 	if err != nil {
 		return nil, err
 	}
 
-	// Initial game state without board (clients handle initial board setup)
+	// Initial game state with the initial board
 	initialColor := generateNextColorForPlayer(true) // Player1 range
 	gameState := &GameState{
 		RoomId:        roomId,
@@ -406,10 +644,15 @@ func createInitialGameState(dynamo *dynamodb.DynamoDB, tableName, roomId string)
 		CurrentPlayer: room.Player1Id, // Player1 always goes first
 		NextColor:     initialColor,
 		GamePhase:     "PLAYING",
+		Board:         rules.NewBoard(),
 	}
 
 	// Save initial state
 	err = saveGameState(dynamo, tableName, *gameState)
+	if errors.Is(err, errStaleState) {
+		// Another invocation created the initial state first; use that one
+		return getCurrentGameState(dynamo, tableName, roomId)
+	}
 	if err != nil {
 		return nil, err
 	}
@@ -417,7 +660,7 @@ func createInitialGameState(dynamo *dynamodb.DynamoDB, tableName, roomId string)
 	return gameState, nil
 }
 
-func getRoomInfo(dynamo *dynamodb.DynamoDB, tableName, roomId string) (*GameRoom, error) {
+func getRoomInfo(dynamo dynamodbiface.DynamoDBAPI, tableName, roomId string) (*GameRoom, error) {
 	input := &dynamodb.GetItemInput{
 		TableName: aws.String(tableName),
 		Key: map[string]*dynamodb.AttributeValue{
@@ -453,11 +696,14 @@ func getRoomInfo(dynamo *dynamodb.DynamoDB, tableName, roomId string) (*GameRoom
 			room.PlayerCount = count
 		}
 	}
+	if tokenVal, ok := result.Item["finalizationToken"]; ok && tokenVal.S != nil {
+		room.FinalizationToken = *tokenVal.S
+	}
 
 	return room, nil
 }
 
-func getRoomPlayers(dynamo *dynamodb.DynamoDB, tableName, roomId string) ([]Player, error) {
+func getRoomPlayers(dynamo dynamodbiface.DynamoDBAPI, tableName, roomId string) ([]Player, error) {
 	input := &dynamodb.QueryInput{
 		TableName:              aws.String(tableName),
 		KeyConditionExpression: aws.String("PK = :pk AND begins_with(SK, :sk)"),
@@ -490,8 +736,8 @@ func getRoomPlayers(dynamo *dynamodb.DynamoDB, tableName, roomId string) ([]Play
 	return players, nil
 }
 
-// Board validation and game logic moved to client-side
-// Server now only handles turn management and basic position validation
+// Board rules (legality, color mixing, winner) live in the shared rules package.
+// The server keeps the authoritative board and clients render what it broadcasts.
 
 // generateNextColorForPlayer returns a color in the specified player's range.
 // PLAYER1: 0-128, PLAYER2: 129-255
@@ -503,18 +749,18 @@ func generateNextColorForPlayer(isPlayer1 bool) int {
 	return 129 + r.Intn(127) // 129-255 inclusive (129..255 -> 127 values)
 }
 
-func saveGameState(dynamo *dynamodb.DynamoDB, tableName string, gameState GameState) error {
+func saveGameState(dynamo dynamodbiface.DynamoDBAPI, tableName string, gameState GameState) error {
 	now := time.Now().Format(time.RFC3339)
 
-	// Simplified game state without board data
 	item := map[string]*dynamodb.AttributeValue{
 		"PK":            {S: aws.String(fmt.Sprintf("ROOM#%s", gameState.RoomId))},
-		"SK":            {S: aws.String(fmt.Sprintf("TURN#%06d", gameState.TurnNumber))},
+		"SK":            {S: aws.String(turnKey(gameState.TurnNumber))},
 		"roomId":        {S: aws.String(gameState.RoomId)},
 		"turnNumber":    {N: aws.String(strconv.Itoa(gameState.TurnNumber))},
 		"currentPlayer": {S: aws.String(gameState.CurrentPlayer)},
 		"nextColor":     {N: aws.String(strconv.Itoa(gameState.NextColor))},
 		"gamePhase":     {S: aws.String(gameState.GamePhase)},
+		"board":         {S: aws.String(encodeBoard(gameState.Board))},
 		"createdAt":     {S: aws.String(now)},
 	}
 
@@ -522,27 +768,22 @@ func saveGameState(dynamo *dynamodb.DynamoDB, tableName string, gameState GameSt
 		item["winner"] = &dynamodb.AttributeValue{S: aws.String(gameState.Winner)}
 	}
 
-	input := &dynamodb.PutItemInput{
-		TableName: aws.String(tableName),
-		Item:      item,
-	}
-
-	_, err := dynamo.PutItem(input)
-	return err
+	return putTurn(dynamo, tableName, item)
 }
 
-func saveGameStateWithMove(dynamo *dynamodb.DynamoDB, tableName string, gameState GameState, moveRow, moveCol, moveColor int) error {
+func saveGameStateWithMove(dynamo dynamodbiface.DynamoDBAPI, tableName string, gameState GameState, moveRow, moveCol, moveColor int) error {
 	now := time.Now().Format(time.RFC3339)
 
 	// Game state with move details
 	item := map[string]*dynamodb.AttributeValue{
 		"PK":            {S: aws.String(fmt.Sprintf("ROOM#%s", gameState.RoomId))},
-		"SK":            {S: aws.String(fmt.Sprintf("TURN#%06d", gameState.TurnNumber))},
+		"SK":            {S: aws.String(turnKey(gameState.TurnNumber))},
 		"roomId":        {S: aws.String(gameState.RoomId)},
 		"turnNumber":    {N: aws.String(strconv.Itoa(gameState.TurnNumber))},
 		"currentPlayer": {S: aws.String(gameState.CurrentPlayer)},
 		"nextColor":     {N: aws.String(strconv.Itoa(gameState.NextColor))},
 		"gamePhase":     {S: aws.String(gameState.GamePhase)},
+		"board":         {S: aws.String(encodeBoard(gameState.Board))},
 		"createdAt":     {S: aws.String(now)},
 		// Move details
 		"moveRow":   {N: aws.String(strconv.Itoa(moveRow))},
@@ -554,16 +795,34 @@ func saveGameStateWithMove(dynamo *dynamodb.DynamoDB, tableName string, gameStat
 		item["winner"] = &dynamodb.AttributeValue{S: aws.String(gameState.Winner)}
 	}
 
-	input := &dynamodb.PutItemInput{
-		TableName: aws.String(tableName),
-		Item:      item,
+	return putTurn(dynamo, tableName, item)
+}
+
+// encodeBoard serializes the board as the JSON of rules.Board.Cells
+func encodeBoard(board rules.Board) string {
+	data, _ := json.Marshal(board.Cells())
+	return string(data)
+}
+
+// boardFromItem reads the board stored with a TURN# item. It returns nil if the item has no board.
+func boardFromItem(item map[string]*dynamodb.AttributeValue) (*rules.Board, error) {
+	v, ok := item["board"]
+	if !ok || v.S == nil {
+		return nil, nil
 	}
 
-	_, err := dynamo.PutItem(input)
-	return err
+	var cells [][]int
+	if err := json.Unmarshal([]byte(*v.S), &cells); err != nil {
+		return nil, fmt.Errorf("invalid board: %v", err)
+	}
+	board, err := rules.FromCells(cells)
+	if err != nil {
+		return nil, fmt.Errorf("invalid board: %v", err)
+	}
+	return &board, nil
 }
 
-func sendMessage(apiGW *apigatewaymanagementapi.ApiGatewayManagementApi, connectionId string, message interface{}) error {
+func sendMessage(apiGW apigatewaymanagementapiiface.ApiGatewayManagementApiAPI, connectionId string, message interface{}) error {
 	data, err := json.Marshal(message)
 	if err != nil {
 		return err
@@ -578,135 +837,285 @@ func sendMessage(apiGW *apigatewaymanagementapi.ApiGatewayManagementApi, connect
 	return err
 }
 
-func updateRoomStatus(dynamo *dynamodb.DynamoDB, tableName, roomId, status string) error {
-	now := time.Now().Format(time.RFC3339)
-
-	// Update room metadata status
-	input := &dynamodb.UpdateItemInput{
-		TableName: aws.String(tableName),
-		Key: map[string]*dynamodb.AttributeValue{
-			"PK": {S: aws.String(fmt.Sprintf("ROOM#%s", roomId))},
-			"SK": {S: aws.String("METADATA")},
-		},
-		UpdateExpression: aws.String("SET #status = :status, updatedAt = :updatedAt"),
-		ExpressionAttributeNames: map[string]*string{
-			"#status": aws.String("status"),
-		},
-		ExpressionAttributeValues: map[string]*dynamodb.AttributeValue{
-			":status":    {S: aws.String(status)},
-			":updatedAt": {S: aws.String(now)},
-		},
-	}
-
-	_, err := dynamo.UpdateItem(input)
-	return err
-}
-
-func archiveGameData(dynamo *dynamodb.DynamoDB, gameFinished GameFinishedRequest) error {
-	// Get room information
-	roomData, err := getRoomData(dynamo, gameFinished.RoomId)
+// finalizeRoom sets the room to FINISHED together with the finalization token and the winner, archives the game
+// and writes both players' new ratings, in a single transaction. Each rating item carries a version, so a player
+// finishing two games at once never loses an update; on a conflict the ratings are re-read and the transaction retried.
+// It returns committed == false without error if the room has already been finalized.
+func finalizeRoom(dynamo dynamodbiface.DynamoDBAPI, tableName, roomId, token, winner string) (changes []RatingChange, committed bool, err error) {
+	room, err := getRoomInfo(dynamo, tableName, roomId)
 	if err != nil {
-		return fmt.Errorf("failed to get room data: %v", err)
+		return nil, false, fmt.Errorf("failed to get room data: %v", err)
+	}
+	if room.FinalizationToken != "" {
+		// Already finalized: skip building the archive and ratings; the transaction condition still guards the race
+		return nil, false, nil
 	}
+	archive, err := archiveItem(dynamo, tableName, roomId, room, winner)
+	if err != nil {
+		return nil, false, err
+	}
+	rated := room.Player1Id != "" && room.Player2Id != ""
+	if !rated {
+		fmt.Printf("Room %s does not have two players; finalizing without ratings\n", roomId)
+	}
+	outcome := player1Outcome(winner)
+
+	for attempt := 1; attempt <= maxRatingAttempts; attempt++ {
+		now := time.Now().Format(time.RFC3339)
+		items := []*dynamodb.TransactWriteItem{
+			{
+				Update: &dynamodb.Update{
+					TableName: aws.String(tableName),
+					Key: map[string]*dynamodb.AttributeValue{
+						"PK": {S: aws.String(fmt.Sprintf("ROOM#%s", roomId))},
+						"SK": {S: aws.String("METADATA")},
+					},
+					// GSI1 lets cleanup_handler find finished rooms
+					UpdateExpression:    aws.String("SET #status = :status, finalizationToken = :token, winner = :winner, updatedAt = :updatedAt, GSI1PK = :gsi1pk, GSI1SK = :updatedAt"),
+					ConditionExpression: aws.String("attribute_exists(PK) AND attribute_not_exists(finalizationToken)"),
+					ExpressionAttributeNames: map[string]*string{
+						"#status": aws.String("status"),
+					},
+					ExpressionAttributeValues: map[string]*dynamodb.AttributeValue{
+						":status":    {S: aws.String("FINISHED")},
+						":token":     {S: aws.String(token)},
+						":winner":    {S: aws.String(winner)},
+						":updatedAt": {S: aws.String(now)},
+						":gsi1pk":    {S: aws.String("ROOM_STATUS#FINISHED")},
+					},
+				},
+			},
+			{
+				Put: &dynamodb.Put{
+					TableName: aws.String(archiveTableName),
+					Item:      archive,
+					// Never overwrite an archive that has already been written for this game
+					ConditionExpression: aws.String("attribute_not_exists(PK)"),
+				},
+			},
+		}
 
-	var gameDataText string
+		changes = nil
+		if rated {
+			p1, err := loadPlayerRating(dynamo, tableName, room.Player1Id)
+			if err != nil {
+				return nil, false, err
+			}
+			p2, err := loadPlayerRating(dynamo, tableName, room.Player2Id)
+			if err != nil {
+				return nil, false, err
+			}
 
-	// Use client-provided game text if available (最優先)
-	if gameFinished.GameData != nil && gameFinished.GameData.GameText != "" {
-		fmt.Printf("Using client-provided game text data: %q\n", gameFinished.GameData.GameText)
-		gameDataText = gameFinished.GameData.GameText
-	} else if gameFinished.GameData != nil && len(gameFinished.GameData.Moves) > 0 {
-		// フォールバック1: Movesデータから変換
-		fmt.Printf("Using client-provided game data with %d moves\n", len(gameFinished.GameData.Moves))
-		gameDataText = convertGameDataToText(*gameFinished.GameData)
-	} else {
-		// フォールバック2: サーバー側履歴収集
-		fmt.Printf("Falling back to server-side move history collection\n")
-		var err error
-		gameDataText, err = collectGameMoveHistory(dynamo, gameFinished.RoomId)
+			after1, after2 := rating.Update(p1.Rating, p2.Rating, outcome)
+			changes = []RatingChange{
+				{UserId: p1.UserId, Before: p1.Rating, After: after1, Delta: after1 - p1.Rating},
+				{UserId: p2.UserId, Before: p2.Rating, After: after2, Delta: after2 - p2.Rating},
+			}
+			items = append(items, ratingPut(tableName, p1, after1, outcome), ratingPut(tableName, p2, after2, 1-outcome))
+		}
+
+		_, err = dynamo.TransactWriteItems(&dynamodb.TransactWriteItemsInput{TransactItems: items})
+		if conditionFailedAt(err, 0) {
+			return nil, false, nil
+		}
+		if rated && (conditionFailedAt(err, 2) || conditionFailedAt(err, 3) || isTransactionConflict(err)) {
+			fmt.Printf("Rating update for room %s lost a race (attempt %d/%d): %v\n", roomId, attempt, maxRatingAttempts, err)
+			continue
+		}
 		if err != nil {
-			return fmt.Errorf("failed to collect game move history: %v", err)
+			return nil, false, err
 		}
+
+		if rated {
+			fmt.Printf("Ratings updated for room %s: %s %.0f -> %.0f, %s %.0f -> %.0f\n",
+				roomId, changes[0].UserId, changes[0].Before, changes[0].After, changes[1].UserId, changes[1].Before, changes[1].After)
+		}
+		fmt.Printf("Finalized and archived game %s\n", roomId)
+		return changes, true, nil
 	}
+	// Nothing has been written, so the room is still unfinalized and the next gameFinished tries again
+	return nil, false, errRatingConflict
+}
 
-	// Convert game data to whitespace format
-	fmt.Printf("Original game data text: %q\n", gameDataText)
-	whitespaceData, err := convertToWhitespace(gameDataText)
-	if err != nil {
-		return fmt.Errorf("failed to convert to whitespace format: %v", err)
+// player1Outcome returns the result for PLAYER1, who places the dark colors (0-128): a black result is a win
+func player1Outcome(winner string) rating.Outcome {
+	switch winner {
+	case rules.Black.String():
+		return rating.Win
+	case rules.White.String():
+		return rating.Loss
 	}
-	fmt.Printf("Successfully converted game data to whitespace format\n")
+	return rating.Draw
+}
 
-	// Create archive entry
-	archiveTableName := "game-archive"
-	gameId := gameFinished.RoomId // Using roomId as gameId for now
-	now := time.Now().UTC().Format(time.RFC3339)
-	ttl := time.Now().UTC().Add(30 * 24 * time.Hour).Unix() // 30 days TTL
+// putTurn writes a TURN# item only if that turn has not been saved yet.
+// Turn numbers are consecutive, so this is an optimistic lock on the game state read before the move.
+func putTurn(dynamo dynamodbiface.DynamoDBAPI, tableName string, item map[string]*dynamodb.AttributeValue) error {
+	input := &dynamodb.PutItemInput{
+		TableName:           aws.String(tableName),
+		Item:                item,
+		ConditionExpression: aws.String("attribute_not_exists(PK)"),
+	}
 
-	archiveItem := map[string]*dynamodb.AttributeValue{
-		"PK":        {S: aws.String(fmt.Sprintf("GAME#%s", gameId))},
-		"SK":        {S: aws.String("ARCHIVE")},
-		"gameId":    {S: aws.String(gameId)},
-		"roomId":    {S: aws.String(gameFinished.RoomId)},
-		"player1Id": {S: aws.String(roomData.Player1Id)},
-		"player2Id": {S: aws.String(roomData.Player2Id)},
-		"winner":    {S: aws.String(gameFinished.Winner)},
-		"gamePhase": {S: aws.String("FINISHED")},
-		"endTime":   {S: aws.String(now)},
-		"gameData":  {S: aws.String(whitespaceData)}, // Whitespace形式で保存
-		"ttl":       {N: aws.String(fmt.Sprintf("%d", ttl))},
+	_, err := dynamo.PutItem(input)
+	if isConditionalCheckFailed(err) {
+		return errStaleState
 	}
+	return err
+}
 
-	putInput := &dynamodb.PutItemInput{
-		TableName: aws.String(archiveTableName),
-		Item:      archiveItem,
+// isConditionalCheckFailed reports whether err is DynamoDB rejecting a conditional write
+func isConditionalCheckFailed(err error) bool {
+	var aerr awserr.Error
+	return errors.As(err, &aerr) && aerr.Code() == dynamodb.ErrCodeConditionalCheckFailedException
+}
+
+// conditionFailedAt reports whether err is a cancelled transaction whose item at index failed its condition
+func conditionFailedAt(err error, index int) bool {
+	var canceled *dynamodb.TransactionCanceledException
+	if !errors.As(err, &canceled) || index >= len(canceled.CancellationReasons) {
+		return false
 	}
+	return aws.StringValue(canceled.CancellationReasons[index].Code) == "ConditionalCheckFailed"
+}
 
-	_, err = dynamo.PutItem(putInput)
-	if err != nil {
-		return fmt.Errorf("failed to archive game data: %v", err)
+// isTransactionConflict reports whether err is a transaction cancelled because another one was updating the same items
+func isTransactionConflict(err error) bool {
+	var canceled *dynamodb.TransactionCanceledException
+	if !errors.As(err, &canceled) {
+		return false
 	}
+	for _, reason := range canceled.CancellationReasons {
+		if aws.StringValue(reason.Code) == "TransactionConflict" {
+			return true
+		}
+	}
+	return false
+}
 
-	fmt.Printf("Successfully archived game %s\n", gameId)
-	return nil
+// turnKey returns the sort key of the TURN# item for the given turn number
+func turnKey(turnNumber int) string {
+	return fmt.Sprintf("TURN#%06d", turnNumber)
 }
 
-func getRoomData(dynamo *dynamodb.DynamoDB, roomId string) (*GameRoom, error) {
-	getInput := &dynamodb.GetItemInput{
-		TableName: aws.String(os.Getenv("DYNAMODB_TABLE_NAME")),
+// loadPlayerRating reads a player's rating item; a player without one starts at the initial rating
+func loadPlayerRating(dynamo dynamodbiface.DynamoDBAPI, tableName, userId string) (PlayerRating, error) {
+	result, err := dynamo.GetItem(&dynamodb.GetItemInput{
+		TableName: aws.String(tableName),
 		Key: map[string]*dynamodb.AttributeValue{
-			"PK": {S: aws.String(fmt.Sprintf("ROOM#%s", roomId))},
-			"SK": {S: aws.String("METADATA")},
+			"PK": {S: aws.String(fmt.Sprintf("PLAYER#%s", userId))},
+			"SK": {S: aws.String("RATING")},
 		},
+		ConsistentRead: aws.Bool(true),
+	})
+	if err != nil {
+		return PlayerRating{}, err
 	}
 
-	result, err := dynamo.GetItem(getInput)
-	if err != nil {
-		return nil, err
+	p := PlayerRating{UserId: userId, Rating: rating.Initial}
+	if v, ok := result.Item["rating"]; ok && v.N != nil {
+		if r, err := strconv.ParseFloat(*v.N, 64); err == nil {
+			p.Rating = r
+		}
 	}
+	p.Games, _ = numberAttribute(result.Item, "games")
+	p.Wins, _ = numberAttribute(result.Item, "wins")
+	p.Losses, _ = numberAttribute(result.Item, "losses")
+	p.Draws, _ = numberAttribute(result.Item, "draws")
+	p.Version, _ = numberAttribute(result.Item, "version")
+	return p, nil
+}
 
-	if result.Item == nil {
-		return nil, fmt.Errorf("room not found")
+// ratingPut builds the conditional write of a player's new rating.
+// It only succeeds if the item is still at the version that was read.
+func ratingPut(tableName string, p PlayerRating, newRating float64, outcome rating.Outcome) *dynamodb.TransactWriteItem {
+	switch outcome {
+	case rating.Win:
+		p.Wins++
+	case rating.Loss:
+		p.Losses++
+	default:
+		p.Draws++
+	}
+
+	condition := "attribute_not_exists(PK)"
+	var values map[string]*dynamodb.AttributeValue
+	if p.Version > 0 {
+		condition = "version = :version"
+		values = map[string]*dynamodb.AttributeValue{
+			":version": {N: aws.String(strconv.Itoa(p.Version))},
+		}
 	}
 
-	room := &GameRoom{
-		RoomId: roomId,
+	return &dynamodb.TransactWriteItem{
+		Put: &dynamodb.Put{
+			TableName: aws.String(tableName),
+			Item: map[string]*dynamodb.AttributeValue{
+				"PK":        {S: aws.String(fmt.Sprintf("PLAYER#%s", p.UserId))},
+				"SK":        {S: aws.String("RATING")},
+				"userId":    {S: aws.String(p.UserId)},
+				"rating":    {N: aws.String(strconv.FormatFloat(newRating, 'f', -1, 64))},
+				"games":     {N: aws.String(strconv.Itoa(p.Games + 1))},
+				"wins":      {N: aws.String(strconv.Itoa(p.Wins))},
+				"losses":    {N: aws.String(strconv.Itoa(p.Losses))},
+				"draws":     {N: aws.String(strconv.Itoa(p.Draws))},
+				"version":   {N: aws.String(strconv.Itoa(p.Version + 1))},
+				"updatedAt": {S: aws.String(time.Now().Format(time.RFC3339))},
+				// GSI1 sorted by rating backs GET /leaderboard in game_replay_handler
+				"GSI1PK": {S: aws.String("LEADERBOARD")},
+				"GSI1SK": {S: aws.String(leaderboardKey(newRating, p.UserId))},
+			},
+			ConditionExpression:       aws.String(condition),
+			ExpressionAttributeValues: values,
+		},
 	}
+}
+
+// leaderboardKey returns a GSI1SK that sorts lexicographically in rating order
+func leaderboardKey(r float64, userId string) string {
+	return fmt.Sprintf("%06d#%s", int(math.Max(0, math.Round(r))), userId)
+}
+
+// archiveTableName is the table that game_replay_handler reads archived games from
+const archiveTableName = "game-archive"
 
-	if player1Id, ok := result.Item["player1Id"]; ok && player1Id.S != nil {
-		room.Player1Id = *player1Id.S
+// archiveItem builds the archive of the game from the server's move history, never from client data
+func archiveItem(dynamo dynamodbiface.DynamoDBAPI, tableName, roomId string, room *GameRoom, winner string) (map[string]*dynamodb.AttributeValue, error) {
+	gameDataText, err := collectGameMoveHistory(dynamo, tableName, roomId)
+	if err != nil {
+		return nil, fmt.Errorf("failed to collect game move history: %v", err)
 	}
-	if player2Id, ok := result.Item["player2Id"]; ok && player2Id.S != nil {
-		room.Player2Id = *player2Id.S
+
+	// Convert game data to whitespace format
+	fmt.Printf("Original game data text: %q\n", gameDataText)
+	whitespaceData, err := convertToWhitespace(gameDataText)
+	if err != nil {
+		return nil, fmt.Errorf("failed to convert to whitespace format: %v", err)
 	}
 
-	return room, nil
+	gameId := roomId // Using roomId as gameId for now
+	now := time.Now().UTC().Format(time.RFC3339)
+	ttl := time.Now().UTC().Add(30 * 24 * time.Hour).Unix() // 30 days TTL
+
+	return map[string]*dynamodb.AttributeValue{
+		"PK":        {S: aws.String(fmt.Sprintf("GAME#%s", gameId))},
+		"SK":        {S: aws.String("ARCHIVE")},
+		"gameId":    {S: aws.String(gameId)},
+		"roomId":    {S: aws.String(roomId)},
+		"player1Id": {S: aws.String(room.Player1Id)},
+		"player2Id": {S: aws.String(room.Player2Id)},
+		"winner":    {S: aws.String(winner)},
+		"gamePhase": {S: aws.String("FINISHED")},
+		"endTime":   {S: aws.String(now)},
+		"gameData":  {S: aws.String(whitespaceData)}, // Whitespace形式で保存
+		"ttl":       {N: aws.String(fmt.Sprintf("%d", ttl))},
+	}, nil
 }
 
-func collectGameMoveHistory(dynamo *dynamodb.DynamoDB, roomId string) (string, error) {
-	// Query all turn records for this room to collect move history
+// loadMoves returns the moves recorded in the room's turn history in chronological order
+func loadMoves(dynamo dynamodbiface.DynamoDBAPI, tableName, roomId string) ([]rules.Move, error) {
 	queryInput := &dynamodb.QueryInput{
-		TableName:              aws.String(os.Getenv("DYNAMODB_TABLE_NAME")),
+		TableName:              aws.String(tableName),
 		KeyConditionExpression: aws.String("PK = :pk AND begins_with(SK, :sk)"),
 		ExpressionAttributeValues: map[string]*dynamodb.AttributeValue{
 			":pk": {S: aws.String(fmt.Sprintf("ROOM#%s", roomId))},
@@ -717,34 +1126,54 @@ func collectGameMoveHistory(dynamo *dynamodb.DynamoDB, roomId string) (string, e
 
 	result, err := dynamo.Query(queryInput)
 	if err != nil {
-		return "", fmt.Errorf("failed to query game turns: %v", err)
+		return nil, fmt.Errorf("failed to query game turns: %v", err)
 	}
 
-	var gameDataLines []string
+	var moves []rules.Move
 	for _, item := range result.Items {
-		// Extract move details from each turn
-		if moveRow, ok := item["moveRow"]; ok && moveRow.N != nil {
-			if moveCol, ok := item["moveCol"]; ok && moveCol.N != nil {
-				if moveColor, ok := item["moveColor"]; ok && moveColor.N != nil {
-					// Format: "row col color"
-					line := fmt.Sprintf("%s %s %s", *moveRow.N, *moveCol.N, *moveColor.N)
-					gameDataLines = append(gameDataLines, line)
-				}
-			}
+		// The initial turn has no move details
+		row, okRow := numberAttribute(item, "moveRow")
+		col, okCol := numberAttribute(item, "moveCol")
+		color, okColor := numberAttribute(item, "moveColor")
+		if okRow && okCol && okColor {
+			moves = append(moves, rules.Move{Row: row, Col: col, Color: uint8(color)})
 		}
 	}
 
+	return moves, nil
+}
+
+// numberAttribute reads a numeric attribute from a DynamoDB item
+func numberAttribute(item map[string]*dynamodb.AttributeValue, name string) (int, bool) {
+	v, ok := item[name]
+	if !ok || v.N == nil {
+		return 0, false
+	}
+	n, err := strconv.Atoi(*v.N)
+	if err != nil {
+		return 0, false
+	}
+	return n, true
+}
+
+func collectGameMoveHistory(dynamo dynamodbiface.DynamoDBAPI, tableName, roomId string) (string, error) {
+	moves, err := loadMoves(dynamo, tableName, roomId)
+	if err != nil {
+		return "", err
+	}
+
 	// Join all moves with newlines
-	if len(gameDataLines) == 0 {
+	if len(moves) == 0 {
 		return "0 0 0", nil // Fallback for empty games
 	}
 
-	gameDataText := fmt.Sprintf("%s\n", gameDataLines[0])
-	for i := 1; i < len(gameDataLines); i++ {
-		gameDataText += fmt.Sprintf("%s\n", gameDataLines[i])
+	var gameDataText strings.Builder
+	for _, move := range moves {
+		// Format: "row col color"
+		fmt.Fprintf(&gameDataText, "%d %d %d\n", move.Row, move.Col, move.Color)
 	}
 
-	return gameDataText, nil
+	return gameDataText.String(), nil
 }
 
 // convertGameDataToText はクライアントから送信されたGameDataをテキスト形式に変換する
@@ -762,84 +1191,29 @@ func convertGameDataToText(gameData GameData) string {
 	return fmt.Sprintf("%s\n", strings.Join(lines, "\n"))
 }
 
-// WhitespaceConvertRequest はWhitespace変換API呼び出し用のリクエスト構造体
-type WhitespaceConvertRequest struct {
-	CommandType string   `json:"command_type"`
-	Payload     []string `json:"payload"`
-}
-
-// WhitespaceConvertResponse はWhitespace変換API呼び出し用のレスポンス構造体
-type WhitespaceConvertResponse struct {
-	CommandType             string   `json:"command_type"`
-	ResultWhitespace        []string `json:"result_whitespace"`
-	ResultWhitespaceEncoded []string `json:"result_whitespace_percent_encoded"`
-}
-
 // convertToWhitespace は対局データテキストをWhitespace形式に変換する
+// 変換は Decode API と同じ規則（wscodec）でプロセス内で行う
 func convertToWhitespace(gameDataText string) (string, error) {
-	// 固定IPアドレスを使用（ECS Fargateへのアクセス）
-	apiURL := "http://18.181.38.132:3000"
-	fmt.Printf("Using fixed IP API URL: %s\n", apiURL)
-
-	// ゲームデータテキストを行ごとに分割して配列に変換
 	lines := strings.Split(strings.TrimSpace(gameDataText), "\n")
-	var validLines []string
-	for _, line := range lines {
+	var sentences []wscodec.Sentence
+	for i, line := range lines {
 		line = strings.TrimSpace(line)
-		if line != "" {
-			validLines = append(validLines, line)
+		if line == "" {
+			continue
 		}
-	}
-
-	// Decode APIの正しいリクエスト形式（配列形式）
-	reqBody := map[string]interface{}{
-		"command_type": "DecimalToWhitespace",
-		"payload":      validLines,
-	}
-	fmt.Printf("Request body: %+v\n", reqBody)
-
-	jsonData, err := json.Marshal(reqBody)
-	if err != nil {
-		return "", fmt.Errorf("failed to marshal request: %v", err)
-	}
-	fmt.Printf("JSON request data: %s\n", string(jsonData))
-
-	resp, err := http.Post(apiURL+"/v1/decode", "application/json", bytes.NewBuffer(jsonData))
-	if err != nil {
-		return "", fmt.Errorf("failed to call decode API: %v", err)
-	}
-	defer resp.Body.Close()
-
-	fmt.Printf("Response status code: %d\n", resp.StatusCode)
-	if resp.StatusCode != http.StatusOK {
-		// レスポンスボディを読んでエラー内容を確認
-		bodyBytes := make([]byte, 1024)
-		n, _ := resp.Body.Read(bodyBytes)
-		return "", fmt.Errorf("decode API returned status code: %d, body: %s", resp.StatusCode, string(bodyBytes[:n]))
-	}
-
-	// Decode APIレスポンス形式に合わせて解析
-	var apiResp map[string]interface{}
-	if err := json.NewDecoder(resp.Body).Decode(&apiResp); err != nil {
-		return "", fmt.Errorf("failed to decode response: %v", err)
-	}
-	fmt.Printf("Decode API response: %+v\n", apiResp)
-
-	// result_whitespaceフィールドから結果を取得（配列形式）
-	if resultArray, ok := apiResp["result_whitespace"].([]interface{}); ok && len(resultArray) > 0 {
-		// 配列の各要素を文字列として連結
-		var whitespaceLines []string
-		for _, item := range resultArray {
-			if line, ok := item.(string); ok {
-				whitespaceLines = append(whitespaceLines, line)
-			}
+		sentence, err := wscodec.ParseDecimal(line)
+		if err != nil {
+			return "", fmt.Errorf("line %d: %w", i+1, err)
 		}
-		result := strings.Join(whitespaceLines, "")
-		fmt.Printf("Converted whitespace data (length: %d)\n", len(result))
-		return result, nil
+		sentences = append(sentences, sentence)
+	}
+	if len(sentences) == 0 {
+		return "", fmt.Errorf("no moves to convert")
 	}
 
-	return "", fmt.Errorf("empty or invalid decode result, full response: %+v", apiResp)
+	result := wscodec.EncodeWhitespace(sentences)
+	fmt.Printf("Converted whitespace data (length: %d)\n", len(result))
+	return result, nil
 }
 
 func main() {
@@ -0,0 +1,380 @@
+package main
+
+import (
+	"errors"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/2509-hackz-ichthyo/main/api/pkg/rating"
+	"github.com/2509-hackz-ichthyo/main/api/pkg/rules"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbiface"
+)
+
+func TestApplyMove(t *testing.T) {
+	playing := GameState{
+		RoomId:        "room-1",
+		CurrentPlayer: "alice",
+		GamePhase:     "PLAYING",
+		Board:         rules.NewBoard(),
+	}
+	finished := playing
+	finished.GamePhase = "FINISHED"
+
+	cases := []struct {
+		name       string
+		state      GameState
+		userId     string
+		move       rules.Move
+		wantReason string
+	}{
+		{"legal move", playing, "alice", rules.Move{Row: 2, Col: 3, Color: 10}, ""},
+		{"wrong turn", playing, "bob", rules.Move{Row: 2, Col: 3, Color: 200}, "notYourTurn"},
+		{"out of range", playing, "alice", rules.Move{Row: 8, Col: 0, Color: 10}, "invalidPosition"},
+		{"occupied", playing, "alice", rules.Move{Row: 3, Col: 3, Color: 10}, "occupied"},
+		{"illegal move", playing, "alice", rules.Move{Row: 0, Col: 0, Color: 10}, "noFlank"},
+		{"game over", finished, "alice", rules.Move{Row: 2, Col: 3, Color: 10}, "gameOver"},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			board, reason, err := applyMove(tc.state, tc.userId, tc.move)
+			if reason != tc.wantReason {
+				t.Fatalf("applyMove() reason = %q, want %q (err %v)", reason, tc.wantReason, err)
+			}
+			if (err == nil) != (tc.wantReason == "") {
+				t.Fatalf("applyMove() err = %v, want error %v", err, tc.wantReason != "")
+			}
+
+			if tc.wantReason != "" {
+				if board != tc.state.Board {
+					t.Fatal("rejected move changed the board")
+				}
+				return
+			}
+			want, _ := rules.Apply(tc.state.Board, tc.move)
+			if board != want {
+				t.Fatal("accepted move did not apply the shared rules")
+			}
+		})
+	}
+}
+
+func TestGetCurrentGameState_ReplaysHistoryWithoutBoard(t *testing.T) {
+	f := newFakeDynamo()
+	// A turn saved before the board was stored only carries the move
+	newFinishedRoom(f)
+
+	state, err := getCurrentGameState(f, "game-service", "room-1")
+	if err != nil {
+		t.Fatalf("getCurrentGameState: %v", err)
+	}
+	want, _ := rules.Replay([]rules.Move{{Row: 2, Col: 3, Color: 10}})
+	if state.Board != want {
+		t.Fatal("board was not rebuilt from the TURN# history")
+	}
+
+	// Moves are then validated against the rebuilt board
+	state.CurrentPlayer = "bob"
+	if _, reason, _ := applyMove(*state, "bob", rules.Move{Row: 2, Col: 3, Color: 200}); reason != "occupied" {
+		t.Fatalf("move on a recorded square: reason = %q, want occupied", reason)
+	}
+}
+
+func TestAdvanceTurn(t *testing.T) {
+	t.Run("next player", func(t *testing.T) {
+		board := rules.NewBoard()
+		turn := rules.NextTurn(board, 200, 10)
+		if turn != rules.TurnNext {
+			t.Fatalf("NextTurn() on the initial board = %v, want next", turn)
+		}
+		got := advanceTurn(turn, board, "alice", "bob", 10, 200)
+		want := nextTurn{Player: "bob", Color: 200, GamePhase: "PLAYING"}
+		if got != want {
+			t.Fatalf("advanceTurn() = %+v, want %+v", got, want)
+		}
+	})
+
+	t.Run("game over", func(t *testing.T) {
+		// A full board leaves no legal move for either color; every piece is dark, so black wins
+		var board rules.Board
+		for row := range rules.Size {
+			for col := range rules.Size {
+				board[row][col] = rules.Square{Occupied: true, Color: 10}
+			}
+		}
+		turn := rules.NextTurn(board, 200, 10)
+		if turn != rules.TurnGameOver {
+			t.Fatalf("NextTurn() on a full board = %v, want gameOver", turn)
+		}
+		got := advanceTurn(turn, board, "alice", "bob", 10, 200)
+		if got.GamePhase != "FINISHED" || got.Winner != rules.Black.String() || got.PassedPlayer != "" {
+			t.Fatalf("advanceTurn() = %+v, want FINISHED won by %s", got, rules.Black)
+		}
+	})
+
+	t.Run("pass", func(t *testing.T) {
+		// rules.NextTurn cannot return TurnPass under the current rules, so the branch is driven directly
+		got := advanceTurn(rules.TurnPass, rules.NewBoard(), "alice", "bob", 10, 200)
+		want := nextTurn{Player: "alice", Color: 10, GamePhase: "PLAYING", PassedPlayer: "bob"}
+		if got != want {
+			t.Fatalf("advanceTurn() = %+v, want %+v", got, want)
+		}
+	})
+}
+
+// fakeDynamo is an in-memory stand-in for the DynamoDB calls this handler makes.
+// Methods it does not implement panic through the embedded nil interface.
+type fakeDynamo struct {
+	dynamodbiface.DynamoDBAPI
+
+	items        map[string]map[string]*dynamodb.AttributeValue // keyed by table, PK and SK
+	transactErrs []error                                        // returned in order by TransactWriteItems instead of committing
+	transactions []*dynamodb.TransactWriteItemsInput
+}
+
+func newFakeDynamo() *fakeDynamo {
+	return &fakeDynamo{items: map[string]map[string]*dynamodb.AttributeValue{}}
+}
+
+func itemKey(table string, item map[string]*dynamodb.AttributeValue) string {
+	return table + "|" + aws.StringValue(item["PK"].S) + "|" + aws.StringValue(item["SK"].S)
+}
+
+func (f *fakeDynamo) put(table string, item map[string]*dynamodb.AttributeValue) {
+	f.items[itemKey(table, item)] = item
+}
+
+func (f *fakeDynamo) GetItem(input *dynamodb.GetItemInput) (*dynamodb.GetItemOutput, error) {
+	return &dynamodb.GetItemOutput{Item: f.items[itemKey(aws.StringValue(input.TableName), input.Key)]}, nil
+}
+
+// Query supports the "PK = :pk AND begins_with(SK, :sk)" queries used for turns and players,
+// honouring ScanIndexForward and Limit
+func (f *fakeDynamo) Query(input *dynamodb.QueryInput) (*dynamodb.QueryOutput, error) {
+	prefix := aws.StringValue(input.TableName) + "|" + aws.StringValue(input.ExpressionAttributeValues[":pk"].S) + "|"
+	if sk, ok := input.ExpressionAttributeValues[":sk"]; ok {
+		prefix += aws.StringValue(sk.S)
+	}
+	var keys []string
+	for key := range f.items {
+		if strings.HasPrefix(key, prefix) {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+	if input.ScanIndexForward != nil && !*input.ScanIndexForward {
+		sort.Sort(sort.Reverse(sort.StringSlice(keys)))
+	}
+	if input.Limit != nil && int64(len(keys)) > *input.Limit {
+		keys = keys[:*input.Limit]
+	}
+
+	output := &dynamodb.QueryOutput{}
+	for _, key := range keys {
+		output.Items = append(output.Items, f.items[key])
+	}
+	return output, nil
+}
+
+// PutItem honours the attribute_not_exists(PK) condition used for turns
+func (f *fakeDynamo) PutItem(input *dynamodb.PutItemInput) (*dynamodb.PutItemOutput, error) {
+	table := aws.StringValue(input.TableName)
+	if aws.StringValue(input.ConditionExpression) == "attribute_not_exists(PK)" {
+		if _, exists := f.items[itemKey(table, input.Item)]; exists {
+			return nil, awserr.New(dynamodb.ErrCodeConditionalCheckFailedException, "The conditional request failed", nil)
+		}
+	}
+	f.put(table, input.Item)
+	return &dynamodb.PutItemOutput{}, nil
+}
+
+// TransactWriteItems records the transaction and commits its Puts unless an error is queued in transactErrs
+func (f *fakeDynamo) TransactWriteItems(input *dynamodb.TransactWriteItemsInput) (*dynamodb.TransactWriteItemsOutput, error) {
+	f.transactions = append(f.transactions, input)
+	if len(f.transactErrs) > 0 {
+		err := f.transactErrs[0]
+		f.transactErrs = f.transactErrs[1:]
+		return nil, err
+	}
+	for _, item := range input.TransactItems {
+		if item.Put != nil {
+			f.put(aws.StringValue(item.Put.TableName), item.Put.Item)
+		}
+	}
+	return &dynamodb.TransactWriteItemsOutput{}, nil
+}
+
+// canceled builds the error DynamoDB returns when a transaction is cancelled, with one code per item
+func canceled(codes ...string) error {
+	reasons := make([]*dynamodb.CancellationReason, len(codes))
+	for i, code := range codes {
+		reasons[i] = &dynamodb.CancellationReason{Code: aws.String(code)}
+	}
+	return &dynamodb.TransactionCanceledException{CancellationReasons: reasons}
+}
+
+// newFinishedRoom stores a two-player room with one recorded move
+func newFinishedRoom(f *fakeDynamo) {
+	f.put("game-service", map[string]*dynamodb.AttributeValue{
+		"PK":        {S: aws.String("ROOM#room-1")},
+		"SK":        {S: aws.String("METADATA")},
+		"roomId":    {S: aws.String("room-1")},
+		"player1Id": {S: aws.String("alice")},
+		"player2Id": {S: aws.String("bob")},
+	})
+	f.put("game-service", map[string]*dynamodb.AttributeValue{
+		"PK":        {S: aws.String("ROOM#room-1")},
+		"SK":        {S: aws.String(turnKey(1))},
+		"moveRow":   {N: aws.String("2")},
+		"moveCol":   {N: aws.String("3")},
+		"moveColor": {N: aws.String("10")},
+	})
+}
+
+// playerRating reads the rating the fake has stored for userId
+func playerRating(f *fakeDynamo, userId string) string {
+	item := f.items["game-service|PLAYER#"+userId+"|RATING"]
+	if item == nil {
+		return ""
+	}
+	return aws.StringValue(item["rating"].N)
+}
+
+func TestFinalizeRoom(t *testing.T) {
+	t.Run("claims, archives and rates together", func(t *testing.T) {
+		f := newFakeDynamo()
+		newFinishedRoom(f)
+
+		changes, committed, err := finalizeRoom(f, "game-service", "room-1", turnKey(1), "黒")
+		if err != nil || !committed {
+			t.Fatalf("finalizeRoom() = (%v, %v), want (true, nil)", committed, err)
+		}
+		if len(f.transactions) != 1 {
+			t.Fatalf("finalizeRoom() ran %d transactions, want 1", len(f.transactions))
+		}
+
+		items := f.transactions[0].TransactItems
+		if len(items) != 4 || items[0].Update == nil || items[1].Put == nil {
+			t.Fatalf("transaction items = %v, want the room update, the archive and both ratings", items)
+		}
+		if cond := aws.StringValue(items[0].Update.ConditionExpression); !strings.Contains(cond, "attribute_not_exists(finalizationToken)") {
+			t.Fatalf("room update condition = %q, want it to require an unfinalized room", cond)
+		}
+		archive := f.items["game-archive|GAME#room-1|ARCHIVE"]
+		if archive == nil || aws.StringValue(archive["player1Id"].S) != "alice" || aws.StringValue(archive["gameData"].S) == "" {
+			t.Fatalf("archive = %v, want the game built from the server's history", archive)
+		}
+
+		// Black won, so PLAYER1 (alice) gains what PLAYER2 (bob) loses
+		if playerRating(f, "alice") != "1516" || playerRating(f, "bob") != "1484" {
+			t.Fatalf("ratings = alice %s, bob %s, want 1516 and 1484", playerRating(f, "alice"), playerRating(f, "bob"))
+		}
+		want := []RatingChange{{UserId: "alice", Before: 1500, After: 1516, Delta: 16}, {UserId: "bob", Before: 1500, After: 1484, Delta: -16}}
+		if len(changes) != 2 || changes[0] != want[0] || changes[1] != want[1] {
+			t.Fatalf("changes = %+v, want %+v", changes, want)
+		}
+	})
+
+	t.Run("token already set", func(t *testing.T) {
+		f := newFakeDynamo()
+		newFinishedRoom(f)
+		f.items["game-service|ROOM#room-1|METADATA"]["finalizationToken"] = &dynamodb.AttributeValue{S: aws.String(turnKey(1))}
+
+		changes, committed, err := finalizeRoom(f, "game-service", "room-1", turnKey(1), "黒")
+		if err != nil || committed || changes != nil {
+			t.Fatalf("finalizeRoom() = (%v, %v, %v), want (nil, false, nil)", changes, committed, err)
+		}
+		if len(f.transactions) != 0 {
+			t.Fatalf("finalizeRoom() ran %d transactions, want none", len(f.transactions))
+		}
+	})
+
+	t.Run("already finalized", func(t *testing.T) {
+		f := newFakeDynamo()
+		newFinishedRoom(f)
+		f.transactErrs = []error{canceled("ConditionalCheckFailed", "None", "None", "None")}
+
+		_, committed, err := finalizeRoom(f, "game-service", "room-1", turnKey(1), "黒")
+		if err != nil || committed {
+			t.Fatalf("finalizeRoom() = (%v, %v), want (false, nil)", committed, err)
+		}
+	})
+
+	t.Run("rating changed concurrently", func(t *testing.T) {
+		f := newFakeDynamo()
+		newFinishedRoom(f)
+		f.transactErrs = []error{canceled("None", "None", "ConditionalCheckFailed", "None")}
+
+		_, committed, err := finalizeRoom(f, "game-service", "room-1", turnKey(1), "黒")
+		if err != nil || !committed {
+			t.Fatalf("finalizeRoom() = (%v, %v), want (true, nil) after re-reading the ratings", committed, err)
+		}
+		if len(f.transactions) != 2 {
+			t.Fatalf("finalizeRoom() ran %d transactions, want 2", len(f.transactions))
+		}
+	})
+
+	t.Run("failure is retried later", func(t *testing.T) {
+		f := newFakeDynamo()
+		newFinishedRoom(f)
+		conflict := canceled("None", "None", "ConditionalCheckFailed", "None")
+		f.transactErrs = []error{conflict, conflict, conflict}
+
+		if _, committed, err := finalizeRoom(f, "game-service", "room-1", turnKey(1), "黒"); !errors.Is(err, errRatingConflict) || committed {
+			t.Fatalf("finalizeRoom() = (%v, %v), want errRatingConflict", committed, err)
+		}
+		if playerRating(f, "alice") != "" || f.items["game-archive|GAME#room-1|ARCHIVE"] != nil {
+			t.Fatal("a cancelled finalization wrote ratings or the archive")
+		}
+
+		// Nothing was committed, so the next call (the client's gameFinished) claims, archives and rates the game
+		if _, committed, err := finalizeRoom(f, "game-service", "room-1", turnKey(1), "黒"); err != nil || !committed {
+			t.Fatalf("retried finalizeRoom() = (%v, %v), want (true, nil)", committed, err)
+		}
+		if playerRating(f, "alice") != "1516" {
+			t.Fatalf("alice's rating = %s, want 1516", playerRating(f, "alice"))
+		}
+	})
+}
+
+func TestPlayer1Outcome(t *testing.T) {
+	cases := map[string]rating.Outcome{
+		rules.Black.String(): rating.Win,
+		rules.White.String(): rating.Loss,
+		rules.Draw.String():  rating.Draw,
+	}
+	for winner, want := range cases {
+		if got := player1Outcome(winner); got != want {
+			t.Fatalf("player1Outcome(%q) = %v, want %v", winner, got, want)
+		}
+	}
+}
+
+func TestSaveGameStateWithMove_StaleState(t *testing.T) {
+	f := newFakeDynamo()
+	newFinishedRoom(f)
+
+	board, _ := rules.Apply(rules.NewBoard(), rules.Move{Row: 2, Col: 3, Color: 10})
+	first := GameState{RoomId: "room-1", TurnNumber: 2, CurrentPlayer: "bob", NextColor: 200, GamePhase: "PLAYING", Board: board}
+	if err := saveGameStateWithMove(f, "game-service", first, 2, 3, 10); err != nil {
+		t.Fatalf("first save: %v", err)
+	}
+
+	// A concurrent invocation that read the same turn tries to save turn 2 as well
+	second := first
+	second.CurrentPlayer = "alice"
+	if err := saveGameStateWithMove(f, "game-service", second, 4, 5, 10); !errors.Is(err, errStaleState) {
+		t.Fatalf("second save err = %v, want errStaleState", err)
+	}
+
+	latest, err := getCurrentGameState(f, "game-service", "room-1")
+	if err != nil {
+		t.Fatalf("getCurrentGameState: %v", err)
+	}
+	if latest.TurnNumber != 2 || latest.CurrentPlayer != "bob" || latest.Board != board {
+		t.Fatalf("latest state = %+v, want the first save to be kept", latest)
+	}
+}
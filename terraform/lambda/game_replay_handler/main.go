@@ -1,21 +1,26 @@
 package main
 
 import (
-	"bytes"
 	"context"
+	"encoding/base64"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"math/rand"
-	"net/http"
 	"os"
+	"strconv"
 	"strings"
 	"time"
 
+	"github.com/2509-hackz-ichthyo/main/api/pkg/client"
+	"github.com/2509-hackz-ichthyo/main/api/pkg/rating"
 	"github.com/aws/aws-lambda-go/events"
 	"github.com/aws/aws-lambda-go/lambda"
 	"github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/aws/session"
 	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbiface"
+	"github.com/aws/aws-sdk-go/service/secretsmanager"
 )
 
 // GameArchive represents the archived game data structure
@@ -45,6 +50,41 @@ type RandomGameResponse struct {
 	Message string       `json:"message,omitempty"`
 }
 
+// PlayerRating represents a player's rating as returned by /leaderboard and /players/{id}/rating
+type PlayerRating struct {
+	Rank   int     `json:"rank,omitempty"` // 1-based position on the leaderboard
+	UserId string  `json:"userId"`
+	Rating float64 `json:"rating"`
+	Games  int     `json:"games"`
+	Wins   int     `json:"wins"`
+	Losses int     `json:"losses"`
+	Draws  int     `json:"draws"`
+}
+
+// LeaderboardPage represents one page of the leaderboard
+type LeaderboardPage struct {
+	Players    []PlayerRating `json:"players"`
+	NextCursor string         `json:"nextCursor,omitempty"` // pass as ?cursor= to get the next page
+}
+
+// RatingResponse represents the response body for /leaderboard and /players/{id}/rating
+type RatingResponse struct {
+	Success bool        `json:"success"`
+	Data    interface{} `json:"data,omitempty"`
+	Message string      `json:"message,omitempty"`
+}
+
+// leaderboardCursor is the opaque pagination cursor, base64-encoded JSON
+type leaderboardCursor struct {
+	LastKey map[string]string `json:"lastKey"`
+	Rank    int               `json:"rank"` // rank of the last player on the previous page
+}
+
+const (
+	defaultLeaderboardLimit = 20
+	maxLeaderboardLimit     = 100
+)
+
 func handleRequest(ctx context.Context, request events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
 	// CORS headers
 	headers := map[string]string{
@@ -77,8 +117,16 @@ func handleRequest(ctx context.Context, request events.APIGatewayProxyRequest) (
 		}, nil
 	}
 
+	// Rating endpoints share this Lambda with the replay endpoint
+	switch request.Resource {
+	case "/leaderboard":
+		return handleLeaderboard(request, headers), nil
+	case "/players/{id}/rating":
+		return handlePlayerRating(request, headers), nil
+	}
+
 	// Get random game from archive
-	gameArchive, err := getRandomGame()
+	gameArchive, err := getRandomGame(ctx)
 	if err != nil {
 		fmt.Printf("Error getting random game: %v\n", err)
 		response := RandomGameResponse{
@@ -120,7 +168,7 @@ func handleRequest(ctx context.Context, request events.APIGatewayProxyRequest) (
 	}, nil
 }
 
-func getRandomGame() (*GameArchive, error) {
+func getRandomGame(ctx context.Context) (*GameArchive, error) {
 	// Initialize AWS session
 	sess, err := session.NewSession(&aws.Config{
 		Region: aws.String(os.Getenv("AWS_REGION")),
@@ -196,7 +244,7 @@ func getRandomGame() (*GameArchive, error) {
 
 	// Whitespace→10進数変換を実行
 	if gameArchive.GameData != "" {
-		decodedData, err := convertWhitespaceToDecimal(gameArchive.GameData)
+		decodedData, err := convertWhitespaceToDecimal(ctx, gameArchive.GameData)
 		if err != nil {
 			fmt.Printf("Warning: Failed to decode whitespace data: %v\n", err)
 			// エラーでも処理続行（Whitespace形式のまま返却）
@@ -209,11 +257,265 @@ func getRandomGame() (*GameArchive, error) {
 	return gameArchive, nil
 }
 
+// handleLeaderboard returns players in descending rating order, ?limit= players per page
+func handleLeaderboard(request events.APIGatewayProxyRequest, headers map[string]string) events.APIGatewayProxyResponse {
+	limit := defaultLeaderboardLimit
+	if v := request.QueryStringParameters["limit"]; v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 {
+			return ratingResponse(400, headers, RatingResponse{Success: false, Message: "limit must be a positive integer"})
+		}
+		limit = min(n, maxLeaderboardLimit)
+	}
+
+	var cursor leaderboardCursor
+	if v := request.QueryStringParameters["cursor"]; v != "" {
+		var err error
+		if cursor, err = decodeLeaderboardCursor(v); err != nil {
+			return ratingResponse(400, headers, RatingResponse{Success: false, Message: "Invalid cursor"})
+		}
+	}
+
+	dynamo, tableName, err := newGameServiceClient()
+	if err != nil {
+		fmt.Printf("Error getting leaderboard: %v\n", err)
+		return ratingResponse(500, headers, RatingResponse{Success: false, Message: "Failed to get leaderboard"})
+	}
+	page, err := getLeaderboard(dynamo, tableName, limit, cursor)
+	if err != nil {
+		fmt.Printf("Error getting leaderboard: %v\n", err)
+		return ratingResponse(500, headers, RatingResponse{Success: false, Message: "Failed to get leaderboard"})
+	}
+	return ratingResponse(200, headers, RatingResponse{Success: true, Data: page})
+}
+
+// handlePlayerRating returns one player's rating; players who have not finished a game yet get the initial rating
+func handlePlayerRating(request events.APIGatewayProxyRequest, headers map[string]string) events.APIGatewayProxyResponse {
+	userId := request.PathParameters["id"]
+	if userId == "" {
+		return ratingResponse(400, headers, RatingResponse{Success: false, Message: "Player id is required"})
+	}
+
+	player, err := getPlayerRating(userId)
+	if err != nil {
+		fmt.Printf("Error getting rating for %s: %v\n", userId, err)
+		return ratingResponse(500, headers, RatingResponse{Success: false, Message: "Failed to get player rating"})
+	}
+	return ratingResponse(200, headers, RatingResponse{Success: true, Data: player})
+}
+
+func ratingResponse(statusCode int, headers map[string]string, response RatingResponse) events.APIGatewayProxyResponse {
+	body, _ := json.Marshal(response)
+	return events.APIGatewayProxyResponse{
+		StatusCode: statusCode,
+		Headers:    headers,
+		Body:       string(body),
+	}
+}
+
+// newGameServiceClient returns the DynamoDB client and the game-service table that game_handler writes ratings to
+func newGameServiceClient() (dynamodbiface.DynamoDBAPI, string, error) {
+	sess, err := session.NewSession(&aws.Config{
+		Region: aws.String(os.Getenv("AWS_REGION")),
+	})
+	if err != nil {
+		return nil, "", fmt.Errorf("error creating AWS session: %v", err)
+	}
+
+	tableName := os.Getenv("GAME_SERVICE_TABLE")
+	if tableName == "" {
+		tableName = "game-service"
+	}
+	return dynamodb.New(sess), tableName, nil
+}
+
+// getLeaderboard queries the LEADERBOARD partition of GSI1, whose sort key is the zero-padded rating
+func getLeaderboard(dynamo dynamodbiface.DynamoDBAPI, tableName string, limit int, cursor leaderboardCursor) (*LeaderboardPage, error) {
+	input := &dynamodb.QueryInput{
+		TableName:              aws.String(tableName),
+		IndexName:              aws.String("GSI1"),
+		KeyConditionExpression: aws.String("GSI1PK = :pk"),
+		ExpressionAttributeValues: map[string]*dynamodb.AttributeValue{
+			":pk": {S: aws.String("LEADERBOARD")},
+		},
+		ScanIndexForward: aws.Bool(false),
+		Limit:            aws.Int64(int64(limit)),
+	}
+	if len(cursor.LastKey) > 0 {
+		input.ExclusiveStartKey = map[string]*dynamodb.AttributeValue{}
+		for name, value := range cursor.LastKey {
+			input.ExclusiveStartKey[name] = &dynamodb.AttributeValue{S: aws.String(value)}
+		}
+	}
+
+	result, err := dynamo.Query(input)
+	if err != nil {
+		return nil, fmt.Errorf("error querying leaderboard: %v", err)
+	}
+
+	page := &LeaderboardPage{Players: []PlayerRating{}}
+	for i, item := range result.Items {
+		player := parsePlayerRating(item)
+		player.Rank = cursor.Rank + i + 1
+		page.Players = append(page.Players, player)
+	}
+
+	if len(result.LastEvaluatedKey) > 0 {
+		next := leaderboardCursor{LastKey: map[string]string{}, Rank: cursor.Rank + len(result.Items)}
+		for name, value := range result.LastEvaluatedKey {
+			if value.S != nil {
+				next.LastKey[name] = *value.S
+			}
+		}
+		page.NextCursor = encodeLeaderboardCursor(next)
+	}
+	return page, nil
+}
+
+// encodeLeaderboardCursor returns the cursor as sent to the client
+func encodeLeaderboardCursor(cursor leaderboardCursor) string {
+	raw, _ := json.Marshal(cursor)
+	return base64.RawURLEncoding.EncodeToString(raw)
+}
+
+// decodeLeaderboardCursor parses a cursor sent by the client
+func decodeLeaderboardCursor(v string) (leaderboardCursor, error) {
+	var cursor leaderboardCursor
+	raw, err := base64.RawURLEncoding.DecodeString(v)
+	if err == nil {
+		err = json.Unmarshal(raw, &cursor)
+	}
+	if err == nil && len(cursor.LastKey) == 0 {
+		err = errors.New("cursor has no last key")
+	}
+	return cursor, err
+}
+
+// getPlayerRating reads the PLAYER#<userId>/RATING item written by game_handler
+func getPlayerRating(userId string) (*PlayerRating, error) {
+	dynamo, tableName, err := newGameServiceClient()
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := dynamo.GetItem(&dynamodb.GetItemInput{
+		TableName: aws.String(tableName),
+		Key: map[string]*dynamodb.AttributeValue{
+			"PK": {S: aws.String(fmt.Sprintf("PLAYER#%s", userId))},
+			"SK": {S: aws.String("RATING")},
+		},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error getting rating item: %v", err)
+	}
+
+	if result.Item == nil {
+		return &PlayerRating{UserId: userId, Rating: rating.Initial}, nil
+	}
+	player := parsePlayerRating(result.Item)
+	player.UserId = userId
+	return &player, nil
+}
+
+// parsePlayerRating converts a rating item into PlayerRating
+func parsePlayerRating(item map[string]*dynamodb.AttributeValue) PlayerRating {
+	player := PlayerRating{Rating: rating.Initial}
+	if userId, ok := item["userId"]; ok && userId.S != nil {
+		player.UserId = *userId.S
+	}
+	if v, ok := item["rating"]; ok && v.N != nil {
+		if r, err := strconv.ParseFloat(*v.N, 64); err == nil {
+			player.Rating = r
+		}
+	}
+	for name, field := range map[string]*int{
+		"games":  &player.Games,
+		"wins":   &player.Wins,
+		"losses": &player.Losses,
+		"draws":  &player.Draws,
+	} {
+		if v, ok := item[name]; ok && v.N != nil {
+			*field, _ = strconv.Atoi(*v.N)
+		}
+	}
+	return player
+}
+
+// defaultDecodeAPIURL は DECODE_API_URL が未設定の場合に用いる Decode API（ECS Fargate）の URL
+const defaultDecodeAPIURL = "http://18.181.38.132:3000"
+
+// decodeAPIClient は Decode API のシークレットのうち、ゲームの Lambda に割り当てたキーのクライアント名
+const decodeAPIClient = "game-lambda"
+
+// decodeAPIKeyCache は Secrets Manager から読んだキーを Lambda の実行環境が続く間だけ保持する
+var decodeAPIKeyCache string
+
+// newDecodeClient は Decode API のクライアントを生成する
+func newDecodeClient() (*client.Client, error) {
+	apiURL := os.Getenv("DECODE_API_URL")
+	if apiURL == "" {
+		apiURL = defaultDecodeAPIURL
+	}
+	fmt.Printf("Using decode API URL: %s\n", apiURL)
+
+	apiKey, err := decodeAPIKey()
+	if err != nil {
+		return nil, err
+	}
+	return client.New(apiURL, client.WithAPIKey(apiKey))
+}
+
+// decodeAPIKey は Decode API の API キーを返す。
+// DECODE_API_KEY が無ければ DECODE_API_KEY_SECRET_ARN のシークレット（API_KEYS_JSON 形式）から読む
+func decodeAPIKey() (string, error) {
+	if key := os.Getenv("DECODE_API_KEY"); key != "" {
+		return key, nil
+	}
+	secretARN := os.Getenv("DECODE_API_KEY_SECRET_ARN")
+	if secretARN == "" || decodeAPIKeyCache != "" {
+		return decodeAPIKeyCache, nil
+	}
+
+	sess, err := session.NewSession(&aws.Config{
+		Region: aws.String(os.Getenv("AWS_REGION")),
+	})
+	if err != nil {
+		return "", fmt.Errorf("error creating AWS session: %v", err)
+	}
+	result, err := secretsmanager.New(sess).GetSecretValue(&secretsmanager.GetSecretValueInput{
+		SecretId: aws.String(secretARN),
+	})
+	if err != nil {
+		return "", fmt.Errorf("error reading decode API key secret: %v", err)
+	}
+
+	key, err := findAPIKey(aws.StringValue(result.SecretString), decodeAPIClient)
+	if err != nil {
+		return "", err
+	}
+	decodeAPIKeyCache = key
+	return key, nil
+}
+
+// findAPIKey は API_KEYS_JSON 形式のシークレットから client のキーを取り出す
+func findAPIKey(secret, clientName string) (string, error) {
+	var keys []struct {
+		Client string `json:"client"`
+		Key    string `json:"key"`
+	}
+	if err := json.Unmarshal([]byte(secret), &keys); err != nil {
+		return "", fmt.Errorf("error parsing decode API key secret: %v", err)
+	}
+	for _, k := range keys {
+		if k.Client == clientName && k.Key != "" {
+			return k.Key, nil
+		}
+	}
+	return "", fmt.Errorf("no decode API key for client %q in secret", clientName)
+}
+
 // convertWhitespaceToDecimal はWhitespace形式データを10進数形式に変換する
-func convertWhitespaceToDecimal(whitespaceData string) (string, error) {
-	// 固定IPアドレスを使用（ECS Fargateへのアクセス）
-	apiURL := "http://18.181.38.132:3000"
-	fmt.Printf("Converting whitespace to decimal using API: %s\n", apiURL)
+func convertWhitespaceToDecimal(ctx context.Context, whitespaceData string) (string, error) {
 	fmt.Printf("Input whitespace data length: %d, first 100 chars: %q\n", len(whitespaceData), whitespaceData[:min(100, len(whitespaceData))])
 
 	if whitespaceData == "" {
@@ -252,65 +554,22 @@ func convertWhitespaceToDecimal(whitespaceData string) (string, error) {
 
 	fmt.Printf("Total sentences created: %d\n", len(sentences))
 
-	// Prepare the request payload
-	reqBody := map[string]interface{}{
-		"command_type": "WhitespaceToDecimal",
-		"payload":      sentences, // Array of sentences instead of single string
-	}
-
-	fmt.Printf("Request body prepared with %d sentences\n", len(sentences))
-
-	jsonData, err := json.Marshal(reqBody)
+	decodeClient, err := newDecodeClient()
 	if err != nil {
-		return "", fmt.Errorf("failed to marshal request: %v", err)
+		return "", err
 	}
-	fmt.Printf("JSON request prepared (length: %d)\n", len(jsonData))
 
-	resp, err := http.Post(apiURL+"/v1/decode", "application/json", bytes.NewBuffer(jsonData))
+	decimalLines, err := decodeClient.WhitespaceToDecimal(ctx, sentences)
 	if err != nil {
-		return "", fmt.Errorf("failed to call decode API: %v", err)
-	}
-	defer resp.Body.Close()
-
-	fmt.Printf("Response status code: %d\n", resp.StatusCode)
-	if resp.StatusCode != http.StatusOK {
-		// レスポンスボディを読んでエラー内容を確認
-		bodyBytes := make([]byte, 1024)
-		n, _ := resp.Body.Read(bodyBytes)
-		return "", fmt.Errorf("decode API returned status code: %d, body: %s", resp.StatusCode, string(bodyBytes[:n]))
+		return "", err
 	}
-
-	// Decode APIレスポンス形式に合わせて解析
-	var apiResp map[string]interface{}
-	if err := json.NewDecoder(resp.Body).Decode(&apiResp); err != nil {
-		return "", fmt.Errorf("failed to decode response: %v", err)
-	}
-	fmt.Printf("Decode API response keys: %v\n", getMapKeys(apiResp))
-
-	// result_decimalsフィールドから結果を取得（配列形式）
-	if resultArray, ok := apiResp["result_decimals"].([]interface{}); ok && len(resultArray) > 0 {
-		// 配列の各要素を文字列として連結
-		var decimalLines []string
-		for _, item := range resultArray {
-			if line, ok := item.(string); ok {
-				decimalLines = append(decimalLines, line)
-			}
-		}
-		result := strings.Join(decimalLines, "\n")
-		fmt.Printf("Converted decimal data (lines: %d): %q\n", len(decimalLines), result[:min(200, len(result))])
-		return result, nil
+	if len(decimalLines) == 0 {
+		return "", fmt.Errorf("empty decode result for %d sentences", len(sentences))
 	}
 
-	return "", fmt.Errorf("empty or invalid decode result, full response keys: %v", getMapKeys(apiResp))
-}
-
-// helper function to get map keys for debugging
-func getMapKeys(m map[string]interface{}) []string {
-	keys := make([]string, 0, len(m))
-	for k := range m {
-		keys = append(keys, k)
-	}
-	return keys
+	result := strings.Join(decimalLines, "\n")
+	fmt.Printf("Converted decimal data (lines: %d): %q\n", len(decimalLines), result[:min(200, len(result))])
+	return result, nil
 }
 
 // helper function for min
@@ -0,0 +1,106 @@
+package main
+
+import (
+	"sort"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbiface"
+)
+
+// fakeLeaderboard serves the descending GSI1 query of the LEADERBOARD partition, one page of Limit items at a time.
+// Methods it does not implement panic through the embedded nil interface.
+type fakeLeaderboard struct {
+	dynamodbiface.DynamoDBAPI
+
+	items []map[string]*dynamodb.AttributeValue
+}
+
+func (f *fakeLeaderboard) add(userId, gsi1sk, rating string) {
+	f.items = append(f.items, map[string]*dynamodb.AttributeValue{
+		"PK":     {S: aws.String("PLAYER#" + userId)},
+		"SK":     {S: aws.String("RATING")},
+		"GSI1PK": {S: aws.String("LEADERBOARD")},
+		"GSI1SK": {S: aws.String(gsi1sk)},
+		"userId": {S: aws.String(userId)},
+		"rating": {N: aws.String(rating)},
+	})
+}
+
+func (f *fakeLeaderboard) Query(input *dynamodb.QueryInput) (*dynamodb.QueryOutput, error) {
+	sort.Slice(f.items, func(i, j int) bool {
+		return aws.StringValue(f.items[i]["GSI1SK"].S) > aws.StringValue(f.items[j]["GSI1SK"].S)
+	})
+
+	start := 0
+	if input.ExclusiveStartKey != nil {
+		last := aws.StringValue(input.ExclusiveStartKey["GSI1SK"].S)
+		for start < len(f.items) && aws.StringValue(f.items[start]["GSI1SK"].S) >= last {
+			start++
+		}
+	}
+	end := min(start+int(aws.Int64Value(input.Limit)), len(f.items))
+
+	output := &dynamodb.QueryOutput{Items: f.items[start:end]}
+	if end < len(f.items) {
+		last := f.items[end-1]
+		output.LastEvaluatedKey = map[string]*dynamodb.AttributeValue{
+			"PK": last["PK"], "SK": last["SK"], "GSI1PK": last["GSI1PK"], "GSI1SK": last["GSI1SK"],
+		}
+	}
+	return output, nil
+}
+
+func TestGetLeaderboard_Pages(t *testing.T) {
+	f := &fakeLeaderboard{}
+	f.add("carol", "001480#carol", "1480")
+	f.add("alice", "001532#alice", "1532")
+	f.add("bob", "001516#bob", "1516")
+
+	first, err := getLeaderboard(f, "game-service", 2, leaderboardCursor{})
+	if err != nil {
+		t.Fatalf("first page: %v", err)
+	}
+	if len(first.Players) != 2 || first.Players[0].UserId != "alice" || first.Players[0].Rank != 1 || first.Players[1].Rank != 2 {
+		t.Fatalf("first page = %+v, want alice and bob ranked 1 and 2", first.Players)
+	}
+	if first.NextCursor == "" {
+		t.Fatal("first page has no next cursor")
+	}
+
+	// The cursor round-trips through the client and carries the rank on to the next page
+	cursor, err := decodeLeaderboardCursor(first.NextCursor)
+	if err != nil {
+		t.Fatalf("decodeLeaderboardCursor: %v", err)
+	}
+	if cursor.Rank != 2 || cursor.LastKey["GSI1SK"] != "001516#bob" {
+		t.Fatalf("cursor = %+v, want rank 2 after bob", cursor)
+	}
+	if encodeLeaderboardCursor(cursor) != first.NextCursor {
+		t.Fatal("re-encoded cursor differs from the one sent to the client")
+	}
+
+	second, err := getLeaderboard(f, "game-service", 2, cursor)
+	if err != nil {
+		t.Fatalf("second page: %v", err)
+	}
+	if len(second.Players) != 1 || second.Players[0].UserId != "carol" || second.Players[0].Rank != 3 || second.Players[0].Rating != 1480 {
+		t.Fatalf("second page = %+v, want carol ranked 3", second.Players)
+	}
+	if second.NextCursor != "" {
+		t.Fatalf("last page has next cursor %q", second.NextCursor)
+	}
+}
+
+func TestDecodeLeaderboardCursor_Invalid(t *testing.T) {
+	for _, v := range []string{
+		"not base64!",
+		"bm90IGpzb24", // "not json"
+		encodeLeaderboardCursor(leaderboardCursor{Rank: 20}), // no last key
+	} {
+		if _, err := decodeLeaderboardCursor(v); err == nil {
+			t.Fatalf("decodeLeaderboardCursor(%q) succeeded, want an error", v)
+		}
+	}
+}
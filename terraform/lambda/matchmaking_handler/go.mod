@@ -1,9 +1,14 @@
 module matchmaking_handler
 
-go 1.21
+go 1.25.1
 
 require (
-	github.com/aws/aws-lambda-go v1.49.0 // indirect
-	github.com/aws/aws-sdk-go v1.55.8 // indirect
-	github.com/jmespath/go-jmespath v0.4.0 // indirect
+	github.com/2509-hackz-ichthyo/main/api v0.0.0-00010101000000-000000000000
+	github.com/aws/aws-lambda-go v1.49.0
+	github.com/aws/aws-sdk-go v1.55.8
 )
+
+require github.com/jmespath/go-jmespath v0.4.0 // indirect
+
+// 対戦相手の選び方（matchmaking）はリポジトリ内の api モジュールから取り込む
+replace github.com/2509-hackz-ichthyo/main/api => ../../../api
@@ -3,19 +3,35 @@ package main
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"os"
+	"strconv"
 	"time"
 
+	"github.com/2509-hackz-ichthyo/main/api/pkg/matchmaking"
+	"github.com/2509-hackz-ichthyo/main/api/pkg/waitingqueue"
 	"github.com/aws/aws-lambda-go/events"
 	"github.com/aws/aws-lambda-go/lambda"
 	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
 	"github.com/aws/aws-sdk-go/aws/session"
 	"github.com/aws/aws-sdk-go/service/apigatewaymanagementapi"
+	"github.com/aws/aws-sdk-go/service/apigatewaymanagementapi/apigatewaymanagementapiiface"
 	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbiface"
 )
 
-// JoinGameRequest represents the request body for joinGame
+// maxMatchAttempts is how many times a join retries when another request claims the same waiting player first
+const maxMatchAttempts = 5
+
+// errClaimLost is returned when the waiting player was claimed by another request before our transaction committed
+var errClaimLost = errors.New("waiting player was claimed by another request")
+
+// errAlreadyWaiting is returned when the player already has an entry in the waiting queue
+var errAlreadyWaiting = errors.New("player is already in the waiting queue")
+
+// JoinGameRequest represents the request body for joinGame, leaveQueue and queueStatus
 type JoinGameRequest struct {
 	Action string `json:"action"`
 	UserId string `json:"userId"`
@@ -23,10 +39,21 @@ type JoinGameRequest struct {
 
 // MatchFoundResponse represents the response when a match is found
 type MatchFoundResponse struct {
-	Type       string `json:"type"`
-	RoomId     string `json:"roomId"`
-	Role       string `json:"role"`
-	OpponentId string `json:"opponentId"`
+	Type           string      `json:"type"`
+	RoomId         string      `json:"roomId"`
+	Role           string      `json:"role"`
+	OpponentId     string      `json:"opponentId"`
+	Rating         float64     `json:"rating"`
+	OpponentRating float64     `json:"opponentRating"`
+	RatingBand     *RatingBand `json:"ratingBand,omitempty"`
+}
+
+// RatingBand explains why the two players were paired
+type RatingBand struct {
+	Difference    float64 `json:"difference"`    // rating difference between the players
+	Window        float64 `json:"window"`        // allowed difference at the time of the match
+	WaitedSeconds int     `json:"waitedSeconds"` // waiting time of the player who waited longer
+	Explanation   string  `json:"explanation"`
 }
 
 // WaitingResponse represents the response when waiting for a match
@@ -35,7 +62,67 @@ type WaitingResponse struct {
 	Message string `json:"message"`
 }
 
+// QueueStatusResponse tells a waiting player where they are in the queue
+type QueueStatusResponse struct {
+	Type                 string `json:"type"`                           // "queueStatus"
+	Position             int    `json:"position"`                       // 1 = next to be matched
+	QueueLength          int    `json:"queueLength"`                    // number of players waiting
+	WaitedSeconds        int64  `json:"waitedSeconds"`                  // time since joining the queue
+	EstimatedWaitSeconds *int   `json:"estimatedWaitSeconds,omitempty"` // omitted until a match has been made
+}
+
+// QueueLeftResponse confirms that the player has been removed from the queue
+type QueueLeftResponse struct {
+	Type    string `json:"type"` // "queueLeft"
+	Removed int    `json:"removed"`
+}
+
+// matchStats are running totals used to estimate waiting time
+type matchStats struct {
+	TotalWaitSeconds int64
+	MatchCount       int64
+}
+
+// handleEvent routes the scheduled queue sweep from EventBridge and WebSocket requests from API Gateway
+func handleEvent(ctx context.Context, event json.RawMessage) (events.APIGatewayProxyResponse, error) {
+	var scheduled events.CloudWatchEvent
+	if err := json.Unmarshal(event, &scheduled); err == nil && scheduled.Source == "aws.events" {
+		return events.APIGatewayProxyResponse{StatusCode: 200}, handleQueueSweep(ctx)
+	}
+
+	var request events.APIGatewayWebsocketProxyRequest
+	if err := json.Unmarshal(event, &request); err != nil {
+		fmt.Printf("Error parsing event: %v\n", err)
+		return events.APIGatewayProxyResponse{StatusCode: 400}, err
+	}
+	return handleMatchmaking(ctx, request)
+}
+
 func handleMatchmaking(ctx context.Context, request events.APIGatewayWebsocketProxyRequest) (events.APIGatewayProxyResponse, error) {
+	// First parse to get action
+	var actionRequest struct {
+		Action string `json:"action"`
+	}
+	if err := json.Unmarshal([]byte(request.Body), &actionRequest); err != nil {
+		fmt.Printf("Error parsing request body for action: %v\n", err)
+		return events.APIGatewayProxyResponse{StatusCode: 400}, err
+	}
+
+	// Route based on action
+	switch actionRequest.Action {
+	case "joinGame":
+		return handleJoinGame(ctx, request)
+	case "leaveQueue":
+		return handleLeaveQueue(ctx, request)
+	case "queueStatus":
+		return handleQueueStatus(ctx, request)
+	default:
+		fmt.Printf("Unknown action: %s\n", actionRequest.Action)
+		return events.APIGatewayProxyResponse{StatusCode: 400}, fmt.Errorf("unknown action: %s", actionRequest.Action)
+	}
+}
+
+func handleJoinGame(ctx context.Context, request events.APIGatewayWebsocketProxyRequest) (events.APIGatewayProxyResponse, error) {
 	// Get connection ID from request context
 	connectionId := request.RequestContext.ConnectionID
 
@@ -53,125 +140,376 @@ func handleMatchmaking(ctx context.Context, request events.APIGatewayWebsocketPr
 		return events.APIGatewayProxyResponse{StatusCode: 400}, fmt.Errorf("userId is required")
 	}
 
-	// Initialize AWS session
-	sess, err := session.NewSession(&aws.Config{
-		Region: aws.String(os.Getenv("AWS_REGION")),
-	})
+	dynamo, apiGW, tableName, err := newClients(request)
 	if err != nil {
 		fmt.Printf("Error creating AWS session: %v\n", err)
 		return events.APIGatewayProxyResponse{StatusCode: 500}, err
 	}
 
-	dynamo := dynamodb.New(sess)
-	apiGW := apigatewaymanagementapi.New(sess, &aws.Config{
-		Endpoint: aws.String(fmt.Sprintf("https://%s.execute-api.%s.amazonaws.com/%s",
-			request.RequestContext.APIID,
-			os.Getenv("AWS_REGION"),
-			request.RequestContext.Stage)),
-	})
-
-	tableName := os.Getenv("DYNAMODB_TABLE_NAME")
-	if tableName == "" {
-		tableName = "websocket-connections"
+	// A player who is already waiting keeps their place; only the connection is updated
+	waiting, err := findQueueEntry(dynamo, tableName, joinRequest.UserId)
+	if err != nil {
+		fmt.Printf("Error reading waiting queue: %v\n", err)
+		return events.APIGatewayProxyResponse{StatusCode: 500}, err
+	}
+	if waiting != nil {
+		return rejoinQueue(dynamo, apiGW, tableName, *waiting, connectionId)
 	}
 
-	// Check for waiting players
-	waitingPlayer, err := findWaitingPlayer(dynamo, tableName)
+	player := WaitingPlayer{
+		UserId:       joinRequest.UserId,
+		ConnectionId: connectionId,
+		Timestamp:    time.Now().Unix(),
+		Rating:       loadRating(dynamo, tableName, joinRequest.UserId),
+	}
+	matched, err := matchPlayer(dynamo, apiGW, tableName, player)
 	if err != nil {
-		fmt.Printf("Error finding waiting player: %v\n", err)
 		return events.APIGatewayProxyResponse{StatusCode: 500}, err
 	}
 
-	if waitingPlayer != nil {
-		// Match found - create room and notify both players
-		roomId := generateRoomId()
-
-		err = createGameRoom(dynamo, tableName, roomId, waitingPlayer.UserId, joinRequest.UserId, waitingPlayer.ConnectionId, connectionId)
+	if !matched {
+		// No waiting player within the rating band - add to waiting queue
+		err = addToWaitingQueue(dynamo, tableName, player)
+		if errors.Is(err, errAlreadyWaiting) {
+			// A concurrent join of the same player queued first
+			fmt.Printf("Player %s joined the waiting queue concurrently; ignoring duplicate join\n", joinRequest.UserId)
+			if waiting, err := findQueueEntry(dynamo, tableName, joinRequest.UserId); err == nil && waiting != nil {
+				return rejoinQueue(dynamo, apiGW, tableName, *waiting, connectionId)
+			}
+			return events.APIGatewayProxyResponse{StatusCode: 200}, nil
+		}
 		if err != nil {
-			fmt.Printf("Error creating game room: %v\n", err)
+			fmt.Printf("Error adding to waiting queue: %v\n", err)
 			return events.APIGatewayProxyResponse{StatusCode: 500}, err
 		}
 
-		// Notify player 1 (waiting player)
-		player1Response := MatchFoundResponse{
-			Type:       "matchFound",
-			RoomId:     roomId,
-			Role:       "PLAYER1",
-			OpponentId: joinRequest.UserId,
+		// Notify player they are waiting
+		waitingResponse := WaitingResponse{
+			Type:    "waiting",
+			Message: "Waiting for opponent...",
 		}
-		err = sendMessage(apiGW, waitingPlayer.ConnectionId, player1Response)
+		err = sendMessage(apiGW, connectionId, waitingResponse)
 		if err != nil {
-			fmt.Printf("Error sending message to player 1: %v\n", err)
+			fmt.Printf("Error sending waiting message: %v\n", err)
 		}
 
-		// Notify player 2 (current player)
-		player2Response := MatchFoundResponse{
-			Type:       "matchFound",
-			RoomId:     roomId,
-			Role:       "PLAYER2",
-			OpponentId: waitingPlayer.UserId,
+		fmt.Printf("Player %s (rating %.0f) added to waiting queue\n", joinRequest.UserId, player.Rating)
+		broadcastQueueStatus(dynamo, apiGW, tableName)
+	}
+
+	return events.APIGatewayProxyResponse{StatusCode: 200}, nil
+}
+
+// rejoinQueue handles a joinGame from a player who is already waiting: the queue entry keeps its place
+// and is pointed at the calling connection
+func rejoinQueue(dynamo dynamodbiface.DynamoDBAPI, apiGW apigatewaymanagementapiiface.ApiGatewayManagementApiAPI, tableName string, waiting WaitingPlayer, connectionId string) (events.APIGatewayProxyResponse, error) {
+	fmt.Printf("Player %s is already in the waiting queue; ignoring duplicate join\n", waiting.UserId)
+	if waiting.ConnectionId != connectionId {
+		err := updateQueueConnection(dynamo, tableName, waiting.QueueKey, connectionId)
+		if isConditionalCheckFailed(err) {
+			// Matched or removed since it was read; the result has been sent to the player
+			fmt.Printf("Queue entry of %s is gone; not updating the connection\n", waiting.UserId)
+			return events.APIGatewayProxyResponse{StatusCode: 200}, nil
 		}
-		err = sendMessage(apiGW, connectionId, player2Response)
 		if err != nil {
-			fmt.Printf("Error sending message to player 2: %v\n", err)
+			fmt.Printf("Error updating queue connection: %v\n", err)
+			return events.APIGatewayProxyResponse{StatusCode: 500}, err
 		}
+	}
+	broadcastQueueStatus(dynamo, apiGW, tableName)
+	return events.APIGatewayProxyResponse{StatusCode: 200}, nil
+}
 
-		fmt.Printf("Match created: Room %s with players %s and %s\n", roomId, waitingPlayer.UserId, joinRequest.UserId)
-	} else {
-		// No waiting player - add to waiting queue
-		err = addToWaitingQueue(dynamo, tableName, joinRequest.UserId, connectionId)
+// matchPlayer pairs player with someone in the waiting queue within the rating band and notifies both.
+// player.QueueKey is empty for a player who has just joined and set for a player who is already waiting.
+// The opponent is claimed and the room created in one transaction, retrying if another request wins the claim.
+// It reports whether a match was made.
+func matchPlayer(dynamo dynamodbiface.DynamoDBAPI, apiGW apigatewaymanagementapiiface.ApiGatewayManagementApiAPI, tableName string, player WaitingPlayer) (bool, error) {
+	var waitingPlayer *WaitingPlayer
+	var band matchmaking.Match
+	var roomId string
+	for attempt := 1; attempt <= maxMatchAttempts; attempt++ {
+		var err error
+		waitingPlayer, band, err = findWaitingPlayer(dynamo, tableName, player)
 		if err != nil {
-			fmt.Printf("Error adding to waiting queue: %v\n", err)
-			return events.APIGatewayProxyResponse{StatusCode: 500}, err
+			fmt.Printf("Error finding waiting player: %v\n", err)
+			return false, err
+		}
+		if waitingPlayer == nil {
+			return false, nil
 		}
 
-		// Notify player they are waiting
-		waitingResponse := WaitingResponse{
-			Type:    "waiting",
-			Message: "Waiting for opponent...",
+		roomId = generateRoomId()
+		err = createGameRoom(dynamo, tableName, roomId, *waitingPlayer, player)
+		if err == nil {
+			break
 		}
-		err = sendMessage(apiGW, connectionId, waitingResponse)
-		if err != nil {
-			fmt.Printf("Error sending waiting message: %v\n", err)
+		if !errors.Is(err, errClaimLost) {
+			fmt.Printf("Error creating game room: %v\n", err)
+			return false, err
+		}
+		if player.QueueKey != "" {
+			// Our own queue entry may be the one that was claimed; either way the next sweep reads the queue again
+			fmt.Printf("Match for waiting player %s was not created: %v\n", player.UserId, err)
+			return false, nil
 		}
 
-		fmt.Printf("Player %s added to waiting queue\n", joinRequest.UserId)
+		fmt.Printf("Waiting player %s was claimed by another request (attempt %d/%d)\n", waitingPlayer.UserId, attempt, maxMatchAttempts)
+		waitingPlayer = nil
+		time.Sleep(time.Duration(attempt*20) * time.Millisecond)
+	}
+	if waitingPlayer == nil {
+		return false, nil
+	}
+
+	// Match found - room has been created, notify both players
+	ratingBand := &RatingBand{
+		Difference:    band.Difference,
+		Window:        band.Window,
+		WaitedSeconds: int(band.Waited.Seconds()),
+		Explanation:   band.Explanation(),
+	}
+
+	// Notify player 1 (waiting player)
+	player1Response := MatchFoundResponse{
+		Type:           "matchFound",
+		RoomId:         roomId,
+		Role:           "PLAYER1",
+		OpponentId:     player.UserId,
+		Rating:         waitingPlayer.Rating,
+		OpponentRating: player.Rating,
+		RatingBand:     ratingBand,
+	}
+	if err := sendMessage(apiGW, waitingPlayer.ConnectionId, player1Response); err != nil {
+		fmt.Printf("Error sending message to player 1: %v\n", err)
+	}
+
+	// Notify player 2 (current player)
+	player2Response := MatchFoundResponse{
+		Type:           "matchFound",
+		RoomId:         roomId,
+		Role:           "PLAYER2",
+		OpponentId:     waitingPlayer.UserId,
+		Rating:         player.Rating,
+		OpponentRating: waitingPlayer.Rating,
+		RatingBand:     ratingBand,
+	}
+	if err := sendMessage(apiGW, player.ConnectionId, player2Response); err != nil {
+		fmt.Printf("Error sending message to player 2: %v\n", err)
+	}
+
+	fmt.Printf("Match created: Room %s with players %s and %s (%s)\n", roomId, waitingPlayer.UserId, player.UserId, ratingBand.Explanation)
+
+	if err := recordMatchWait(dynamo, tableName, time.Now().Unix()-waitingPlayer.Timestamp); err != nil {
+		fmt.Printf("Error recording match statistics: %v\n", err)
+	}
+	// Everyone behind the matched players moves up
+	broadcastQueueStatus(dynamo, apiGW, tableName)
+	return true, nil
+}
+
+// handleLeaveQueue removes the player's queue entries, matched by userId or by the calling connection
+func handleLeaveQueue(ctx context.Context, request events.APIGatewayWebsocketProxyRequest) (events.APIGatewayProxyResponse, error) {
+	connectionId := request.RequestContext.ConnectionID
+
+	var leaveRequest JoinGameRequest
+	if err := json.Unmarshal([]byte(request.Body), &leaveRequest); err != nil {
+		fmt.Printf("Error parsing request body: %v\n", err)
+		return events.APIGatewayProxyResponse{StatusCode: 400}, err
+	}
+
+	dynamo, apiGW, tableName, err := newClients(request)
+	if err != nil {
+		fmt.Printf("Error creating AWS session: %v\n", err)
+		return events.APIGatewayProxyResponse{StatusCode: 500}, err
+	}
+
+	removed, err := removeFromWaitingQueue(dynamo, tableName, func(w WaitingPlayer) bool {
+		return w.ConnectionId == connectionId || (leaveRequest.UserId != "" && w.UserId == leaveRequest.UserId)
+	})
+	if err != nil {
+		fmt.Printf("Error leaving waiting queue: %v\n", err)
+		return events.APIGatewayProxyResponse{StatusCode: 500}, err
+	}
+
+	if err := sendMessage(apiGW, connectionId, QueueLeftResponse{Type: "queueLeft", Removed: removed}); err != nil {
+		fmt.Printf("Error sending queueLeft message: %v\n", err)
+	}
+	if removed > 0 {
+		broadcastQueueStatus(dynamo, apiGW, tableName)
+	}
+
+	fmt.Printf("Player %s left the waiting queue (%d entries removed)\n", leaveRequest.UserId, removed)
+	return events.APIGatewayProxyResponse{StatusCode: 200}, nil
+}
+
+// handleQueueStatus answers the client's periodic queueStatus request for the calling connection.
+// It only reads the queue; matching is retried by the scheduled sweep (handleQueueSweep), not by status requests.
+func handleQueueStatus(ctx context.Context, request events.APIGatewayWebsocketProxyRequest) (events.APIGatewayProxyResponse, error) {
+	dynamo, apiGW, tableName, err := newClients(request)
+	if err != nil {
+		fmt.Printf("Error creating AWS session: %v\n", err)
+		return events.APIGatewayProxyResponse{StatusCode: 500}, err
 	}
 
+	if err := sendQueueStatus(dynamo, apiGW, tableName, request.RequestContext.ConnectionID); err != nil {
+		fmt.Printf("Error reading waiting queue: %v\n", err)
+		return events.APIGatewayProxyResponse{StatusCode: 500}, err
+	}
 	return events.APIGatewayProxyResponse{StatusCode: 200}, nil
 }
 
+// sendQueueStatus sends the connection its place in the queue, or queueLeft if it is not waiting
+func sendQueueStatus(dynamo dynamodbiface.DynamoDBAPI, apiGW apigatewaymanagementapiiface.ApiGatewayManagementApiAPI, tableName, connectionId string) error {
+	queue, err := listWaitingQueue(dynamo, tableName)
+	if err != nil {
+		return err
+	}
+	stats, err := loadMatchStats(dynamo, tableName)
+	if err != nil {
+		fmt.Printf("Error loading match statistics: %v\n", err)
+	}
+
+	now := time.Now().Unix()
+	for i, waiting := range queue {
+		if waiting.ConnectionId == connectionId {
+			if err := sendMessage(apiGW, connectionId, newQueueStatus(i+1, len(queue), now-waiting.Timestamp, stats)); err != nil {
+				fmt.Printf("Error sending queueStatus message: %v\n", err)
+			}
+			return nil
+		}
+	}
+
+	// Not waiting (already matched or removed): tell the client so that it stops polling
+	if err := sendMessage(apiGW, connectionId, QueueLeftResponse{Type: "queueLeft"}); err != nil {
+		fmt.Printf("Error sending queueLeft message: %v\n", err)
+	}
+	return nil
+}
+
+// handleQueueSweep runs every minute from EventBridge. Rating bands widen while players wait, so players who
+// could not be paired when they joined may be pairable now: it retries matching for everyone waiting and
+// pushes queueStatus to those who are still waiting.
+func handleQueueSweep(ctx context.Context) error {
+	dynamo, apiGW, tableName, err := newClientsForEndpoint(os.Getenv("WEBSOCKET_API_ENDPOINT"))
+	if err != nil {
+		fmt.Printf("Error creating AWS session: %v\n", err)
+		return err
+	}
+
+	matches, err := sweepWaitingQueue(dynamo, apiGW, tableName)
+	if err != nil {
+		fmt.Printf("Error sweeping waiting queue: %v\n", err)
+		return err
+	}
+	if matches == 0 {
+		// matchPlayer broadcasts after each match; otherwise push the updated waiting times here
+		broadcastQueueStatus(dynamo, apiGW, tableName)
+	}
+
+	fmt.Printf("Queue sweep made %d matches\n", matches)
+	return nil
+}
+
+// sweepWaitingQueue tries to match each waiting player, oldest first, until no more pairs can be made.
+// It returns the number of matches.
+func sweepWaitingQueue(dynamo dynamodbiface.DynamoDBAPI, apiGW apigatewaymanagementapiiface.ApiGatewayManagementApiAPI, tableName string) (int, error) {
+	matches := 0
+	for {
+		queue, err := listWaitingQueue(dynamo, tableName)
+		if err != nil {
+			return matches, err
+		}
+
+		matched := false
+		for _, waiting := range queue {
+			matched, err = matchPlayer(dynamo, apiGW, tableName, waiting)
+			if err != nil {
+				return matches, err
+			}
+			if matched {
+				break
+			}
+		}
+		if !matched {
+			return matches, nil
+		}
+		// Two players have left the queue; read it again
+		matches++
+	}
+}
+
+// newClients creates the DynamoDB and API Gateway Management API clients for a WebSocket request
+func newClients(request events.APIGatewayWebsocketProxyRequest) (dynamodbiface.DynamoDBAPI, apigatewaymanagementapiiface.ApiGatewayManagementApiAPI, string, error) {
+	return newClientsForEndpoint(fmt.Sprintf("https://%s.execute-api.%s.amazonaws.com/%s",
+		request.RequestContext.APIID,
+		os.Getenv("AWS_REGION"),
+		request.RequestContext.Stage))
+}
+
+// newClientsForEndpoint creates the clients for the WebSocket API at endpoint (https://{api-id}.execute-api.{region}.amazonaws.com/{stage})
+func newClientsForEndpoint(endpoint string) (dynamodbiface.DynamoDBAPI, apigatewaymanagementapiiface.ApiGatewayManagementApiAPI, string, error) {
+	sess, err := session.NewSession(&aws.Config{
+		Region: aws.String(os.Getenv("AWS_REGION")),
+	})
+	if err != nil {
+		return nil, nil, "", err
+	}
+
+	apiGW := apigatewaymanagementapi.New(sess, &aws.Config{
+		Endpoint: aws.String(endpoint),
+	})
+
+	tableName := os.Getenv("DYNAMODB_TABLE_NAME")
+	if tableName == "" {
+		tableName = "websocket-connections"
+	}
+
+	return dynamodb.New(sess), apiGW, tableName, nil
+}
+
 type WaitingPlayer struct {
 	UserId       string
 	ConnectionId string
 	Timestamp    int64
+	QueueKey     string  // SK of the WAITING_QUEUE item
+	Rating       float64 // rating when the player joined the queue
 }
 
-func findWaitingPlayer(dynamo *dynamodb.DynamoDB, tableName string) (*WaitingPlayer, error) {
+// listWaitingQueue returns every player in the waiting queue, oldest first
+func listWaitingQueue(dynamo dynamodbiface.DynamoDBAPI, tableName string) ([]WaitingPlayer, error) {
 	// Query waiting queue (PK = "WAITING_QUEUE")
 	input := &dynamodb.QueryInput{
 		TableName:              aws.String(tableName),
 		KeyConditionExpression: aws.String("PK = :pk"),
 		ExpressionAttributeValues: map[string]*dynamodb.AttributeValue{
-			":pk": {S: aws.String("WAITING_QUEUE")},
+			":pk": {S: aws.String(waitingqueue.PartitionKey)},
 		},
-		Limit:            aws.Int64(1),
 		ScanIndexForward: aws.Bool(true), // Oldest first
 	}
 
-	result, err := dynamo.Query(input)
+	var queue []WaitingPlayer
+	err := dynamo.QueryPages(input, func(page *dynamodb.QueryOutput, lastPage bool) bool {
+		for _, item := range page.Items {
+			player := parseWaitingPlayer(item)
+			if player.UserId == "" {
+				fmt.Printf("Skipping waiting queue item without userId: %s\n", player.QueueKey)
+				continue
+			}
+			queue = append(queue, player)
+		}
+		return true
+	})
 	if err != nil {
 		return nil, err
 	}
 
-	if len(result.Items) == 0 {
-		return nil, nil // No waiting players
-	}
-
-	item := result.Items[0]
+	return queue, nil
+}
 
-	// Parse the waiting player data
-	player := &WaitingPlayer{}
+// parseWaitingPlayer reads a WAITING_QUEUE item
+func parseWaitingPlayer(item map[string]*dynamodb.AttributeValue) WaitingPlayer {
+	player := WaitingPlayer{}
 	if userId, ok := item["userId"]; ok && userId.S != nil {
 		player.UserId = *userId.S
 	}
@@ -179,114 +517,369 @@ func findWaitingPlayer(dynamo *dynamodb.DynamoDB, tableName string) (*WaitingPla
 		player.ConnectionId = *connectionId.S
 	}
 	if timestamp, ok := item["timestamp"]; ok && timestamp.N != nil {
-		// Parse timestamp if needed
+		fmt.Sscanf(*timestamp.N, "%d", &player.Timestamp)
+	}
+	if sortKey, ok := item["SK"]; ok && sortKey.S != nil {
+		player.QueueKey = *sortKey.S
+	}
+	player.Rating = matchmaking.DefaultRating
+	if rating, ok := item["rating"]; ok && rating.N != nil {
+		fmt.Sscanf(*rating.N, "%g", &player.Rating)
+	}
+	return player
+}
+
+// findWaitingPlayer chooses player's opponent from the waiting queue with the rating band policy, without removing them.
+// It returns nil if nobody in the queue is within the band. The queue item is claimed by createGameRoom.
+func findWaitingPlayer(dynamo dynamodbiface.DynamoDBAPI, tableName string, player WaitingPlayer) (*WaitingPlayer, matchmaking.Match, error) {
+	queue, err := listWaitingQueue(dynamo, tableName)
+	if err != nil {
+		return nil, matchmaking.Match{}, err
+	}
+
+	candidates := make([]matchmaking.Candidate, len(queue))
+	for i, waiting := range queue {
+		candidates[i] = waiting.candidate()
+	}
+
+	match, ok := matchmaking.FindOpponent(matchmaking.DefaultPolicy(), player.candidate(), candidates, time.Now())
+	if !ok {
+		return nil, matchmaking.Match{}, nil
+	}
+	for _, waiting := range queue {
+		if waiting.UserId == match.Opponent.UserID {
+			return &waiting, match, nil
+		}
 	}
+	return nil, matchmaking.Match{}, nil
+}
+
+// candidate converts the queue entry for the matchmaking package
+func (w WaitingPlayer) candidate() matchmaking.Candidate {
+	return matchmaking.Candidate{UserID: w.UserId, Rating: w.Rating, JoinedAt: time.Unix(w.Timestamp, 0)}
+}
 
-	// Remove from waiting queue
-	deleteInput := &dynamodb.DeleteItemInput{
+// loadRating returns the player's current rating, or the default rating if none has been recorded
+func loadRating(dynamo dynamodbiface.DynamoDBAPI, tableName, userId string) float64 {
+	result, err := dynamo.GetItem(&dynamodb.GetItemInput{
 		TableName: aws.String(tableName),
 		Key: map[string]*dynamodb.AttributeValue{
-			"PK": {S: aws.String("WAITING_QUEUE")},
-			"SK": item["SK"],
+			"PK": {S: aws.String(fmt.Sprintf("PLAYER#%s", userId))},
+			"SK": {S: aws.String("RATING")},
 		},
-	}
-	_, err = dynamo.DeleteItem(deleteInput)
+	})
 	if err != nil {
-		fmt.Printf("Error removing from waiting queue: %v\n", err)
+		fmt.Printf("Error loading rating for %s: %v\n", userId, err)
+		return matchmaking.DefaultRating
 	}
 
-	return player, nil
+	rating := float64(matchmaking.DefaultRating)
+	if v, ok := result.Item["rating"]; ok && v.N != nil {
+		fmt.Sscanf(*v.N, "%g", &rating)
+	}
+	return rating
 }
 
-func addToWaitingQueue(dynamo *dynamodb.DynamoDB, tableName, userId, connectionId string) error {
-	now := time.Now()
-	timestamp := now.Unix()
+// removeFromWaitingQueue deletes the queue entries for which match returns true, with their markers,
+// and returns how many were deleted
+func removeFromWaitingQueue(dynamo dynamodbiface.DynamoDBAPI, tableName string, match func(WaitingPlayer) bool) (int, error) {
+	queue, err := listWaitingQueue(dynamo, tableName)
+	if err != nil {
+		return 0, err
+	}
 
-	// Use timestamp#userId as sort key for ordering
-	sortKey := fmt.Sprintf("%d#%s", timestamp, userId)
+	var entries []waitingqueue.Entry
+	for _, player := range queue {
+		if match(player) {
+			entries = append(entries, player.entry())
+		}
+	}
+	return waitingqueue.Remove(dynamo, tableName, entries)
+}
 
-	input := &dynamodb.PutItemInput{
+// updateQueueConnection points an existing queue entry at a new connection, keeping its place in the queue
+func updateQueueConnection(dynamo dynamodbiface.DynamoDBAPI, tableName, queueKey, connectionId string) error {
+	_, err := dynamo.UpdateItem(&dynamodb.UpdateItemInput{
 		TableName: aws.String(tableName),
-		Item: map[string]*dynamodb.AttributeValue{
-			"PK":           {S: aws.String("WAITING_QUEUE")},
-			"SK":           {S: aws.String(sortKey)},
-			"userId":       {S: aws.String(userId)},
-			"connectionId": {S: aws.String(connectionId)},
-			"timestamp":    {N: aws.String(fmt.Sprintf("%d", timestamp))},
-			"waitingSince": {S: aws.String(now.Format(time.RFC3339))},
+		Key: map[string]*dynamodb.AttributeValue{
+			"PK": {S: aws.String(waitingqueue.PartitionKey)},
+			"SK": {S: aws.String(queueKey)},
+		},
+		UpdateExpression:    aws.String("SET connectionId = :connectionId"),
+		ConditionExpression: aws.String("attribute_exists(PK)"),
+		ExpressionAttributeValues: map[string]*dynamodb.AttributeValue{
+			":connectionId": {S: aws.String(connectionId)},
 		},
+	})
+	return err
+}
+
+// broadcastQueueStatus sends every waiting player their current position and estimated wait.
+// Failures are only logged because the queue itself has already been updated.
+func broadcastQueueStatus(dynamo dynamodbiface.DynamoDBAPI, apiGW apigatewaymanagementapiiface.ApiGatewayManagementApiAPI, tableName string) {
+	queue, err := listWaitingQueue(dynamo, tableName)
+	if err != nil {
+		fmt.Printf("Error reading waiting queue: %v\n", err)
+		return
+	}
+	stats, err := loadMatchStats(dynamo, tableName)
+	if err != nil {
+		fmt.Printf("Error loading match statistics: %v\n", err)
 	}
 
-	_, err := dynamo.PutItem(input)
-	return err
+	now := time.Now().Unix()
+	for i, waiting := range queue {
+		if err := sendMessage(apiGW, waiting.ConnectionId, newQueueStatus(i+1, len(queue), now-waiting.Timestamp, stats)); err != nil {
+			fmt.Printf("Error sending queueStatus to %s: %v\n", waiting.UserId, err)
+		}
+	}
 }
 
-func createGameRoom(dynamo *dynamodb.DynamoDB, tableName, roomId, player1Id, player2Id, player1ConnId, player2ConnId string) error {
-	now := time.Now().Format(time.RFC3339)
+// newQueueStatus builds the queueStatus message for the player at position (1-based).
+// Each arriving player is matched with the head of the queue, so a player at position p
+// waits for about p times the average wait, minus the time already spent waiting.
+func newQueueStatus(position, queueLength int, waited int64, stats matchStats) QueueStatusResponse {
+	status := QueueStatusResponse{
+		Type:          "queueStatus",
+		Position:      position,
+		QueueLength:   queueLength,
+		WaitedSeconds: waited,
+	}
+	if stats.MatchCount > 0 {
+		average := float64(stats.TotalWaitSeconds) / float64(stats.MatchCount)
+		estimate := int(average*float64(position)) - int(waited)
+		if estimate < 0 {
+			estimate = 0
+		}
+		status.EstimatedWaitSeconds = &estimate
+	}
+	return status
+}
 
-	// Create room metadata
-	roomInput := &dynamodb.PutItemInput{
+// loadMatchStats reads the running totals of waiting time of matched players
+func loadMatchStats(dynamo dynamodbiface.DynamoDBAPI, tableName string) (matchStats, error) {
+	result, err := dynamo.GetItem(&dynamodb.GetItemInput{
 		TableName: aws.String(tableName),
-		Item: map[string]*dynamodb.AttributeValue{
-			"PK":          {S: aws.String(fmt.Sprintf("ROOM#%s", roomId))},
-			"SK":          {S: aws.String("METADATA")},
-			"roomId":      {S: aws.String(roomId)},
-			"status":      {S: aws.String("WAITING")},
-			"playerCount": {N: aws.String("2")},
-			"player1Id":   {S: aws.String(player1Id)},
-			"player2Id":   {S: aws.String(player2Id)},
-			"createdAt":   {S: aws.String(now)},
-			"updatedAt":   {S: aws.String(now)},
+		Key: map[string]*dynamodb.AttributeValue{
+			"PK": {S: aws.String("MATCHMAKING")},
+			"SK": {S: aws.String("STATS")},
 		},
+	})
+	if err != nil {
+		return matchStats{}, err
 	}
 
-	_, err := dynamo.PutItem(roomInput)
-	if err != nil {
-		return err
+	var stats matchStats
+	if v, ok := result.Item["totalWaitSeconds"]; ok && v.N != nil {
+		fmt.Sscanf(*v.N, "%d", &stats.TotalWaitSeconds)
+	}
+	if v, ok := result.Item["matchCount"]; ok && v.N != nil {
+		fmt.Sscanf(*v.N, "%d", &stats.MatchCount)
 	}
+	return stats, nil
+}
 
-	// Add player 1
-	player1Input := &dynamodb.PutItemInput{
+// recordMatchWait adds the matched player's waiting time to the running totals
+func recordMatchWait(dynamo dynamodbiface.DynamoDBAPI, tableName string, waitedSeconds int64) error {
+	if waitedSeconds < 0 {
+		waitedSeconds = 0
+	}
+	_, err := dynamo.UpdateItem(&dynamodb.UpdateItemInput{
 		TableName: aws.String(tableName),
-		Item: map[string]*dynamodb.AttributeValue{
-			"PK":           {S: aws.String(fmt.Sprintf("ROOM#%s", roomId))},
-			"SK":           {S: aws.String(fmt.Sprintf("PLAYER#%s", player1Id))},
-			"userId":       {S: aws.String(player1Id)},
-			"roomId":       {S: aws.String(roomId)},
-			"playerRole":   {S: aws.String("PLAYER1")},
-			"connectionId": {S: aws.String(player1ConnId)},
-			"joinedAt":     {S: aws.String(now)},
+		Key: map[string]*dynamodb.AttributeValue{
+			"PK": {S: aws.String("MATCHMAKING")},
+			"SK": {S: aws.String("STATS")},
+		},
+		UpdateExpression: aws.String("ADD totalWaitSeconds :wait, matchCount :one"),
+		ExpressionAttributeValues: map[string]*dynamodb.AttributeValue{
+			":wait": {N: aws.String(fmt.Sprintf("%d", waitedSeconds))},
+			":one":  {N: aws.String("1")},
+		},
+	})
+	return err
+}
+
+// addToWaitingQueue adds the player to the waiting queue together with their WAITING#userId marker.
+// The marker can only be created once, so a player never has two queue entries; errAlreadyWaiting is
+// returned if they are already waiting.
+func addToWaitingQueue(dynamo dynamodbiface.DynamoDBAPI, tableName string, player WaitingPlayer) error {
+	now := time.Unix(player.Timestamp, 0)
+	timestamp := player.Timestamp
+	userId := player.UserId
+	connectionId := player.ConnectionId
+
+	// Use timestamp#userId as sort key for ordering
+	sortKey := fmt.Sprintf("%d#%s", timestamp, userId)
+
+	marker := waitingqueue.MarkerKey(userId)
+	marker["userId"] = &dynamodb.AttributeValue{S: aws.String(userId)}
+	marker["queueKey"] = &dynamodb.AttributeValue{S: aws.String(sortKey)}
+
+	input := &dynamodb.TransactWriteItemsInput{
+		TransactItems: []*dynamodb.TransactWriteItem{
+			{Put: &dynamodb.Put{
+				TableName:           aws.String(tableName),
+				Item:                marker,
+				ConditionExpression: aws.String("attribute_not_exists(PK)"),
+			}},
+			{Put: &dynamodb.Put{
+				TableName: aws.String(tableName),
+				Item: map[string]*dynamodb.AttributeValue{
+					"PK":           {S: aws.String(waitingqueue.PartitionKey)},
+					"SK":           {S: aws.String(sortKey)},
+					"userId":       {S: aws.String(userId)},
+					"connectionId": {S: aws.String(connectionId)},
+					"timestamp":    {N: aws.String(fmt.Sprintf("%d", timestamp))},
+					"waitingSince": {S: aws.String(now.Format(time.RFC3339))},
+					"rating":       {N: aws.String(strconv.FormatFloat(player.Rating, 'f', -1, 64))},
+				},
+			}},
 		},
 	}
 
-	_, err = dynamo.PutItem(player1Input)
-	if err != nil {
-		return err
+	_, err := dynamo.TransactWriteItems(input)
+	if waitingqueue.ConditionFailedAt(err, 0) {
+		return errAlreadyWaiting
+	}
+	return err
+}
+
+// findQueueEntry returns userId's entry in the waiting queue, or nil if they are not waiting
+func findQueueEntry(dynamo dynamodbiface.DynamoDBAPI, tableName, userId string) (*WaitingPlayer, error) {
+	marker, err := dynamo.GetItem(&dynamodb.GetItemInput{
+		TableName:      aws.String(tableName),
+		Key:            waitingqueue.MarkerKey(userId),
+		ConsistentRead: aws.Bool(true),
+	})
+	if err != nil || marker.Item == nil {
+		return nil, err
 	}
+	queueKey := aws.StringValue(marker.Item["queueKey"].S)
 
-	// Add player 2
-	player2Input := &dynamodb.PutItemInput{
+	entry, err := dynamo.GetItem(&dynamodb.GetItemInput{
 		TableName: aws.String(tableName),
-		Item: map[string]*dynamodb.AttributeValue{
-			"PK":           {S: aws.String(fmt.Sprintf("ROOM#%s", roomId))},
-			"SK":           {S: aws.String(fmt.Sprintf("PLAYER#%s", player2Id))},
-			"userId":       {S: aws.String(player2Id)},
-			"roomId":       {S: aws.String(roomId)},
-			"playerRole":   {S: aws.String("PLAYER2")},
-			"connectionId": {S: aws.String(player2ConnId)},
-			"joinedAt":     {S: aws.String(now)},
+		Key: map[string]*dynamodb.AttributeValue{
+			"PK": {S: aws.String(waitingqueue.PartitionKey)},
+			"SK": {S: aws.String(queueKey)},
 		},
+		ConsistentRead: aws.Bool(true),
+	})
+	if err != nil {
+		return nil, err
+	}
+	if entry.Item == nil {
+		// The entry was removed without its marker (for example by a partially failed cleanup); let the player join again
+		fmt.Printf("Removing stale waiting marker of %s\n", userId)
+		_, err := dynamo.DeleteItem(&dynamodb.DeleteItemInput{
+			TableName:           aws.String(tableName),
+			Key:                 waitingqueue.MarkerKey(userId),
+			ConditionExpression: aws.String("queueKey = :queueKey"),
+			ExpressionAttributeValues: map[string]*dynamodb.AttributeValue{
+				":queueKey": {S: aws.String(queueKey)},
+			},
+		})
+		if isConditionalCheckFailed(err) {
+			err = nil
+		}
+		return nil, err
+	}
+
+	player := parseWaitingPlayer(entry.Item)
+	return &player, nil
+}
+
+// entry identifies the player's queue item for the waitingqueue package
+func (w WaitingPlayer) entry() waitingqueue.Entry {
+	return waitingqueue.Entry{UserID: w.UserId, QueueKey: w.QueueKey}
+}
+
+// createGameRoom removes the waiting player (and player 2, if they were waiting too) from the queue and writes
+// the room and both player items in a single transaction. If a queue item is already gone, or player 2 has
+// just joined but is also waiting, nothing is written and errClaimLost is returned.
+func createGameRoom(dynamo dynamodbiface.DynamoDBAPI, tableName, roomId string, waiting, player2 WaitingPlayer) error {
+	now := time.Now().Format(time.RFC3339)
+	roomKey := fmt.Sprintf("ROOM#%s", roomId)
+	player2Id, player2ConnId := player2.UserId, player2.ConnectionId
+
+	// Claim the waiting player
+	input := &dynamodb.TransactWriteItemsInput{
+		TransactItems: append(waitingqueue.DequeueItems(tableName, waiting.entry()), []*dynamodb.TransactWriteItem{
+			// Room metadata
+			{Put: &dynamodb.Put{
+				TableName: aws.String(tableName),
+				Item: map[string]*dynamodb.AttributeValue{
+					"PK":          {S: aws.String(roomKey)},
+					"SK":          {S: aws.String("METADATA")},
+					"roomId":      {S: aws.String(roomId)},
+					"status":      {S: aws.String("WAITING")},
+					"playerCount": {N: aws.String("2")},
+					"player1Id":   {S: aws.String(waiting.UserId)},
+					"player2Id":   {S: aws.String(player2Id)},
+					"createdAt":   {S: aws.String(now)},
+					"updatedAt":   {S: aws.String(now)},
+				},
+				ConditionExpression: aws.String("attribute_not_exists(PK)"),
+			}},
+			// Player 1 (waiting player)
+			{Put: &dynamodb.Put{
+				TableName: aws.String(tableName),
+				Item: map[string]*dynamodb.AttributeValue{
+					"PK":           {S: aws.String(roomKey)},
+					"SK":           {S: aws.String(fmt.Sprintf("PLAYER#%s", waiting.UserId))},
+					"userId":       {S: aws.String(waiting.UserId)},
+					"roomId":       {S: aws.String(roomId)},
+					"playerRole":   {S: aws.String("PLAYER1")},
+					"connectionId": {S: aws.String(waiting.ConnectionId)},
+					"joinedAt":     {S: aws.String(now)},
+				},
+			}},
+			// Player 2 (current player)
+			{Put: &dynamodb.Put{
+				TableName: aws.String(tableName),
+				Item: map[string]*dynamodb.AttributeValue{
+					"PK":           {S: aws.String(roomKey)},
+					"SK":           {S: aws.String(fmt.Sprintf("PLAYER#%s", player2Id))},
+					"userId":       {S: aws.String(player2Id)},
+					"roomId":       {S: aws.String(roomId)},
+					"playerRole":   {S: aws.String("PLAYER2")},
+					"connectionId": {S: aws.String(player2ConnId)},
+					"joinedAt":     {S: aws.String(now)},
+				},
+			}},
+		}...),
+	}
+
+	if player2.QueueKey != "" {
+		input.TransactItems = append(input.TransactItems, waitingqueue.DequeueItems(tableName, player2.entry())...)
+	} else {
+		// A player who has just joined must not be waiting at the same time (a concurrent join of theirs may have queued)
+		input.TransactItems = append(input.TransactItems, &dynamodb.TransactWriteItem{ConditionCheck: &dynamodb.ConditionCheck{
+			TableName:           aws.String(tableName),
+			Key:                 waitingqueue.MarkerKey(player2.UserId),
+			ConditionExpression: aws.String("attribute_not_exists(PK)"),
+		}})
 	}
 
-	_, err = dynamo.PutItem(player2Input)
+	_, err := dynamo.TransactWriteItems(input)
+	var aerr awserr.Error
+	if errors.As(err, &aerr) && aerr.Code() == dynamodb.ErrCodeTransactionCanceledException {
+		// The claim failed or conflicted with a concurrent transaction; either way nothing was written
+		return fmt.Errorf("%w: %v", errClaimLost, err)
+	}
 	return err
 }
 
+// isConditionalCheckFailed reports whether err is DynamoDB rejecting a conditional write
+func isConditionalCheckFailed(err error) bool {
+	var aerr awserr.Error
+	return errors.As(err, &aerr) && aerr.Code() == dynamodb.ErrCodeConditionalCheckFailedException
+}
+
 func generateRoomId() string {
 	return fmt.Sprintf("room_%d", time.Now().UnixNano())
 }
 
-func sendMessage(apiGW *apigatewaymanagementapi.ApiGatewayManagementApi, connectionId string, message interface{}) error {
+func sendMessage(apiGW apigatewaymanagementapiiface.ApiGatewayManagementApiAPI, connectionId string, message interface{}) error {
 	data, err := json.Marshal(message)
 	if err != nil {
 		return err
@@ -302,5 +895,5 @@ func sendMessage(apiGW *apigatewaymanagementapi.ApiGatewayManagementApi, connect
 }
 
 func main() {
-	lambda.Start(handleMatchmaking)
+	lambda.Start(handleEvent)
 }
@@ -0,0 +1,334 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/service/apigatewaymanagementapi"
+	"github.com/aws/aws-sdk-go/service/apigatewaymanagementapi/apigatewaymanagementapiiface"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbiface"
+)
+
+// fakeDynamo is an in-memory stand-in for the DynamoDB calls this handler makes.
+// Only attribute_exists(PK) and attribute_not_exists(PK) are evaluated; any other condition is treated as met.
+// Methods it does not implement panic through the embedded nil interface.
+type fakeDynamo struct {
+	dynamodbiface.DynamoDBAPI
+
+	items  map[string]map[string]*dynamodb.AttributeValue // keyed by PK and SK
+	writes int                                            // number of write calls, successful or not
+}
+
+func newFakeDynamo() *fakeDynamo {
+	return &fakeDynamo{items: map[string]map[string]*dynamodb.AttributeValue{}}
+}
+
+func itemKey(key map[string]*dynamodb.AttributeValue) string {
+	return aws.StringValue(key["PK"].S) + "|" + aws.StringValue(key["SK"].S)
+}
+
+// conditionHolds evaluates condition against whether the item currently exists
+func (f *fakeDynamo) conditionHolds(condition *string, key map[string]*dynamodb.AttributeValue) bool {
+	_, exists := f.items[itemKey(key)]
+	switch aws.StringValue(condition) {
+	case "attribute_exists(PK)":
+		return exists
+	case "attribute_not_exists(PK)":
+		return !exists
+	}
+	return true
+}
+
+func conditionalCheckFailed() error {
+	return awserr.New(dynamodb.ErrCodeConditionalCheckFailedException, "The conditional request failed", nil)
+}
+
+func (f *fakeDynamo) GetItem(input *dynamodb.GetItemInput) (*dynamodb.GetItemOutput, error) {
+	return &dynamodb.GetItemOutput{Item: f.items[itemKey(input.Key)]}, nil
+}
+
+func (f *fakeDynamo) PutItem(input *dynamodb.PutItemInput) (*dynamodb.PutItemOutput, error) {
+	f.writes++
+	if !f.conditionHolds(input.ConditionExpression, input.Item) {
+		return nil, conditionalCheckFailed()
+	}
+	f.items[itemKey(input.Item)] = input.Item
+	return &dynamodb.PutItemOutput{}, nil
+}
+
+func (f *fakeDynamo) DeleteItem(input *dynamodb.DeleteItemInput) (*dynamodb.DeleteItemOutput, error) {
+	f.writes++
+	if !f.conditionHolds(input.ConditionExpression, input.Key) {
+		return nil, conditionalCheckFailed()
+	}
+	delete(f.items, itemKey(input.Key))
+	return &dynamodb.DeleteItemOutput{}, nil
+}
+
+// QueryPages supports the "PK = :pk" query used for the waiting queue, in ascending SK order
+func (f *fakeDynamo) QueryPages(input *dynamodb.QueryInput, fn func(*dynamodb.QueryOutput, bool) bool) error {
+	prefix := aws.StringValue(input.ExpressionAttributeValues[":pk"].S) + "|"
+	var keys []string
+	for key := range f.items {
+		if strings.HasPrefix(key, prefix) {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+
+	page := &dynamodb.QueryOutput{}
+	for _, key := range keys {
+		page.Items = append(page.Items, f.items[key])
+	}
+	fn(page, true)
+	return nil
+}
+
+// UpdateItem applies the SET of connectionId used for queue entries; other updates (the match statistics) are only counted
+func (f *fakeDynamo) UpdateItem(input *dynamodb.UpdateItemInput) (*dynamodb.UpdateItemOutput, error) {
+	f.writes++
+	if !f.conditionHolds(input.ConditionExpression, input.Key) {
+		return nil, conditionalCheckFailed()
+	}
+	if connectionId, ok := input.ExpressionAttributeValues[":connectionId"]; ok {
+		f.items[itemKey(input.Key)]["connectionId"] = connectionId
+	}
+	return &dynamodb.UpdateItemOutput{}, nil
+}
+
+// TransactWriteItems commits every Put and Delete, or nothing if any condition (including a ConditionCheck) fails
+func (f *fakeDynamo) TransactWriteItems(input *dynamodb.TransactWriteItemsInput) (*dynamodb.TransactWriteItemsOutput, error) {
+	f.writes++
+	reasons := make([]*dynamodb.CancellationReason, len(input.TransactItems))
+	canceled := false
+	for i, item := range input.TransactItems {
+		reasons[i] = &dynamodb.CancellationReason{Code: aws.String("None")}
+		var ok bool
+		switch {
+		case item.Put != nil:
+			ok = f.conditionHolds(item.Put.ConditionExpression, item.Put.Item)
+		case item.Delete != nil:
+			ok = f.conditionHolds(item.Delete.ConditionExpression, item.Delete.Key)
+		case item.ConditionCheck != nil:
+			ok = f.conditionHolds(item.ConditionCheck.ConditionExpression, item.ConditionCheck.Key)
+		default:
+			ok = true
+		}
+		if !ok {
+			reasons[i].Code = aws.String("ConditionalCheckFailed")
+			canceled = true
+		}
+	}
+	if canceled {
+		return nil, &dynamodb.TransactionCanceledException{CancellationReasons: reasons}
+	}
+
+	for _, item := range input.TransactItems {
+		switch {
+		case item.Put != nil:
+			f.items[itemKey(item.Put.Item)] = item.Put.Item
+		case item.Delete != nil:
+			delete(f.items, itemKey(item.Delete.Key))
+		}
+	}
+	return &dynamodb.TransactWriteItemsOutput{}, nil
+}
+
+// fakeAPIGateway records the messages posted to each connection
+type fakeAPIGateway struct {
+	apigatewaymanagementapiiface.ApiGatewayManagementApiAPI
+
+	sent map[string][]map[string]interface{}
+}
+
+func newFakeAPIGateway() *fakeAPIGateway {
+	return &fakeAPIGateway{sent: map[string][]map[string]interface{}{}}
+}
+
+func (g *fakeAPIGateway) PostToConnection(input *apigatewaymanagementapi.PostToConnectionInput) (*apigatewaymanagementapi.PostToConnectionOutput, error) {
+	var message map[string]interface{}
+	if err := json.Unmarshal(input.Data, &message); err != nil {
+		return nil, err
+	}
+	connectionId := aws.StringValue(input.ConnectionId)
+	g.sent[connectionId] = append(g.sent[connectionId], message)
+	return &apigatewaymanagementapi.PostToConnectionOutput{}, nil
+}
+
+// lastType returns the type of the last message sent to connectionId
+func (g *fakeAPIGateway) lastType(connectionId string) string {
+	messages := g.sent[connectionId]
+	if len(messages) == 0 {
+		return ""
+	}
+	messageType, _ := messages[len(messages)-1]["type"].(string)
+	return messageType
+}
+
+// queuePlayer adds userId to the waiting queue and returns the stored entry
+func queuePlayer(t *testing.T, f *fakeDynamo, userId string) WaitingPlayer {
+	t.Helper()
+	return queuePlayerAt(t, f, userId, 1500, time.Now())
+}
+
+// queuePlayerAt adds userId with the given rating as if they had joined at joinedAt
+func queuePlayerAt(t *testing.T, f *fakeDynamo, userId string, rating float64, joinedAt time.Time) WaitingPlayer {
+	t.Helper()
+	player := WaitingPlayer{UserId: userId, ConnectionId: "conn-" + userId, Timestamp: joinedAt.Unix(), Rating: rating}
+	if err := addToWaitingQueue(f, "game-service", player); err != nil {
+		t.Fatalf("addToWaitingQueue(%s): %v", userId, err)
+	}
+	waiting, err := findQueueEntry(f, "game-service", userId)
+	if err != nil || waiting == nil {
+		t.Fatalf("findQueueEntry(%s) = (%v, %v), want the new entry", userId, waiting, err)
+	}
+	return *waiting
+}
+
+func TestAddToWaitingQueue_Duplicate(t *testing.T) {
+	f := newFakeDynamo()
+	queuePlayer(t, f, "alice")
+
+	// A second join, even one racing the first with a different timestamp, cannot add another entry
+	again := WaitingPlayer{UserId: "alice", ConnectionId: "conn-2", Timestamp: time.Now().Unix() + 1, Rating: 1500}
+	if err := addToWaitingQueue(f, "game-service", again); !errors.Is(err, errAlreadyWaiting) {
+		t.Fatalf("second addToWaitingQueue err = %v, want errAlreadyWaiting", err)
+	}
+	if queue, _ := listWaitingQueue(f, "game-service"); len(queue) != 1 {
+		t.Fatalf("queue = %v, want a single entry", queue)
+	}
+
+	// Leaving removes the marker with the entry, so the player can queue again
+	if removed, err := removeFromWaitingQueue(f, "game-service", func(w WaitingPlayer) bool { return w.UserId == "alice" }); err != nil || removed != 1 {
+		t.Fatalf("removeFromWaitingQueue() = (%d, %v), want (1, nil)", removed, err)
+	}
+	if err := addToWaitingQueue(f, "game-service", again); err != nil {
+		t.Fatalf("addToWaitingQueue after leaving: %v", err)
+	}
+}
+
+func TestFindQueueEntry_StaleMarker(t *testing.T) {
+	f := newFakeDynamo()
+	waiting := queuePlayer(t, f, "alice")
+
+	// The entry disappears without its marker
+	delete(f.items, "WAITING_QUEUE|"+waiting.QueueKey)
+
+	if entry, err := findQueueEntry(f, "game-service", "alice"); err != nil || entry != nil {
+		t.Fatalf("findQueueEntry() = (%v, %v), want (nil, nil)", entry, err)
+	}
+	if _, ok := f.items["WAITING#alice|QUEUE"]; ok {
+		t.Fatal("stale marker was not removed")
+	}
+}
+
+func TestCreateGameRoom(t *testing.T) {
+	f := newFakeDynamo()
+	waiting := queuePlayer(t, f, "alice")
+
+	if err := createGameRoom(f, "game-service", "room-1", waiting, WaitingPlayer{UserId: "bob", ConnectionId: "conn-b"}); err != nil {
+		t.Fatalf("createGameRoom: %v", err)
+	}
+	if queue, _ := listWaitingQueue(f, "game-service"); len(queue) != 0 {
+		t.Fatalf("queue after match = %v, want empty", queue)
+	}
+	if _, ok := f.items["WAITING#alice|QUEUE"]; ok {
+		t.Fatal("matched player is still marked as waiting")
+	}
+	room := f.items["ROOM#room-1|METADATA"]
+	if room == nil || aws.StringValue(room["player1Id"].S) != "alice" || aws.StringValue(room["player2Id"].S) != "bob" {
+		t.Fatalf("room = %v, want alice against bob", room)
+	}
+
+	// A concurrent join that read the same queue tries to claim alice as well
+	err := createGameRoom(f, "game-service", "room-2", waiting, WaitingPlayer{UserId: "carol", ConnectionId: "conn-c"})
+	if !errors.Is(err, errClaimLost) {
+		t.Fatalf("second createGameRoom err = %v, want errClaimLost", err)
+	}
+	if _, ok := f.items["ROOM#room-2|METADATA"]; ok {
+		t.Fatal("losing claim created a room")
+	}
+}
+
+func TestCreateGameRoom_NewPlayerAlreadyWaiting(t *testing.T) {
+	f := newFakeDynamo()
+	waiting := queuePlayer(t, f, "alice")
+	queuePlayer(t, f, "bob")
+
+	// bob's concurrent join queued him, so this join must not also match him
+	err := createGameRoom(f, "game-service", "room-1", waiting, WaitingPlayer{UserId: "bob", ConnectionId: "conn-b2"})
+	if !errors.Is(err, errClaimLost) {
+		t.Fatalf("createGameRoom err = %v, want errClaimLost", err)
+	}
+	if queue, _ := listWaitingQueue(f, "game-service"); len(queue) != 2 {
+		t.Fatalf("queue = %v, want both players still waiting", queue)
+	}
+}
+
+func TestSweepWaitingQueue(t *testing.T) {
+	f := newFakeDynamo()
+	apiGW := newFakeAPIGateway()
+	now := time.Now()
+
+	// 300 apart: too far for the initial ±100 band, but alice has waited long enough for it to widen to ±400
+	queuePlayerAt(t, f, "alice", 1500, now.Add(-time.Minute))
+	queuePlayerAt(t, f, "bob", 1800, now)
+	// Nobody is close to carol even with the widened band
+	queuePlayerAt(t, f, "carol", 2600, now)
+
+	matches, err := sweepWaitingQueue(f, apiGW, "game-service")
+	if err != nil || matches != 1 {
+		t.Fatalf("sweepWaitingQueue() = (%d, %v), want (1, nil)", matches, err)
+	}
+	for _, connectionId := range []string{"conn-alice", "conn-bob"} {
+		found := false
+		for _, message := range apiGW.sent[connectionId] {
+			found = found || message["type"] == "matchFound"
+		}
+		if !found {
+			t.Fatalf("%s was not sent matchFound: %v", connectionId, apiGW.sent[connectionId])
+		}
+	}
+	if queue, _ := listWaitingQueue(f, "game-service"); len(queue) != 1 || queue[0].UserId != "carol" {
+		t.Fatalf("queue after sweep = %v, want only carol", queue)
+	}
+	if got := apiGW.lastType("conn-carol"); got != "queueStatus" {
+		t.Fatalf("last message to carol = %q, want queueStatus", got)
+	}
+}
+
+func TestSendQueueStatus_ReadOnly(t *testing.T) {
+	f := newFakeDynamo()
+	apiGW := newFakeAPIGateway()
+	now := time.Now()
+
+	// A pair the sweep would match; a status request must not
+	queuePlayerAt(t, f, "alice", 1500, now.Add(-time.Minute))
+	queuePlayerAt(t, f, "bob", 1800, now)
+	writes := f.writes
+
+	if err := sendQueueStatus(f, apiGW, "game-service", "conn-bob"); err != nil {
+		t.Fatalf("sendQueueStatus: %v", err)
+	}
+	if f.writes != writes {
+		t.Fatalf("sendQueueStatus made %d writes, want none", f.writes-writes)
+	}
+	status := apiGW.sent["conn-bob"]
+	if len(status) != 1 || status[0]["type"] != "queueStatus" || status[0]["position"] != float64(2) {
+		t.Fatalf("sent %v, want queueStatus at position 2", status)
+	}
+
+	if err := sendQueueStatus(f, apiGW, "game-service", "conn-unknown"); err != nil {
+		t.Fatalf("sendQueueStatus: %v", err)
+	}
+	if got := apiGW.lastType("conn-unknown"); got != "queueLeft" {
+		t.Fatalf("message to a connection that is not waiting = %q, want queueLeft", got)
+	}
+}
@@ -120,7 +120,8 @@ resource "aws_iam_role_policy" "lambda_websocket_policy" {
           "dynamodb:DeleteItem",
           "dynamodb:Query",
           "dynamodb:Scan",
-          "dynamodb:UpdateItem"
+          "dynamodb:UpdateItem",
+          "dynamodb:ConditionCheckItem"
         ]
         Resource = [
           aws_dynamodb_table.game_service.arn,
@@ -139,6 +140,13 @@ resource "aws_iam_role_policy" "lambda_websocket_policy" {
           "${aws_dynamodb_table.game_archive.arn}/*"
         ]
       },
+      {
+        Effect = "Allow"
+        Action = [
+          "secretsmanager:GetSecretValue"
+        ]
+        Resource = aws_secretsmanager_secret.decode_api_keys.arn
+      },
       {
         Effect = "Allow"
         Action = [
@@ -209,7 +217,9 @@ resource "aws_lambda_function" "hackz_ichthyo_matchmaking_handler" {
 
   environment {
     variables = {
-      DYNAMODB_TABLE_NAME = aws_dynamodb_table.game_service.name
+      # 定期実行（EventBridge）のときは接続元の API がわからないため、WEBSOCKET_API_ENDPOINT で送信先を渡す
+      DYNAMODB_TABLE_NAME    = aws_dynamodb_table.game_service.name
+      WEBSOCKET_API_ENDPOINT = "${replace(aws_apigatewayv2_api.hackz_ichthyo_websocket.api_endpoint, "wss://", "https://")}/${aws_apigatewayv2_stage.hackz_ichthyo_stage.name}"
     }
   }
 
@@ -280,7 +290,8 @@ resource "aws_lambda_function" "hackz_ichthyo_disconnect_handler" {
 
   environment {
     variables = {
-      DYNAMODB_TABLE_NAME = aws_dynamodb_table.websocket_connections.name
+      DYNAMODB_TABLE_NAME     = aws_dynamodb_table.websocket_connections.name
+      GAME_SERVICE_TABLE_NAME = aws_dynamodb_table.game_service.name
     }
   }
 
@@ -324,6 +335,20 @@ resource "aws_apigatewayv2_route" "hackz_ichthyo_joingame" {
   target    = "integrations/${aws_apigatewayv2_integration.hackz_ichthyo_matchmaking_integration.id}"
 }
 
+# leaveQueue route
+resource "aws_apigatewayv2_route" "hackz_ichthyo_leavequeue" {
+  api_id    = aws_apigatewayv2_api.hackz_ichthyo_websocket.id
+  route_key = "leaveQueue"
+  target    = "integrations/${aws_apigatewayv2_integration.hackz_ichthyo_matchmaking_integration.id}"
+}
+
+# queueStatus route
+resource "aws_apigatewayv2_route" "hackz_ichthyo_queuestatus" {
+  api_id    = aws_apigatewayv2_api.hackz_ichthyo_websocket.id
+  route_key = "queueStatus"
+  target    = "integrations/${aws_apigatewayv2_integration.hackz_ichthyo_matchmaking_integration.id}"
+}
+
 # permission for exec lambda (connect)
 resource "aws_lambda_permission" "connect_handler" {
   statement_id  = "AllowExecutionFromAPIGateway"
@@ -351,6 +376,24 @@ resource "aws_lambda_permission" "matchmaking_handler" {
   source_arn    = "arn:aws:execute-api:${data.aws_region.current.name}:${data.aws_caller_identity.current.account_id}:${aws_apigatewayv2_api.hackz_ichthyo_websocket.id}/*/joinGame"
 }
 
+# permission for exec lambda (matchmaking - leaveQueue)
+resource "aws_lambda_permission" "matchmaking_handler_leavequeue" {
+  statement_id  = "AllowMatchmakingLeaveQueueExecutionFromAPIGateway"
+  action        = "lambda:InvokeFunction"
+  function_name = aws_lambda_function.hackz_ichthyo_matchmaking_handler.function_name
+  principal     = "apigateway.amazonaws.com"
+  source_arn    = "arn:aws:execute-api:${data.aws_region.current.name}:${data.aws_caller_identity.current.account_id}:${aws_apigatewayv2_api.hackz_ichthyo_websocket.id}/*/leaveQueue"
+}
+
+# permission for exec lambda (matchmaking - queueStatus)
+resource "aws_lambda_permission" "matchmaking_handler_queuestatus" {
+  statement_id  = "AllowMatchmakingQueueStatusExecutionFromAPIGateway"
+  action        = "lambda:InvokeFunction"
+  function_name = aws_lambda_function.hackz_ichthyo_matchmaking_handler.function_name
+  principal     = "apigateway.amazonaws.com"
+  source_arn    = "arn:aws:execute-api:${data.aws_region.current.name}:${data.aws_caller_identity.current.account_id}:${aws_apigatewayv2_api.hackz_ichthyo_websocket.id}/*/queueStatus"
+}
+
 # Data source to create ZIP file for game Lambda
 data "archive_file" "lambda_game_zip" {
   type        = "zip"
@@ -573,6 +616,35 @@ resource "aws_lambda_permission" "allow_eventbridge_cleanup" {
   source_arn    = aws_cloudwatch_event_rule.cleanup_schedule.arn
 }
 
+# EventBridge Rule for the matchmaking queue sweep (every minute)
+# 待ち時間に応じて広がるレーティング幅で待機中のプレイヤー同士のマッチングを再試行し、queueStatus を送る
+resource "aws_cloudwatch_event_rule" "matchmaking_sweep_schedule" {
+  name                = "hackz-ichthyo-matchmaking-sweep"
+  description         = "Retry matchmaking for waiting players every minute"
+  schedule_expression = "rate(1 minute)"
+
+  tags = {
+    Environment = "hackathon"
+    Project     = "ichthyo-reversi"
+  }
+}
+
+# EventBridge Target to invoke Matchmaking Lambda
+resource "aws_cloudwatch_event_target" "matchmaking_sweep_target" {
+  rule      = aws_cloudwatch_event_rule.matchmaking_sweep_schedule.name
+  target_id = "MatchmakingSweepTarget"
+  arn       = aws_lambda_function.hackz_ichthyo_matchmaking_handler.arn
+}
+
+# Permission for EventBridge to invoke Matchmaking Lambda
+resource "aws_lambda_permission" "allow_eventbridge_matchmaking_sweep" {
+  statement_id  = "AllowExecutionFromEventBridgeSweep"
+  action        = "lambda:InvokeFunction"
+  function_name = aws_lambda_function.hackz_ichthyo_matchmaking_handler.function_name
+  principal     = "events.amazonaws.com"
+  source_arn    = aws_cloudwatch_event_rule.matchmaking_sweep_schedule.arn
+}
+
 # 証明書検証の完了確認
 resource "aws_acm_certificate_validation" "websocket_cert_validation" {
   certificate_arn = aws_acm_certificate.websocket_cert.arn
@@ -671,8 +743,10 @@ resource "aws_lambda_function" "game_replay_handler" {
 
   environment {
     variables = {
-      GAME_SERVICE_TABLE  = aws_dynamodb_table.game_service.name
-      GAME_ARCHIVE_TABLE  = aws_dynamodb_table.game_archive.name
+      GAME_SERVICE_TABLE        = aws_dynamodb_table.game_service.name
+      GAME_ARCHIVE_TABLE        = aws_dynamodb_table.game_archive.name
+      # Decode API のキーは値ではなくシークレットの ARN を渡し、Lambda が実行時に読む
+      DECODE_API_KEY_SECRET_ARN = aws_secretsmanager_secret.decode_api_keys.arn
     }
   }
 